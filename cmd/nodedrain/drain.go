@@ -0,0 +1,213 @@
+package nodedrain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Conflux-Chain/confura/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type drainCmdConfig struct {
+	Network  string // network space ("cfx" or "eth")
+	Endpoint string // node manager RPC endpoint
+	Url      string // node url
+	Group    string // node group
+}
+
+var (
+	drainCfg drainCmdConfig
+
+	drainCmd = &cobra.Command{
+		Use:   "drain",
+		Short: "Stop routing new requests to the node for maintenance",
+		Run:   drainNode,
+	}
+
+	undrainCmd = &cobra.Command{
+		Use:   "undrain",
+		Short: "Resume routing new requests to the node",
+		Run:   undrainNode,
+	}
+
+	statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show drain progress of the node",
+		Run:   drainStatus,
+	}
+
+	listDrainingCmd = &cobra.Command{
+		Use:   "ls",
+		Short: "List all draining nodes",
+		Run:   listDrainingNodes,
+	}
+)
+
+func init() {
+	Cmd.AddCommand(drainCmd)
+	hookDrainCmdFlags(drainCmd, true, false)
+
+	Cmd.AddCommand(undrainCmd)
+	hookDrainCmdFlags(undrainCmd, true, false)
+
+	Cmd.AddCommand(statusCmd)
+	hookDrainCmdFlags(statusCmd, true, true)
+
+	Cmd.AddCommand(listDrainingCmd)
+	hookDrainCmdFlags(listDrainingCmd, false, false)
+}
+
+func drainNode(cmd *cobra.Command, args []string) {
+	client, err := dialNodeManager()
+	if err != nil {
+		logrus.WithError(err).Info("Failed to dial node manager RPC")
+		return
+	}
+	defer client.Close()
+
+	if err := client.Call(nil, "node_drain", drainCfg.Url); err != nil {
+		logrus.WithField("url", drainCfg.Url).WithError(err).Info("Failed to drain node")
+		return
+	}
+
+	logrus.WithField("url", drainCfg.Url).Info("Node is draining now")
+}
+
+func undrainNode(cmd *cobra.Command, args []string) {
+	client, err := dialNodeManager()
+	if err != nil {
+		logrus.WithError(err).Info("Failed to dial node manager RPC")
+		return
+	}
+	defer client.Close()
+
+	if err := client.Call(nil, "node_undrain", drainCfg.Url); err != nil {
+		logrus.WithField("url", drainCfg.Url).WithError(err).Info("Failed to undrain node")
+		return
+	}
+
+	logrus.WithField("url", drainCfg.Url).Info("Node undrained")
+}
+
+func drainStatus(cmd *cobra.Command, args []string) {
+	client, err := dialNodeManager()
+	if err != nil {
+		logrus.WithError(err).Info("Failed to dial node manager RPC")
+		return
+	}
+	defer client.Close()
+
+	var statuses []json.RawMessage
+	if err := client.Call(&statuses, "node_status", drainCfg.Group, drainCfg.Url); err != nil {
+		logrus.WithField("url", drainCfg.Url).WithError(err).Info("Failed to get node status")
+		return
+	}
+
+	if len(statuses) == 0 {
+		logrus.WithField("group", drainCfg.Group).Info("Node not found in the group")
+		return
+	}
+
+	var status struct {
+		NodeName string            `json:"nodeName"`
+		Drain    *node.DrainStatus `json:"drain"`
+	}
+
+	if err := json.Unmarshal(statuses[0], &status); err != nil {
+		logrus.WithError(err).Info("Failed to decode node status")
+		return
+	}
+
+	if status.Drain == nil {
+		logrus.WithField("node", status.NodeName).Info("Node is not draining")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"node":          status.NodeName,
+		"since":         status.Drain.Since,
+		"inFlight":      status.Drain.InFlight,
+		"subscriptions": status.Drain.Subscriptions,
+		"reported":      fmt.Sprintf("%v/%v", status.Drain.Reported, status.Drain.Reporters),
+		"drained":       status.Drain.Drained,
+		"timedOut":      status.Drain.TimedOut,
+	}).Info("Node drain status")
+}
+
+func listDrainingNodes(cmd *cobra.Command, args []string) {
+	client, err := dialNodeManager()
+	if err != nil {
+		logrus.WithError(err).Info("Failed to dial node manager RPC")
+		return
+	}
+	defer client.Close()
+
+	var urls []string
+	if err := client.Call(&urls, "node_listDraining"); err != nil {
+		logrus.WithError(err).Info("Failed to list draining nodes")
+		return
+	}
+
+	if len(urls) == 0 {
+		logrus.Info("No draining node found")
+		return
+	}
+
+	logrus.WithField("total", len(urls)).Info("Draining nodes:")
+
+	for i, url := range urls {
+		logrus.WithField("url", url).Info("Node #", i)
+	}
+}
+
+func dialNodeManager() (*rpc.Client, error) {
+	endpoint := drainCfg.Endpoint
+
+	if len(endpoint) == 0 {
+		switch drainCfg.Network {
+		case "cfx":
+			endpoint = node.Config().Router.NodeRPCURL
+		case "eth":
+			endpoint = node.Config().Router.EthNodeRPCURL
+		default:
+			return nil, errors.New("invalid network space")
+		}
+	}
+
+	if len(endpoint) == 0 {
+		return nil, errors.New("node manager RPC endpoint not configured")
+	}
+
+	return rpc.DialHTTP(endpoint)
+}
+
+func hookDrainCmdFlags(drainCmd *cobra.Command, hookUrl, hookGroup bool) {
+	{ // network space
+		drainCmd.Flags().StringVarP(
+			&drainCfg.Network, "network", "n", "cfx", "network space ('cfx' or 'eth')",
+		)
+	}
+
+	{ // node manager RPC endpoint
+		drainCmd.Flags().StringVarP(
+			&drainCfg.Endpoint, "endpoint", "e", "", "node manager RPC endpoint, defaults to the configured one",
+		)
+	}
+
+	if hookUrl { // node url
+		drainCmd.Flags().StringVarP(
+			&drainCfg.Url, "url", "u", "", "node url",
+		)
+		drainCmd.MarkFlagRequired("url")
+	}
+
+	if hookGroup { // node group
+		drainCmd.Flags().StringVarP(
+			&drainCfg.Group, "group", "g", "", "node group",
+		)
+		drainCmd.MarkFlagRequired("group")
+	}
+}
@@ -0,0 +1,15 @@
+package nodedrain
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	Cmd = &cobra.Command{
+		Use:   "nodedrain",
+		Short: "Node drain and maintenance utility toolset",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+)
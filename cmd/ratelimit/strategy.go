@@ -68,7 +68,8 @@ func hookStrategyCmdFlags(stratCmd *cobra.Command, hookName, hookRules bool) {
 
 	if hookRules { // strategy rules json
 		stratCmd.Flags().StringVarP(
-			&stratCfg.Rules, "rules", "r", "", "strategy rules config json",
+			&stratCfg.Rules, "rules", "r", "",
+			"strategy rules config json, with optional cost table for compute_unit algorithm and resource caps",
 		)
 		stratCmd.MarkFlagRequired("rules")
 	}
@@ -112,6 +113,7 @@ func addStrategy(cmd *cobra.Command, args []string) {
 	logrus.WithFields(logrus.Fields{
 		"name":  strategy.Name,
 		"rules": strategy.LimitOptions,
+		"caps":  strategy.Caps,
 	}).Info("Press the Enter Key to ", op)
 	fmt.Scanln() // wait for Enter Key
 
@@ -195,6 +197,7 @@ func listStrategies(cmd *cobra.Command, args []string) {
 			"name":  s.Name,
 			"ID":    s.ID,
 			"rules": s.LimitOptions,
+			"caps":  s.Caps,
 		}).Info("Strategy #", i)
 	}
 }
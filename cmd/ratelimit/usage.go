@@ -0,0 +1,188 @@
+package ratelimit
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Conflux-Chain/confura/cmd/util"
+	"github.com/Conflux-Chain/confura/util/rate"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type usageCmdConfig struct {
+	Network  string   // RPC network space ("cfx" or "eth")
+	LimitKey string   // rate limit key
+	From     string   // start time (RFC3339 or date)
+	To       string   // end time (RFC3339 or date)
+	Methods  []string // RPC methods
+	Output   string   // CSV file path to export
+}
+
+var (
+	usageCfg usageCmdConfig
+
+	usageCmd = &cobra.Command{
+		Use:   "usage",
+		Short: "Query or export hourly usages of rate limit key",
+		Run:   queryUsage,
+	}
+)
+
+func init() {
+	Cmd.AddCommand(usageCmd)
+
+	usageCmd.Flags().StringVarP(
+		&usageCfg.Network, "network", "n", "cfx", "RPC network space ('cfx' or 'eth')",
+	)
+	usageCmd.MarkFlagRequired("network")
+
+	usageCmd.Flags().StringVarP(&usageCfg.LimitKey, "key", "k", "", "rate limit key")
+	usageCmd.MarkFlagRequired("key")
+
+	usageCmd.Flags().StringVarP(
+		&usageCfg.From, "from", "f", "", "start time in RFC3339 or date format (default 24 hours ago)",
+	)
+	usageCmd.Flags().StringVarP(
+		&usageCfg.To, "to", "t", "", "end time in RFC3339 or date format (default now)",
+	)
+	usageCmd.Flags().StringSliceVarP(
+		&usageCfg.Methods, "methods", "m", nil, "RPC methods to query (default all)",
+	)
+	usageCmd.Flags().StringVarP(
+		&usageCfg.Output, "output", "o", "", "CSV file path to export usages",
+	)
+}
+
+func queryUsage(cmd *cobra.Command, args []string) {
+	filter, err := validateUsageCmdConfig()
+	if err != nil {
+		logrus.WithField("config", usageCfg).WithError(err).Info("Invalid command config")
+		return
+	}
+
+	storeCtx := util.MustInitStoreContext()
+	defer storeCtx.Close()
+
+	dbs, err := storeCtx.GetMysqlStore(usageCfg.Network)
+	if err != nil {
+		logrus.WithError(err).Info("Failed to get mysql store by network")
+		return
+	}
+
+	if dbs == nil {
+		logrus.Info("DB store is unavailable")
+		return
+	}
+
+	usages, err := dbs.LoadRateLimitUsages(filter)
+	if err != nil {
+		logrus.WithError(err).Info("Failed to load rate limit usages")
+		return
+	}
+
+	if len(usages) == 0 {
+		logrus.Info("No rate limit usages found")
+		return
+	}
+
+	if len(usageCfg.Output) > 0 {
+		if err := exportUsages(usageCfg.Output, usages); err != nil {
+			logrus.WithError(err).Info("Failed to export rate limit usages")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"total":  len(usages),
+				"output": usageCfg.Output,
+			}).Info("Rate limit usages exported")
+		}
+
+		return
+	}
+
+	logrus.WithField("total", len(usages)).Info("Rate limit usages loaded:")
+
+	for _, u := range usages {
+		logrus.WithFields(logrus.Fields{
+			"hour":           u.Hour.Format(time.RFC3339),
+			"method":         u.Method,
+			"requests":       u.Requests,
+			"errors":         u.Errors,
+			"totalLatencyMs": u.TotalLatencyMs,
+			"maxLatencyMs":   u.MaxLatencyMs,
+		}).Info("Usage")
+	}
+}
+
+func exportUsages(path string, usages []*rate.Usage) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{
+		"limit_key", "hour", "method", "requests", "errors", "total_latency_ms", "max_latency_ms",
+	})
+
+	for _, u := range usages {
+		w.Write([]string{
+			u.LimitKey,
+			u.Hour.Format(time.RFC3339),
+			u.Method,
+			strconv.FormatUint(u.Requests, 10),
+			strconv.FormatUint(u.Errors, 10),
+			strconv.FormatUint(u.TotalLatencyMs, 10),
+			strconv.FormatUint(u.MaxLatencyMs, 10),
+		})
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func validateUsageCmdConfig() (*rate.UsageFilter, error) {
+	if len(usageCfg.LimitKey) == 0 {
+		return nil, errors.New("rate limit key must not be empty")
+	}
+
+	now := time.Now()
+	filter := &rate.UsageFilter{
+		LimitKey: usageCfg.LimitKey,
+		Methods:  usageCfg.Methods,
+		From:     now.Add(-24 * time.Hour),
+		To:       now,
+	}
+
+	var err error
+
+	if len(usageCfg.From) > 0 {
+		if filter.From, err = parseUsageTime(usageCfg.From); err != nil {
+			return nil, errors.WithMessage(err, "invalid start time")
+		}
+	}
+
+	if len(usageCfg.To) > 0 {
+		if filter.To, err = parseUsageTime(usageCfg.To); err != nil {
+			return nil, errors.WithMessage(err, "invalid end time")
+		}
+	}
+
+	if filter.From.After(filter.To) {
+		return nil, errors.New("start time must not be after end time")
+	}
+
+	filter.From = filter.From.Truncate(time.Hour)
+	return filter, nil
+}
+
+func parseUsageTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
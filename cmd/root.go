@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/Conflux-Chain/confura/cmd/acl"
+	"github.com/Conflux-Chain/confura/cmd/nodedrain"
 	"github.com/Conflux-Chain/confura/cmd/noderoute"
 	"github.com/Conflux-Chain/confura/cmd/ratelimit"
 	"github.com/Conflux-Chain/confura/cmd/test"
@@ -59,6 +60,7 @@ func init() {
 	rootCmd.AddCommand(test.Cmd)
 	rootCmd.AddCommand(ratelimit.Cmd)
 	rootCmd.AddCommand(noderoute.Cmd)
+	rootCmd.AddCommand(nodedrain.Cmd)
 	rootCmd.AddCommand(acl.Cmd)
 }
 
@@ -13,11 +13,14 @@ import (
 	"github.com/Conflux-Chain/confura/node"
 	"github.com/Conflux-Chain/confura/rpc"
 	"github.com/Conflux-Chain/confura/rpc/handler"
+	"github.com/Conflux-Chain/confura/store/mysql"
 	"github.com/Conflux-Chain/confura/store/redis"
+	"github.com/Conflux-Chain/confura/util/accesslog"
 	"github.com/Conflux-Chain/confura/util/acl"
 	"github.com/Conflux-Chain/confura/util/rate"
 	"github.com/Conflux-Chain/confura/util/relay"
 	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
+	rpccache "github.com/Conflux-Chain/confura/util/rpc/cache"
 	vfclient "github.com/Conflux-Chain/confura/virtualfilter/client"
 	viperutil "github.com/Conflux-Chain/go-conflux-util/viper"
 )
@@ -90,8 +93,11 @@ func startNativeSpaceRpcServer(ctx context.Context, wg *sync.WaitGroup, storeCtx
 	clientProvider := node.NewCfxClientProvider(storeCtx.CfxDB, router)
 	relayer := relay.MustNewTxnRelayerFromViper()
 
+	txnTracker := handler.MustNewTxnTrackerFromViper(storeCtx.CfxDB)
+
 	option := rpc.CfxAPIOption{
-		TxnHandler: handler.MustNewCfxTxnHandler(relayer),
+		TxnHandler: handler.MustNewCfxTxnHandler(relayer, txnTracker),
+		TxnTracker: txnTracker,
 	}
 
 	if vfc, ok := vfclient.MustNewCfxClientFromViper(); ok {
@@ -104,10 +110,13 @@ func startNativeSpaceRpcServer(ctx context.Context, wg *sync.WaitGroup, storeCtx
 		option.StoreHandler = handler.NewCfxCommonStoreHandler("db", storeCtx.CfxDB, option.StoreHandler)
 
 		rateKeyLoader := rate.NewKeyLoader(storeCtx.CfxDB.LoadRateLimitKeyInfos)
-		rateReg = rate.NewRegistry(rateKeyLoader, acl.NewCfxValidator)
+		rateReg = mustNewRateRegistry(rateKeyLoader, acl.NewCfxValidator, storeCtx.CfxDB)
 
 		// periodically reload rate limit settings from db
 		go rateReg.AutoReload(15*time.Second, storeCtx.CfxDB.LoadRateLimitConfigs)
+
+		option.ResponseCache = mustNewResponseCache("cfx", storeCtx.CfxDB, clientProvider)
+		option.ReorgHandler = handler.NewReorgHandler(storeCtx.CfxDB)
 	}
 
 	if storeCtx.CfxCache != nil {
@@ -133,19 +142,24 @@ func startNativeSpaceRpcServer(ctx context.Context, wg *sync.WaitGroup, storeCtx
 		option.LogApiHandler = handler.NewCfxLogsApiHandler(storeCtx.CfxDB, prunedHandler)
 	}
 
+	option.AccessLogger = mustNewAccessLogger("cfx", storeCtx.CfxDB)
+
 	// initialize RPC server
 	exposedModules := viper.GetStringSlice("rpc.exposedModules")
 	server := rpc.MustNewNativeSpaceServer(rateReg, clientProvider, gasHandler, exposedModules, option)
 
 	// serve HTTP endpoint
+	var serverWg sync.WaitGroup
 	httpEndpoint := viper.GetString("rpc.endpoint")
-	go server.MustServeGraceful(ctx, wg, httpEndpoint, rpcutil.ProtocolHttp)
+	go server.MustServeGraceful(ctx, &serverWg, httpEndpoint, rpcutil.ProtocolHttp)
 
 	// serve Websocket endpoint
 	if wsEndpoint := viper.GetString("rpc.wsEndpoint"); len(wsEndpoint) > 0 {
-		go server.MustServeGraceful(ctx, wg, wsEndpoint, rpcutil.ProtocolWS)
+		go server.MustServeGraceful(ctx, &serverWg, wsEndpoint, rpcutil.ProtocolWS)
 	}
 
+	go closeAccessLoggerOnShutdown(ctx, wg, &serverWg, option.AccessLogger)
+
 	// serve debug endpoint
 	if debugEndpoint := viper.GetString("rpc.debugEndpoint"); len(debugEndpoint) > 0 {
 		server := rpc.MustNewDebugServer()
@@ -161,8 +175,11 @@ func startEvmSpaceRpcServer(ctx context.Context, wg *sync.WaitGroup, storeCtx ut
 	clientProvider := node.NewEthClientProvider(storeCtx.EthDB, router)
 	relayer := relay.MustNewEthTxnRelayerFromViper()
 
+	txnTracker := handler.MustNewTxnTrackerFromViper(storeCtx.EthDB)
+
 	option := rpc.EthAPIOption{
-		TxnHandler: handler.MustNewEthTxnHandler(relayer),
+		TxnHandler: handler.MustNewEthTxnHandler(relayer, txnTracker),
+		TxnTracker: txnTracker,
 	}
 
 	if vfc, ok := vfclient.MustNewEthClientFromViper(); ok {
@@ -180,25 +197,33 @@ func startEvmSpaceRpcServer(ctx context.Context, wg *sync.WaitGroup, storeCtx ut
 		option.LogApiHandler = handler.NewEthLogsApiHandler(storeCtx.EthDB)
 
 		rateKeyLoader := rate.NewKeyLoader(storeCtx.EthDB.LoadRateLimitKeyInfos)
-		rateReg = rate.NewRegistry(rateKeyLoader, acl.NewEthValidator)
+		rateReg = mustNewRateRegistry(rateKeyLoader, acl.NewEthValidator, storeCtx.EthDB)
 
 		// periodically reload rate limit settings from db
 		go rateReg.AutoReload(15*time.Second, storeCtx.EthDB.LoadRateLimitConfigs)
+
+		option.ResponseCache = mustNewResponseCache("eth", storeCtx.EthDB, clientProvider)
+		option.ReorgHandler = handler.NewReorgHandler(storeCtx.EthDB)
 	}
 
+	option.AccessLogger = mustNewAccessLogger("eth", storeCtx.EthDB)
+
 	// initialize RPC server
 	exposedModules := viper.GetStringSlice("ethrpc.exposedModules")
 	server := rpc.MustNewEvmSpaceServer(rateReg, clientProvider, gasHandler, exposedModules, option)
 
 	// serve HTTP endpoint
+	var serverWg sync.WaitGroup
 	httpEndpoint := viper.GetString("ethrpc.endpoint")
-	go server.MustServeGraceful(ctx, wg, httpEndpoint, rpcutil.ProtocolHttp)
+	go server.MustServeGraceful(ctx, &serverWg, httpEndpoint, rpcutil.ProtocolHttp)
 
 	// serve Websocket endpoint
 	if wsEndpoint := viper.GetString("ethrpc.wsEndpoint"); len(wsEndpoint) > 0 {
-		go server.MustServeGraceful(ctx, wg, wsEndpoint, rpcutil.ProtocolWS)
+		go server.MustServeGraceful(ctx, &serverWg, wsEndpoint, rpcutil.ProtocolWS)
 	}
 
+	go closeAccessLoggerOnShutdown(ctx, wg, &serverWg, option.AccessLogger)
+
 	// serve debug endpoint
 	if debugEndpoint := viper.GetString("ethrpc.debugEndpoint"); len(debugEndpoint) > 0 {
 		server := rpc.MustNewDebugServer()
@@ -212,7 +237,7 @@ func startNativeSpaceBridgeRpcServer(ctx context.Context, wg *sync.WaitGroup, st
 	var rateReg *rate.Registry
 	if storeCtx.CfxDB != nil {
 		rateKeyLoader := rate.NewKeyLoader(storeCtx.CfxDB.LoadRateLimitKeyInfos)
-		rateReg = rate.NewRegistry(rateKeyLoader, acl.NewCfxValidator)
+		rateReg = mustNewRateRegistry(rateKeyLoader, acl.NewCfxValidator, storeCtx.CfxDB)
 
 		// periodically reload rate limit settings from db
 		go rateReg.AutoReload(15*time.Second, storeCtx.CfxDB.LoadRateLimitConfigs)
@@ -226,3 +251,73 @@ func startNativeSpaceBridgeRpcServer(ctx context.Context, wg *sync.WaitGroup, st
 	server := rpc.MustNewNativeSpaceBridgeServer(rateReg, &config)
 	go server.MustServeGraceful(ctx, wg, config.Endpoint, rpcutil.ProtocolHttp)
 }
+
+// mustNewRateRegistry creates rate limit registry, with Redis backed limiters shared across
+// the fleet and per-key usage accounting if configured.
+func mustNewRateRegistry(
+	kloader *rate.KeyLoader, valFactory acl.ValidatorFactory, usageStore rate.UsageStore,
+) *rate.Registry {
+	var option rate.RegistryOption
+
+	if redisUrl := viper.GetString("requestControl.rateLimit.redisUrl"); len(redisUrl) > 0 {
+		option.RedisClient = redis.MustNewRedisClient(redisUrl)
+		logrus.Info("Redis backed rate limiters enabled")
+	}
+
+	if viper.GetBool("requestControl.rateLimit.usageAccounting") {
+		option.UsageStore = usageStore
+		option.UsageFlushInterval = viper.GetDuration("requestControl.rateLimit.usageFlushInterval")
+		logrus.Info("Rate limit key usage accounting enabled")
+	}
+
+	return rate.NewRegistry(kloader, valFactory, option)
+}
+
+// mustNewResponseCache creates response cache for immutable RPC calls if enabled, which will be
+// invalidated by the chain reorg version of store.
+func mustNewResponseCache(
+	space string, provider rpccache.ChainStatusProvider, finalizer rpccache.FinalizedEpochProvider,
+) *rpccache.ResponseCache {
+	config := rpccache.NewResponseCacheConfig()
+	viperutil.MustUnmarshalKey("requestControl.responseCache", &config)
+
+	if !config.Enabled {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"space":  space,
+		"config": config,
+	}).Info("RPC response cache enabled")
+
+	if len(config.RedisUrl) == 0 {
+		return rpccache.NewResponseCache(space, provider, finalizer, config)
+	}
+
+	return rpccache.NewResponseCache(space, provider, finalizer, config, redis.MustNewRedisClient(config.RedisUrl))
+}
+
+// closeAccessLoggerOnShutdown waits for the RPC servers to shut down, and then closes the access
+// logger if enabled, so that the access logs of in-flight RPC calls are flushed into sinks.
+func closeAccessLoggerOnShutdown(
+	ctx context.Context, wg, serverWg *sync.WaitGroup, logger *accesslog.Logger,
+) {
+	wg.Add(1)
+	defer wg.Done()
+
+	<-ctx.Done()
+	serverWg.Wait()
+
+	if logger != nil {
+		logger.Close()
+	}
+}
+
+func mustNewAccessLogger(space string, db *mysql.MysqlStore) *accesslog.Logger {
+	// avoid typed nil store, which is not nil as interface
+	if db == nil {
+		return accesslog.MustNewLoggerFromViper(space, nil)
+	}
+
+	return accesslog.MustNewLoggerFromViper(space, db)
+}
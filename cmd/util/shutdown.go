@@ -7,6 +7,7 @@ import (
 	"sync"
 	"syscall"
 
+	"github.com/Conflux-Chain/confura/util/tracing"
 	"github.com/sirupsen/logrus"
 )
 
@@ -33,6 +34,9 @@ func GracefulShutdown(wg *sync.WaitGroup, cancel context.CancelFunc) {
 	logrus.Info("Waiting for shutdown...")
 	wg.Wait()
 
+	// flush the pending spans if tracing enabled
+	tracing.Shutdown()
+
 	logrus.Info("Shutdown gracefully")
 }
 
@@ -5,9 +5,11 @@ import (
 	"github.com/Conflux-Chain/confura/rpc"
 	"github.com/Conflux-Chain/confura/store"
 	"github.com/Conflux-Chain/confura/util/blacklist"
+	metricsutil "github.com/Conflux-Chain/confura/util/metrics"
 	"github.com/Conflux-Chain/confura/util/pprof"
 	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
 	"github.com/Conflux-Chain/confura/util/rpc/cache"
+	"github.com/Conflux-Chain/confura/util/tracing"
 	"github.com/Conflux-Chain/go-conflux-util/config"
 	metricUtil "github.com/Conflux-Chain/go-conflux-util/metrics"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -29,6 +31,12 @@ func Init() {
 	// init pprof
 	pprof.MustInit()
 
+	// init prometheus exporter
+	metricsutil.MustInitPrometheusFromViper()
+
+	// init tracing, which should be initialized before rpc middlewares and clients
+	tracing.MustInit()
+
 	// init misc util
 	cache.MustInitFromViper()
 	rpcutil.MustInit()
@@ -0,0 +1,257 @@
+package node
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-sdk/types/cfxaddress"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/go-rpc-provider/utils"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// JSON-RPC error code if method not found
+	errCodeMethodNotFound = -32601
+
+	// min state depth to probe, and full nodes are supposed to keep state of recent epochs within it
+	minProbeStateDepth = 128
+)
+
+var (
+	// known full node error messages (all parts in lower case must be present) if state unavailable
+	stateUnavailableErrors = [][]string{
+		{"missing trie node"},                     // geth (hash scheme)
+		{"historical state", "is not available"},  // geth (path scheme)
+		{"required historical state unavailable"}, // geth
+		{"state for epoch", "does not exist"},     // conflux
+		{"state", "has been pruned"},              // erigon
+	}
+
+	// core space optional namespaces => probe method and arguments
+	cfxProbeNamespaces = map[string]probeCall{
+		"trace":  {"trace_block", []interface{}{common.Hash{}}},
+		"txpool": {"txpool_status", nil},
+	}
+
+	// evm space optional namespaces => probe method and arguments
+	ethProbeNamespaces = map[string]probeCall{
+		"trace":  {"trace_block", []interface{}{"0x0"}},
+		"debug":  {"debug_traceTransaction", []interface{}{common.Hash{}}},
+		"txpool": {"txpool_status", nil},
+	}
+)
+
+// Capabilities represents the probed capabilities of a full node.
+type Capabilities struct {
+	// client version of full node
+	ClientVersion string `json:"clientVersion"`
+	// whether full node keeps full history state since genesis
+	Archive bool `json:"archive"`
+	// max depth of recent epochs whose state is available, 0 if only the latest state is available
+	StateDepth uint64 `json:"stateDepth"`
+	// available optional RPC namespaces, e.g., `trace`, `debug` or `txpool`
+	Namespaces []string `json:"namespaces"`
+	// when capabilities probed
+	ProbedAt time.Time `json:"probedAt"`
+}
+
+// HasNamespace checks whether the RPC namespace is available.
+func (c *Capabilities) HasNamespace(namespace string) bool {
+	return len(namespace) == 0 || slices.Contains(c.Namespaces, namespace)
+}
+
+// HasStateAt checks whether the state at the specified epoch is available when full node has
+// reached the latest epoch.
+func (c *Capabilities) HasStateAt(latestEpoch, epoch uint64) bool {
+	if epoch == 0 || epoch >= latestEpoch || latestEpoch-epoch <= minProbeStateDepth {
+		return true
+	}
+
+	return c.Archive || latestEpoch-epoch <= c.StateDepth
+}
+
+// probeCall is the RPC call to probe some capability.
+type probeCall struct {
+	method string
+	args   []interface{}
+}
+
+// capabilityProber probes capabilities of full node by RPC calls.
+type capabilityProber struct {
+	// RPC call function
+	call func(result interface{}, method string, args ...interface{}) error
+	// latest epoch number of full node
+	latestEpoch func() (uint64, error)
+	// method to get client version
+	clientVersion string
+	// probe call to get state at the specified epoch
+	stateAt func(epoch uint64) (probeCall, error)
+	// optional namespaces to probe
+	namespaces map[string]probeCall
+}
+
+func newCfxCapabilityProber(n *CfxNode) *capabilityProber {
+	return &capabilityProber{
+		call:          n.CallRPC,
+		latestEpoch:   n.LatestEpochNumber,
+		clientVersion: "cfx_clientVersion",
+		stateAt: func(epoch uint64) (probeCall, error) {
+			// network ID is required to encode base32 address
+			networkId, err := n.GetNetworkID()
+			if err != nil {
+				return probeCall{}, errors.WithMessage(err, "failed to get network ID")
+			}
+
+			addr := cfxaddress.MustNewFromHex("0x0000000000000000000000000000000000000000", networkId)
+			return probeCall{"cfx_getBalance", []interface{}{addr, hexutil.Uint64(epoch)}}, nil
+		},
+		namespaces: cfxProbeNamespaces,
+	}
+}
+
+func newEthCapabilityProber(n *EthNode) *capabilityProber {
+	return &capabilityProber{
+		call: func(result interface{}, method string, args ...interface{}) error {
+			return n.Provider().CallContext(context.Background(), result, method, args...)
+		},
+		latestEpoch:   n.LatestEpochNumber,
+		clientVersion: "web3_clientVersion",
+		stateAt: func(epoch uint64) (probeCall, error) {
+			return probeCall{"eth_getBalance", []interface{}{common.Address{}, hexutil.Uint64(epoch)}}, nil
+		},
+		namespaces: ethProbeNamespaces,
+	}
+}
+
+// probe probes the full node capabilities, and returns error if full node is not available.
+func (p *capabilityProber) probe() (c Capabilities, err error) {
+	if err = p.call(&c.ClientVersion, p.clientVersion); err != nil && !utils.IsRPCJSONError(err) {
+		return c, errors.WithMessage(err, "failed to get client version")
+	}
+
+	latestEpoch, err := p.latestEpoch()
+	if err != nil {
+		return c, errors.WithMessage(err, "failed to get latest epoch")
+	}
+
+	// probe state depth exponentially
+	for depth := uint64(minProbeStateDepth); depth < latestEpoch; depth *= 8 {
+		ok, err := p.hasStateAt(latestEpoch - depth)
+		if err != nil {
+			return c, errors.WithMessagef(err, "failed to probe state depth %v", depth)
+		}
+
+		if !ok {
+			break
+		}
+
+		c.StateDepth = depth
+	}
+
+	if c.StateDepth > 0 {
+		ok, err := p.hasStateAt(1)
+		if err != nil {
+			return c, errors.WithMessage(err, "failed to probe archive state")
+		}
+
+		if c.Archive = ok; ok {
+			c.StateDepth = latestEpoch - 1
+		}
+	}
+
+	for namespace, pc := range p.namespaces {
+		ok, err := p.available(pc, isMethodNotFound)
+		if err != nil {
+			return c, errors.WithMessagef(err, "failed to probe namespace %v", namespace)
+		}
+
+		if ok {
+			c.Namespaces = append(c.Namespaces, namespace)
+		}
+	}
+
+	slices.Sort(c.Namespaces)
+	c.ProbedAt = time.Now()
+
+	return c, nil
+}
+
+func (p *capabilityProber) hasStateAt(epoch uint64) (bool, error) {
+	pc, err := p.stateAt(epoch)
+	if err != nil {
+		return false, err
+	}
+
+	return p.available(pc, isStateUnavailable)
+}
+
+// available checks whether the probe call succeeded. Note, it's regarded as available if failed due to
+// JSON-RPC error other than the unavailable one, e.g., invalid parameters or data not found.
+func (p *capabilityProber) available(pc probeCall, unavailable func(err error) bool) (bool, error) {
+	var result interface{}
+
+	err := p.call(&result, pc.method, pc.args...)
+	if err == nil {
+		return true, nil
+	}
+
+	if !utils.IsRPCJSONError(err) { // e.g., io error
+		return false, err
+	}
+
+	return !unavailable(err), nil
+}
+
+func isMethodNotFound(err error) bool {
+	var rpcErr interface{ ErrorCode() int }
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == errCodeMethodNotFound {
+		return true
+	}
+
+	errMsg := strings.ToLower(err.Error())
+	return strings.Contains(errMsg, "method") &&
+		(strings.Contains(errMsg, "not found") || strings.Contains(errMsg, "does not exist"))
+}
+
+func isStateUnavailable(err error) bool {
+	errMsg := strings.ToLower(err.Error())
+
+	for _, parts := range stateUnavailableErrors {
+		matched := true
+		for _, part := range parts {
+			matched = matched && strings.Contains(errMsg, part)
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// probe periodically probes full node capabilities until node closed.
+func (n *baseNode) probe(ctx context.Context, prober *capabilityProber) {
+	ticker := time.NewTicker(cfg.Monitor.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		if c, err := prober.probe(); err != nil {
+			logrus.WithField("name", n.name).WithError(err).Info("Failed to probe node capabilities")
+		} else {
+			status := n.Status()
+			status.setCapabilities(c)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
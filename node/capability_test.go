@@ -0,0 +1,119 @@
+package node
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilitiesHasStateAt(t *testing.T) {
+	c := Capabilities{StateDepth: 1024}
+
+	assert.True(t, c.HasStateAt(10000, 0))
+	assert.True(t, c.HasStateAt(10000, 10000))
+	assert.True(t, c.HasStateAt(10000, 10000-minProbeStateDepth))
+	assert.True(t, c.HasStateAt(10000, 10000-1024))
+	assert.False(t, c.HasStateAt(10000, 10000-1025))
+
+	c.Archive = true
+	assert.True(t, c.HasStateAt(10000, 1))
+}
+
+func TestCapabilityProberProbe(t *testing.T) {
+	const latestEpoch, prunedEpoch = 100000, 90000
+
+	prober := &capabilityProber{
+		call: func(result interface{}, method string, args ...interface{}) error {
+			switch method {
+			case "web3_clientVersion":
+				*result.(*string) = "geth/v1.0.0"
+			case "eth_getBalance":
+				if uint64(args[1].(hexutil.Uint64)) < prunedEpoch {
+					return &rpc.JsonError{Code: -32000, Message: "missing trie node"}
+				}
+			case "debug_traceTransaction":
+				return &rpc.JsonError{Code: -32000, Message: "transaction not found"}
+			case "txpool_status":
+				return errors.New("connection refused")
+			default:
+				return &rpc.JsonError{Code: errCodeMethodNotFound, Message: "method not found"}
+			}
+
+			return nil
+		},
+		latestEpoch:   func() (uint64, error) { return latestEpoch, nil },
+		clientVersion: "web3_clientVersion",
+		stateAt: func(epoch uint64) (probeCall, error) {
+			return probeCall{"eth_getBalance", []interface{}{"0x0", hexutil.Uint64(epoch)}}, nil
+		},
+		namespaces: map[string]probeCall{
+			"trace": {"trace_block", nil},
+			"debug": {"debug_traceTransaction", nil},
+		},
+	}
+
+	c, err := prober.probe()
+	assert.NoError(t, err)
+	assert.Equal(t, "geth/v1.0.0", c.ClientVersion)
+	assert.False(t, c.Archive)
+	assert.Equal(t, uint64(8192), c.StateDepth)
+	assert.Equal(t, []string{"debug"}, c.Namespaces)
+
+	// io error
+	prober.namespaces["txpool"] = probeCall{"txpool_status", nil}
+	_, err = prober.probe()
+	assert.Error(t, err)
+}
+
+func TestManagerDistributeWithCapabilityHint(t *testing.T) {
+	MustInit()
+
+	m := NewManager(GroupEthHttp)
+
+	for i := 1; i <= 3; i++ {
+		name := "node" + strconv.Itoa(i)
+		n, _ := newDummyNode(GroupEthHttp, name, "http://"+name)
+		m.Add(n)
+		m.ReportEpoch(name, 100000)
+
+		if i == 3 {
+			status := n.Status()
+			status.setCapabilities(Capabilities{Archive: true, Namespaces: []string{"debug"}})
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		key := []byte(strconv.Itoa(i))
+
+		assert.Equal(t, "node3", m.DistributeWithHint(key, RouteHint{Namespace: "debug"}).Name())
+		assert.Equal(t, "node3", m.DistributeWithHint(key, RouteHint{StateEpoch: 1}).Name())
+
+		// recent state available for all full nodes
+		assert.Equal(t, m.Distribute(key), m.DistributeWithHint(key, RouteHint{StateEpoch: 99999}))
+	}
+}
+
+func TestIsStateUnavailable(t *testing.T) {
+	for _, msg := range []string{
+		"missing trie node 6b1c2e (path ) state 0x1 is not available",
+		"historical state 0xabcd is not available",
+		"required historical state unavailable (reexec=128)",
+		"State for epoch (number=Some(1) hash=None) does not exist: out-of-bound StateAvailabilityBoundary [100, 200]",
+		"state at block 100 has been pruned",
+	} {
+		assert.True(t, isStateUnavailable(&rpc.JsonError{Code: -32000, Message: msg}), msg)
+	}
+
+	for _, msg := range []string{
+		"invalid state override",
+		"execution reverted: bad state",
+		"header not found",
+		"pruned",
+	} {
+		assert.False(t, isStateUnavailable(&rpc.JsonError{Code: -32000, Message: msg}), msg)
+	}
+}
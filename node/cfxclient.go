@@ -2,6 +2,8 @@ package node
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 
 	"github.com/Conflux-Chain/confura/store/mysql"
 	"github.com/Conflux-Chain/confura/util/rpc"
@@ -13,8 +15,13 @@ type CfxClientProvider struct {
 	*clientProvider
 }
 
-func newCfxClient(url string) (interface{}, error) {
-	return rpc.NewCfxClient(url, rpc.WithClientHookMetrics(true), rpc.WithClientHookCache(true))
+func newCfxClient(url string, group Group) (interface{}, error) {
+	return rpc.NewCfxClient(
+		url,
+		rpc.WithClientHookMetrics(true),
+		rpc.WithClientHookCache(true),
+		rpc.WithClientHookCoalesce(string(group)),
+	)
 }
 
 func NewCfxClientProvider(db *mysql.MysqlStore, router Router) *CfxClientProvider {
@@ -33,10 +40,23 @@ func (p *CfxClientProvider) GetClient(key string, groups ...Group) (sdk.ClientOp
 	return client.(sdk.ClientOperator), nil
 }
 
-// GetClientByIP gets client of specific group (or use normal HTTP group as default) by remote IP address.
+// GetClientWithHint gets client of specific group (or use normal HTTP group as default), which prefers
+// full nodes that have reached the hinted block height.
+func (p *CfxClientProvider) GetClientWithHint(key string, hint RouteHint, groups ...Group) (sdk.ClientOperator, error) {
+	client, err := p.getClient(key, cfxNodeGroup(groups...), hint)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.(sdk.ClientOperator), nil
+}
+
+// GetClientByIP gets client of specific group (or use normal HTTP group as default) by remote IP address,
+// which prefers full nodes that have reached the block height hinted in context if any.
 func (p *CfxClientProvider) GetClientByIP(ctx context.Context, groups ...Group) (sdk.ClientOperator, error) {
 	remoteAddr := remoteAddrFromContext(ctx)
-	client, err := p.getClient(remoteAddr, cfxNodeGroup(groups...))
+	hint, _ := RouteHintFromContext(ctx)
+	client, err := p.getClient(remoteAddr, cfxNodeGroup(groups...), hint)
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +64,21 @@ func (p *CfxClientProvider) GetClientByIP(ctx context.Context, groups ...Group)
 	return client.(sdk.ClientOperator), nil
 }
 
+// FinalizedEpoch returns the latest finalized epoch number from a random full node.
+func (p *CfxClientProvider) FinalizedEpoch() (uint64, error) {
+	cfx, err := p.GetClient(fmt.Sprintf("random_key_%v", rand.Int()))
+	if err != nil {
+		return 0, err
+	}
+
+	status, err := cfx.GetStatus()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(status.LatestFinalized), nil
+}
+
 // GetClientsByGroup gets all clients of specific group.
 func (p *CfxClientProvider) GetClientsByGroup(grp Group) (clients []sdk.ClientOperator, err error) {
 	np := locateNodeProvider(p.router)
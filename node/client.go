@@ -2,13 +2,16 @@ package node
 
 import (
 	"context"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Conflux-Chain/confura/store/mysql"
 	"github.com/Conflux-Chain/confura/util"
 	"github.com/Conflux-Chain/confura/util/rpc"
 	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -24,7 +27,7 @@ var (
 )
 
 // clientFactory factory method to create RPC client for fullnode proxy.
-type clientFactory func(url string) (interface{}, error)
+type clientFactory func(url string, group Group) (interface{}, error)
 
 // clientProvider provides different RPC client based on request IP to achieve load balance
 // or with node group for resource isolation. Generally, it is used by RPC server to delegate
@@ -41,6 +44,14 @@ type clientProvider struct {
 
 	// group => node name => RPC client
 	clients *util.ConcurrentMap
+
+	// policy to send hedged requests to alternative full nodes of the same group
+	hedgePolicy atomic.Pointer[rpc.HedgePolicy]
+}
+
+// middlewarableClient is implemented by RPC clients that support to hook middlewares.
+type middlewarableClient interface {
+	Provider() *providers.MiddlewarableProvider
 }
 
 func newClientProvider(db *mysql.MysqlStore, router Router, factory clientFactory) *clientProvider {
@@ -131,9 +142,14 @@ func (p *clientProvider) populateCache(token string) (grp Group, ok bool) {
 	return grp, true
 }
 
-// getClient gets client based on keyword and node group type.
-func (p *clientProvider) getClient(key string, group Group) (interface{}, error) {
-	url := p.router.Route(group, []byte(key))
+// getClient gets client based on keyword, node group type and optional route hint.
+func (p *clientProvider) getClient(key string, group Group, hints ...RouteHint) (interface{}, error) {
+	var hint RouteHint
+	if len(hints) > 0 {
+		hint = hints[0]
+	}
+
+	url := routeWithHint(p.router, group, []byte(key), hint)
 	if len(url) == 0 {
 		logrus.WithFields(logrus.Fields{
 			"key":   key,
@@ -161,7 +177,18 @@ func (p *clientProvider) getOrRegisterClient(url string, group Group) (interface
 		// TODO improvements required
 		// 1. Necessary retry? (but longer timeout). Better to let user side to decide.
 		// 2. Different metrics for different full nodes.
-		return p.factory(url)
+		client, err := p.factory(url, group)
+		if err != nil {
+			return nil, err
+		}
+
+		if mc, ok := client.(middlewarableClient); ok {
+			mc.Provider().HookCallContext(rpc.MiddlewareHedge(p.hedgePolicy.Load, func() (string, providers.CallContextFunc) {
+				return p.alternativeCallContext(group, nodeName)
+			}))
+		}
+
+		return client, nil
 	})
 
 	if err != nil {
@@ -193,3 +220,43 @@ func accessTokenFromContext(ctx context.Context) string {
 
 	return "unknown_access_token"
 }
+
+// SetHedgePolicy sets the policy to send hedged requests to alternative full nodes of the same group,
+// or nil to disable hedging.
+func (p *clientProvider) SetHedgePolicy(policy *rpc.HedgePolicy) {
+	p.hedgePolicy.Store(policy)
+}
+
+// alternativeCallContext returns the node name and `CallContext` function of a random full node of the
+// group other than the specified one, or nil function if not available.
+func (p *clientProvider) alternativeCallContext(
+	group Group, excludedNodeName string,
+) (string, providers.CallContextFunc) {
+	np := locateNodeProvider(p.router)
+	if np == nil {
+		return "", nil
+	}
+
+	var urls []string
+	for _, url := range np.ListNodesByGroup(group) {
+		if rpc.Url2NodeName(url) != excludedNodeName {
+			urls = append(urls, url)
+		}
+	}
+
+	if len(urls) == 0 {
+		return "", nil
+	}
+
+	url := urls[rand.Intn(len(urls))]
+	client, err := p.getOrRegisterClient(url, group)
+	if err != nil {
+		return "", nil
+	}
+
+	if mc, ok := client.(middlewarableClient); ok {
+		return rpc.Url2NodeName(url), mc.Provider().CallContext
+	}
+
+	return "", nil
+}
@@ -83,7 +83,9 @@ type config struct {
 	}
 	Monitor struct {
 		Interval time.Duration `default:"1s"`
-		Unhealth struct {
+		// interval to probe node capabilities, e.g., archive state and optional RPC namespaces
+		ProbeInterval time.Duration `default:"10m"`
+		Unhealth      struct {
 			Failures          uint64        `default:"3"`
 			EpochsFallBehind  uint64        `default:"30"`
 			LatencyPercentile float64       `default:"0.9"`
@@ -94,6 +96,27 @@ type config struct {
 			SuccessCounter uint64        `default:"60"`
 		}
 	}
+	Discovery struct {
+		// interval to reconcile group nodes against the discovery sources
+		Interval time.Duration `default:"30s"`
+		// whether to persist the reconciled group nodes into db
+		Persist bool
+		// core space group => discovery source
+		Sources map[Group]DiscoverySource
+		// evm space group => discovery source
+		EthSources map[Group]DiscoverySource
+	}
+	Drain struct {
+		// grace period for in-flight requests and subscriptions to migrate before node drained
+		GracePeriod time.Duration `default:"5m"`
+		// period for pubsub subscriptions to finish on their own before evicted by RPC servers, which
+		// should be less than the grace period so that the evicted subscriptions are reported in time
+		SubscriptionGracePeriod time.Duration `default:"3m"`
+	}
+	Routing struct {
+		// group => routing strategy, e.g., `consistentHash` (default), `leastOutstanding` or `ewmaLatency`
+		Strategies map[string]string
+	}
 	Router struct {
 		RedisURL        string
 		NodeRPCURL      string
@@ -0,0 +1,351 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Conflux-Chain/confura/util/rpc"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DNS SRV records, e.g., `_rpc._tcp.fullnode.example.com`
+	DiscoveryTypeSRV = "srv"
+	// DNS A/AAAA records, e.g., `fullnode.example.com`
+	DiscoveryTypeA = "a"
+	// YAML/JSON file of group => node URLs
+	DiscoveryTypeFile = "file"
+)
+
+// DiscoverySource is the source to discover full nodes of a group.
+type DiscoverySource struct {
+	// source type, e.g., `srv`, `a` or `file`
+	Type string
+	// DNS name to lookup for `srv` or `a` source type
+	Name string
+	// URL scheme of discovered nodes for `srv` or `a` source type, defaults to `http`
+	Scheme string
+	// port of discovered nodes for `a` source type
+	Port int
+	// file path for `file` source type
+	File string
+}
+
+// discoverer discovers node URLs of a group from some source.
+type discoverer interface {
+	Discover(ctx context.Context) ([]string, error)
+}
+
+// notifier is implemented by discoverers that could notify source changes in time, so that group
+// nodes are reconciled at once instead of waiting for the next discovery interval.
+type notifier interface {
+	Notify() <-chan struct{}
+}
+
+func newDiscoverer(grp Group, src DiscoverySource) (discoverer, error) {
+	if len(src.Scheme) == 0 {
+		src.Scheme = "http"
+	}
+
+	switch strings.ToLower(src.Type) {
+	case DiscoveryTypeSRV:
+		if len(src.Name) == 0 {
+			return nil, errors.New("DNS name not specified")
+		}
+
+		return &srvDiscoverer{name: src.Name, scheme: src.Scheme}, nil
+	case DiscoveryTypeA:
+		if len(src.Name) == 0 || src.Port <= 0 {
+			return nil, errors.New("DNS name or port not specified")
+		}
+
+		return &aDiscoverer{name: src.Name, scheme: src.Scheme, port: src.Port}, nil
+	case DiscoveryTypeFile:
+		if len(src.File) == 0 {
+			return nil, errors.New("file path not specified")
+		}
+
+		return newFileDiscoverer(grp, src.File), nil
+	default:
+		return nil, errors.Errorf("unknown discovery type %v", src.Type)
+	}
+}
+
+// srvDiscoverer discovers nodes by DNS SRV records.
+type srvDiscoverer struct {
+	name   string
+	scheme string
+}
+
+func (d *srvDiscoverer) Discover(ctx context.Context) (urls []string, err error) {
+	_, addrs, err := net.DefaultResolver.LookupSRV(ctx, "", "", d.name)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to lookup SRV records")
+	}
+
+	for _, addr := range addrs {
+		host := strings.TrimSuffix(addr.Target, ".")
+		urls = append(urls, formatNodeUrl(d.scheme, host, int(addr.Port)))
+	}
+
+	return urls, nil
+}
+
+// aDiscoverer discovers nodes by DNS A/AAAA records.
+type aDiscoverer struct {
+	name   string
+	scheme string
+	port   int
+}
+
+func (d *aDiscoverer) Discover(ctx context.Context) (urls []string, err error) {
+	hosts, err := net.DefaultResolver.LookupHost(ctx, d.name)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to lookup host")
+	}
+
+	for _, host := range hosts {
+		urls = append(urls, formatNodeUrl(d.scheme, host, d.port))
+	}
+
+	return urls, nil
+}
+
+func formatNodeUrl(scheme, host string, port int) string {
+	return fmt.Sprintf("%v://%v", scheme, net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+// fileDiscoverer discovers nodes from a YAML/JSON file of group => node URLs. The file is watched to
+// notify changes, and only re-read when its modification time or size changed.
+type fileDiscoverer struct {
+	group    Group
+	path     string
+	notifyCh chan struct{}
+
+	modTime time.Time
+	size    int64
+	urls    []string // cached node URLs of the last read
+}
+
+func newFileDiscoverer(grp Group, path string) *fileDiscoverer {
+	d := &fileDiscoverer{
+		group:    grp,
+		path:     filepath.Clean(path),
+		notifyCh: make(chan struct{}, 1),
+	}
+
+	if err := d.watch(); err != nil {
+		logrus.WithField("file", path).WithError(err).Warn(
+			"Failed to watch node discovery file, changes will be detected periodically",
+		)
+	}
+
+	return d
+}
+
+// watch watches the parent directory of the file, so that the file replaced (e.g., by editors or
+// Kubernetes ConfigMap updates) is also detected.
+func (d *fileDiscoverer) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.WithMessage(err, "failed to create file watcher")
+	}
+
+	if err := watcher.Add(filepath.Dir(d.path)); err != nil {
+		watcher.Close()
+		return errors.WithMessage(err, "failed to watch directory")
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if filepath.Clean(event.Name) != d.path || event.Op == fsnotify.Chmod {
+					continue
+				}
+
+				select {
+				case d.notifyCh <- struct{}{}:
+				default: // already notified
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				logrus.WithField("file", d.path).WithError(err).Debug("Node discovery file watcher error")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Notify implements the notifier interface.
+func (d *fileDiscoverer) Notify() <-chan struct{} {
+	return d.notifyCh
+}
+
+func (d *fileDiscoverer) Discover(ctx context.Context) ([]string, error) {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to stat file")
+	}
+
+	// file not changed since the last read
+	if d.urls != nil && info.ModTime().Equal(d.modTime) && info.Size() == d.size {
+		return d.urls, nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to read file")
+	}
+
+	// JSON is a subset of YAML
+	var groupNodes map[Group][]string
+	if err := yaml.Unmarshal(data, &groupNodes); err != nil {
+		return nil, errors.WithMessage(err, "failed to unmarshal file")
+	}
+
+	d.modTime, d.size, d.urls = info.ModTime(), info.Size(), groupNodes[d.group]
+
+	return d.urls, nil
+}
+
+// discovery continuously reconciles group nodes against the discovery sources.
+type discovery struct {
+	h           *apiHandler
+	interval    time.Duration
+	persist     bool
+	discoverers map[Group]discoverer
+}
+
+func mustNewDiscovery(h *apiHandler, sources map[Group]DiscoverySource) *discovery {
+	d := &discovery{
+		h:           h,
+		interval:    cfg.Discovery.Interval,
+		persist:     cfg.Discovery.Persist,
+		discoverers: make(map[Group]discoverer),
+	}
+
+	for grp, src := range sources {
+		dr, err := newDiscoverer(grp, src)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"group":  grp,
+				"source": src,
+			}).WithError(err).Fatal("Failed to create node discoverer")
+		}
+
+		d.discoverers[grp] = dr
+	}
+
+	if d.persist && h.dbs == nil {
+		logrus.Fatal("DB not available to persist discovered nodes")
+	}
+
+	return d
+}
+
+func (d *discovery) run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	notifyCh := make(chan Group, len(d.discoverers))
+	for grp, dr := range d.discoverers {
+		if n, ok := dr.(notifier); ok {
+			go func(grp Group, ch <-chan struct{}) {
+				for range ch {
+					notifyCh <- grp
+				}
+			}(grp, n.Notify())
+		}
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			d.reconcileOnce()
+		case grp := <-notifyCh:
+			d.reconcileGroup(grp, d.discoverers[grp])
+		}
+	}
+}
+
+func (d *discovery) reconcileOnce() {
+	for grp, dr := range d.discoverers {
+		d.reconcileGroup(grp, dr)
+	}
+}
+
+func (d *discovery) reconcileGroup(grp Group, dr discoverer) {
+	logger := logrus.WithField("group", grp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.interval)
+	urls, err := dr.Discover(ctx)
+	cancel()
+
+	if err != nil {
+		logger.WithError(err).Error("Failed to discover group nodes")
+		return
+	}
+
+	// in case of the group nodes all removed by some misconfiguration
+	if len(urls) == 0 {
+		logger.Warn("No group nodes discovered, skip reconciliation")
+		return
+	}
+
+	added, removed, err := d.h.reconcileGroupNodes(grp, urls, d.persist)
+	if err != nil {
+		logger.WithError(err).Error("Failed to reconcile discovered group nodes")
+		return
+	}
+
+	if len(added) > 0 || len(removed) > 0 {
+		logger.WithFields(logrus.Fields{
+			"added":   added,
+			"removed": removed,
+		}).Info("Group nodes reconciled with discovery source")
+	}
+}
+
+// diffNodeUrls returns the added and removed node URLs compared by node name.
+func diffNodeUrls(current, desired []string) (added, removed []string) {
+	currentSet := make(map[string]bool)
+	for _, url := range current {
+		currentSet[rpc.Url2NodeName(url)] = true
+	}
+
+	desiredSet := make(map[string]bool)
+	for _, url := range dedupNodeUrls(desired) {
+		nn := rpc.Url2NodeName(url)
+		if !currentSet[nn] {
+			added = append(added, url)
+		}
+
+		desiredSet[nn] = true
+	}
+
+	for _, url := range current {
+		if !desiredSet[rpc.Url2NodeName(url)] {
+			removed = append(removed, url)
+		}
+	}
+
+	return added, removed
+}
@@ -0,0 +1,100 @@
+package node
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffNodeUrls(t *testing.T) {
+	current := []string{"http://127.0.0.1:12537", "http://127.0.0.2:12537"}
+	desired := []string{"http://127.0.0.2:12537", "http://127.0.0.3:12537", "https://127.0.0.3:12537"}
+
+	added, removed := diffNodeUrls(current, desired)
+	assert.Equal(t, []string{"http://127.0.0.3:12537"}, added)
+	assert.Equal(t, []string{"http://127.0.0.1:12537"}, removed)
+
+	added, removed = diffNodeUrls(current, current)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
+
+func TestFileDiscoverer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.yml")
+	content := "cfxhttp: [http://127.0.0.1:12537, http://127.0.0.2:12537]\nethhttp: [http://127.0.0.1:8545]\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	d, err := newDiscoverer(GroupCfxHttp, DiscoverySource{Type: "File", File: path})
+	assert.NoError(t, err)
+
+	urls, err := d.Discover(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://127.0.0.1:12537", "http://127.0.0.2:12537"}, urls)
+
+	// json format
+	assert.NoError(t, os.WriteFile(path, []byte(`{"cfxhttp": ["http://127.0.0.3:12537"]}`), 0644))
+
+	urls, err = d.Discover(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://127.0.0.3:12537"}, urls)
+}
+
+func TestFileDiscovererChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"cfxhttp": ["http://127.0.0.1:12537"]}`), 0644))
+
+	d := newFileDiscoverer(GroupCfxHttp, path)
+
+	urls, err := d.Discover(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://127.0.0.1:12537"}, urls)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+
+	// file changed and notified
+	assert.NoError(t, os.WriteFile(path, []byte(`{"cfxhttp": ["http://127.0.0.2:12537"]}`), 0644))
+
+	select {
+	case <-d.Notify():
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "file change not notified")
+	}
+
+	// not re-read if modification time and size unchanged
+	assert.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+	urls, err = d.Discover(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://127.0.0.1:12537"}, urls)
+
+	// re-read once modification time changed
+	modTime := info.ModTime().Add(time.Second)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	urls, err = d.Discover(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://127.0.0.2:12537"}, urls)
+}
+
+func TestReconcileGroupNodes(t *testing.T) {
+	MustInit()
+
+	h := &apiHandler{pool: newNodePool(func(group Group, name, url string) (Node, error) {
+		return newDummyNode(group, name, url)
+	})}
+
+	added, removed, err := h.reconcileGroupNodes(GroupCfxHttp, testGroupNodeUrls[GroupCfxHttp], false)
+	assert.NoError(t, err)
+	assert.Equal(t, testGroupNodeUrls[GroupCfxHttp], added)
+	assert.Empty(t, removed)
+
+	urls := []string{"http://127.0.0.1:25374", "http://127.0.0.1:25376"}
+	added, removed, err = h.reconcileGroupNodes(GroupCfxHttp, urls, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://127.0.0.1:25376"}, added)
+	assert.ElementsMatch(t, []string{"http://127.0.0.1:25373", "http://127.0.0.1:25375"}, removed)
+	assert.ElementsMatch(t, urls, h.pool.get(GroupCfxHttp))
+}
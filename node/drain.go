@@ -0,0 +1,289 @@
+package node
+
+import (
+	"time"
+
+	"github.com/Conflux-Chain/confura/util/rpc"
+)
+
+const (
+	// drain reports of RPC servers expire if not refreshed in time, e.g., RPC server stopped
+	drainReportTTL = time.Minute
+)
+
+// SubscriptionGracePeriod returns the period for pubsub subscriptions to finish on their own before
+// evicted from draining nodes.
+func SubscriptionGracePeriod() time.Duration {
+	return cfg.Drain.SubscriptionGracePeriod
+}
+
+// DrainProvider provides the full node URLs that are being drained.
+type DrainProvider interface {
+	ListDrainingNodes() (urls []string)
+}
+
+// DrainReporter reports the drain counts of draining full nodes to node manager.
+type DrainReporter interface {
+	// ReportDrain reports drain counts (keyed by node URL) of all the draining nodes known by
+	// the reporter, e.g., RPC server, which is expected to report periodically.
+	ReportDrain(reporter string, counts map[string]DrainCounts) error
+}
+
+// locateRouter finds the router of specific type from the router chain.
+func locateRouter[T any](r Router) (T, bool) {
+	if t, ok := r.(T); ok {
+		return t, true
+	}
+
+	if cr, ok := r.(*chainedRouter); ok {
+		for _, r := range cr.routers {
+			if t, ok := locateRouter[T](r); ok {
+				return t, true
+			}
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// ListDrainingNodes returns the URL list of draining nodes if supported by router.
+func (p *clientProvider) ListDrainingNodes() []string {
+	if dp, ok := locateRouter[DrainProvider](p.router); ok {
+		return dp.ListDrainingNodes()
+	}
+
+	return nil
+}
+
+// ReportDrain reports the drain counts of draining nodes if supported by router.
+func (p *clientProvider) ReportDrain(reporter string, counts map[string]DrainCounts) error {
+	if dr, ok := locateRouter[DrainReporter](p.router); ok {
+		return dr.ReportDrain(reporter, counts)
+	}
+
+	return nil
+}
+
+// DrainCounts is the number of in-flight requests and delegated pubsub subscriptions on a draining
+// full node.
+type DrainCounts struct {
+	InFlight      int64 `json:"inFlight"`
+	Subscriptions int64 `json:"subscriptions"`
+}
+
+// drainReport is the latest drain report of a reporter.
+type drainReport struct {
+	reportedAt time.Time
+	counts     map[string]DrainCounts // node name => drain counts
+}
+
+// drainProgress is the drain counts of a node aggregated from all active reporters.
+type drainProgress struct {
+	DrainCounts
+	reported  int // number of reporters that reported since drain started
+	reporters int // number of active reporters
+}
+
+// DrainStatus is the drain progress of a full node.
+type DrainStatus struct {
+	// when full node started to drain
+	Since time.Time `json:"since"`
+	// in-flight requests and delegated subscriptions reported by RPC servers
+	DrainCounts
+	// number of RPC servers reported since drain started, against all the active ones
+	Reported  int `json:"reported"`
+	Reporters int `json:"reporters"`
+	// whether full node is drained, and could be removed or upgraded safely
+	Drained bool `json:"drained"`
+	// whether drained due to the grace period elapsed rather than all RPC servers reported idle
+	TimedOut bool `json:"timedOut,omitempty"`
+}
+
+func newDrainStatus(since time.Time, progress *drainProgress) *DrainStatus {
+	status := &DrainStatus{Since: since}
+	if progress != nil {
+		status.DrainCounts = progress.DrainCounts
+		status.Reported, status.Reporters = progress.reported, progress.reporters
+	}
+
+	// drained once all active RPC servers reported neither in-flight requests nor subscriptions
+	status.Drained = status.Reporters > 0 && status.Reported == status.Reporters &&
+		status.InFlight == 0 && status.Subscriptions == 0
+
+	// otherwise, drained anyway after the grace period
+	if !status.Drained && time.Since(since) >= cfg.Drain.GracePeriod {
+		status.Drained, status.TimedOut = true, true
+	}
+
+	return status
+}
+
+// Drain stops routing new requests to the managed full node, which is still monitored.
+func (m *Manager) Drain(nodeName string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.nodes[nodeName]; !ok {
+		return false
+	}
+
+	status := m.monitorStatuses[nodeName]
+	status.draining = true
+	m.monitorStatuses[nodeName] = status
+
+	m.hashRing.Remove(nodeName)
+
+	return true
+}
+
+// Undrain resumes routing new requests to the managed full node if healthy.
+func (m *Manager) Undrain(nodeName string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return false
+	}
+
+	status := m.monitorStatuses[nodeName]
+	status.draining = false
+	m.monitorStatuses[nodeName] = status
+
+	if !status.unhealthy {
+		m.hashRing.Add(n)
+	}
+
+	return true
+}
+
+// IsDraining checks whether the managed full node is being drained.
+func (m *Manager) IsDraining(nodeName string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.monitorStatuses[nodeName].draining
+}
+
+// drain drains the node in all groups, and returns false if node not found in the pool. Note, the
+// drain state is kept even if node not found, so that node is drained once added into the pool.
+func (p *nodePool) drain(url string, since time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	nn := rpc.Url2NodeName(url)
+	p.drains[nn] = since
+
+	rn, ok := p.nodes[nn]
+	if !ok {
+		return false
+	}
+
+	for _, m := range p.managers {
+		m.Drain(nn)
+	}
+
+	status := rn.Status()
+	status.setDrainingSince(since)
+
+	p.refreshDrainProgress(time.Now())
+
+	return true
+}
+
+// undrain undrains the node in all groups, and returns false if node not drained.
+func (p *nodePool) undrain(url string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	nn := rpc.Url2NodeName(url)
+	if _, ok := p.drains[nn]; !ok {
+		return false
+	}
+
+	delete(p.drains, nn)
+
+	if rn, ok := p.nodes[nn]; ok {
+		for _, m := range p.managers {
+			m.Undrain(nn)
+		}
+
+		status := rn.Status()
+		status.setDrainingSince(time.Time{})
+	}
+
+	return true
+}
+
+// draining returns the url of all draining nodes in the pool.
+func (p *nodePool) draining() (urls []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for nn := range p.drains {
+		if rn, ok := p.nodes[nn]; ok {
+			urls = append(urls, rn.Url())
+		}
+	}
+
+	return urls
+}
+
+// contains checks whether the node exists in the pool.
+func (p *nodePool) contains(url string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, ok := p.nodes[rpc.Url2NodeName(url)]
+	return ok
+}
+
+// reportDrain records the drain counts (keyed by node URL) reported by RPC server, and refreshes
+// the drain progress of all draining nodes.
+func (p *nodePool) reportDrain(reporter string, counts map[string]DrainCounts, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	report := drainReport{reportedAt: now, counts: make(map[string]DrainCounts, len(counts))}
+	for url, c := range counts {
+		report.counts[rpc.Url2NodeName(url)] = c
+	}
+
+	p.reports[reporter] = report
+	p.refreshDrainProgress(now)
+}
+
+// refreshDrainProgress aggregates the drain counts of all draining nodes from active reporters.
+//
+// Note, a draining node is reported only if the reporter is aware of the drain, so that node will
+// not be regarded as drained before all RPC servers stopped routing new requests to it.
+func (p *nodePool) refreshDrainProgress(now time.Time) {
+	for reporter, report := range p.reports {
+		if now.Sub(report.reportedAt) > drainReportTTL {
+			delete(p.reports, reporter)
+		}
+	}
+
+	for nn, since := range p.drains {
+		rn, ok := p.nodes[nn]
+		if !ok {
+			continue
+		}
+
+		progress := drainProgress{reporters: len(p.reports)}
+		for _, report := range p.reports {
+			c, ok := report.counts[nn]
+			if !ok || report.reportedAt.Before(since) {
+				continue
+			}
+
+			progress.reported++
+			progress.InFlight += c.InFlight
+			progress.Subscriptions += c.Subscriptions
+		}
+
+		status := rn.Status()
+		status.setDrainProgress(&progress)
+	}
+}
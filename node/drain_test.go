@@ -0,0 +1,131 @@
+package node
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodePoolDrain(t *testing.T) {
+	MustInit()
+
+	pool := newNodePool(func(group Group, name, url string) (Node, error) {
+		return newDummyNode(group, name, url)
+	})
+
+	urls := testGroupNodeUrls[GroupCfxHttp]
+	assert.NoError(t, pool.add(GroupCfxHttp, urls...))
+
+	m, _ := pool.manager(GroupCfxHttp)
+	drainedUrl := urls[0]
+
+	assert.False(t, pool.drain("http://127.0.0.1:12345", time.Now()))
+	assert.True(t, pool.undrain("http://127.0.0.1:12345"))
+
+	assert.True(t, pool.drain(drainedUrl, time.Now().Add(-cfg.Drain.GracePeriod)))
+	assert.Equal(t, []string{drainedUrl}, pool.draining())
+
+	// never routed to the draining node
+	for i := 0; i < 100; i++ {
+		assert.NotEqual(t, drainedUrl, m.Route([]byte(strconv.Itoa(i))))
+	}
+
+	// still managed and drained
+	n, ok := m.Get(drainedUrl[len("http://"):])
+	assert.True(t, ok)
+	status := n.Status()
+	assert.True(t, status.DrainStatus().Drained)
+
+	// kept draining if added into another group
+	assert.NoError(t, pool.add(GroupCfxLogs, drainedUrl))
+	logsManager, _ := pool.manager(GroupCfxLogs)
+	assert.True(t, logsManager.IsDraining(n.Name()))
+	assert.Empty(t, logsManager.Route([]byte("key")))
+
+	// routed again once undrained
+	assert.True(t, pool.undrain(drainedUrl))
+	assert.Empty(t, pool.draining())
+	assert.Nil(t, status.DrainStatus())
+	assert.Equal(t, drainedUrl, logsManager.Route([]byte("key")))
+}
+
+func TestNodePoolDrainReport(t *testing.T) {
+	MustInit()
+
+	pool := newNodePool(func(group Group, name, url string) (Node, error) {
+		return newDummyNode(group, name, url)
+	})
+
+	urls := testGroupNodeUrls[GroupCfxHttp]
+	assert.NoError(t, pool.add(GroupCfxHttp, urls...))
+
+	m, _ := pool.manager(GroupCfxHttp)
+	drainedUrl := urls[0]
+	n, _ := m.Get(drainedUrl[len("http://"):])
+	status := n.Status()
+
+	now := time.Now()
+	pool.reportDrain("rpc1", nil, now.Add(-time.Second))
+	assert.True(t, pool.drain(drainedUrl, now))
+
+	// not drained until all RPC servers reported since drain started
+	assert.False(t, status.DrainStatus().Drained)
+
+	pool.reportDrain("rpc2", map[string]DrainCounts{drainedUrl: {}}, now.Add(time.Second))
+	drainStatus := status.DrainStatus()
+	assert.False(t, drainStatus.Drained)
+	assert.Equal(t, 1, drainStatus.Reported)
+	assert.Equal(t, 2, drainStatus.Reporters)
+
+	// not drained if any in-flight requests or subscriptions
+	pool.reportDrain("rpc1", map[string]DrainCounts{drainedUrl: {InFlight: 2, Subscriptions: 1}}, now.Add(time.Second))
+	drainStatus = status.DrainStatus()
+	assert.False(t, drainStatus.Drained)
+	assert.Equal(t, DrainCounts{InFlight: 2, Subscriptions: 1}, drainStatus.DrainCounts)
+
+	// drained once all RPC servers reported idle
+	pool.reportDrain("rpc1", map[string]DrainCounts{drainedUrl: {}}, now.Add(2*time.Second))
+	drainStatus = status.DrainStatus()
+	assert.True(t, drainStatus.Drained)
+	assert.False(t, drainStatus.TimedOut)
+
+	// stale reporters are excluded
+	pool.reportDrain("rpc3", nil, now.Add(3*time.Second))
+	assert.False(t, status.DrainStatus().Drained)
+	pool.reportDrain("rpc1", map[string]DrainCounts{drainedUrl: {}}, now.Add(drainReportTTL+4*time.Second))
+	pool.reportDrain("rpc2", map[string]DrainCounts{drainedUrl: {}}, now.Add(drainReportTTL+4*time.Second))
+	drainStatus = status.DrainStatus()
+	assert.True(t, drainStatus.Drained)
+	assert.Equal(t, 2, drainStatus.Reporters)
+
+	// drained anyway after the grace period
+	assert.True(t, pool.undrain(drainedUrl))
+	assert.True(t, pool.drain(drainedUrl, now.Add(-cfg.Drain.GracePeriod)))
+	pool.reportDrain("rpc1", map[string]DrainCounts{drainedUrl: {InFlight: 1}}, now)
+	drainStatus = status.DrainStatus()
+	assert.True(t, drainStatus.Drained)
+	assert.True(t, drainStatus.TimedOut)
+}
+
+func TestManagerReportHealthyDraining(t *testing.T) {
+	MustInit()
+
+	pool := newNodePool(func(group Group, name, url string) (Node, error) {
+		return newDummyNode(group, name, url)
+	})
+
+	urls := testGroupNodeUrls[GroupCfxHttp]
+	assert.NoError(t, pool.add(GroupCfxHttp, urls...))
+
+	m, _ := pool.manager(GroupCfxHttp)
+	drainedUrl := urls[0]
+	assert.True(t, pool.drain(drainedUrl, time.Now()))
+
+	// draining node never added into hash ring again once healthy
+	m.ReportHealthy(drainedUrl[len("http://"):])
+	for i := 0; i < 100; i++ {
+		assert.NotEqual(t, drainedUrl, m.Route([]byte(strconv.Itoa(i))))
+	}
+}
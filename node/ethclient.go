@@ -8,6 +8,8 @@ import (
 	"github.com/Conflux-Chain/confura/store/mysql"
 	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
 	"github.com/openweb3/web3go"
+	"github.com/openweb3/web3go/types"
+	"github.com/pkg/errors"
 )
 
 type Web3goClient struct {
@@ -25,8 +27,13 @@ type EthClientProvider struct {
 	*clientProvider
 }
 
-func newEthClient(url string) (interface{}, error) {
-	client, err := rpcutil.NewEthClient(url, rpcutil.WithClientHookMetrics(true), rpcutil.WithClientHookCache(true))
+func newEthClient(url string, group Group) (interface{}, error) {
+	client, err := rpcutil.NewEthClient(
+		url,
+		rpcutil.WithClientHookMetrics(true),
+		rpcutil.WithClientHookCache(true),
+		rpcutil.WithClientHookCoalesce(string(group)),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -52,10 +59,23 @@ func (p *EthClientProvider) GetClient(key string, groups ...Group) (*Web3goClien
 	return client.(*Web3goClient), nil
 }
 
-// GetClientByIP gets client of specific group (or use normal HTTP group as default) by remote IP address.
+// GetClientWithHint gets client of specific group (or use normal HTTP group as default), which prefers
+// full nodes that have reached the hinted block height.
+func (p *EthClientProvider) GetClientWithHint(key string, hint RouteHint, groups ...Group) (*Web3goClient, error) {
+	client, err := p.getClient(key, ethNodeGroup(groups...), hint)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.(*Web3goClient), nil
+}
+
+// GetClientByIP gets client of specific group (or use normal HTTP group as default) by remote IP address,
+// which prefers full nodes that have reached the block height hinted in context if any.
 func (p *EthClientProvider) GetClientByIP(ctx context.Context, groups ...Group) (*Web3goClient, error) {
 	remoteAddr := remoteAddrFromContext(ctx)
-	client, err := p.getClient(remoteAddr, ethNodeGroup(groups...))
+	hint, _ := RouteHintFromContext(ctx)
+	client, err := p.getClient(remoteAddr, ethNodeGroup(groups...), hint)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +102,25 @@ func (p *EthClientProvider) GetClientsByGroup(grp Group) (clients []*Web3goClien
 	return clients, nil
 }
 
+// FinalizedEpoch returns the latest finalized block number from a random full node.
+func (p *EthClientProvider) FinalizedEpoch() (uint64, error) {
+	client, err := p.GetClient(fmt.Sprintf("random_key_%v", rand.Int()))
+	if err != nil {
+		return 0, err
+	}
+
+	block, err := client.Eth.BlockByNumber(types.FinalizedBlockNumber, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if block == nil || block.Number == nil {
+		return 0, errors.New("finalized block not found")
+	}
+
+	return block.Number.Uint64(), nil
+}
+
 func (p *EthClientProvider) GetClientRandom() (*Web3goClient, error) {
 	key := fmt.Sprintf("random_key_%v", rand.Int())
 	client, err := p.getClient(key, GroupEthHttp)
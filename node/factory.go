@@ -22,7 +22,7 @@ func Factory() *factory {
 			func(group Group, name, url string) (Node, error) {
 				return NewCfxNode(group, name, url)
 			},
-			cfg.Endpoint, urlCfg, cfg.Router.NodeRPCURL,
+			cfg.Endpoint, urlCfg, cfg.Router.NodeRPCURL, cfg.Discovery.Sources,
 		)
 	})
 
@@ -36,7 +36,7 @@ func EthFactory() *factory {
 			func(group Group, name, url string) (Node, error) {
 				return NewEthNode(group, name, url)
 			},
-			cfg.EthEndpoint, ethUrlCfg, cfg.Router.EthNodeRPCURL,
+			cfg.EthEndpoint, ethUrlCfg, cfg.Router.EthNodeRPCURL, cfg.Discovery.EthSources,
 		)
 	})
 
@@ -49,20 +49,25 @@ type factory struct {
 	rpcSrvEndpoint string
 	groupConf      map[Group]UrlConfig
 	nodeFactory    nodeFactory
+	discoveries    map[Group]DiscoverySource
 }
 
-func newFactory(nf nodeFactory, rpcSrvEndpoint string, groupConf map[Group]UrlConfig, nodeRpcUrl string) *factory {
+func newFactory(
+	nf nodeFactory, rpcSrvEndpoint string, groupConf map[Group]UrlConfig, nodeRpcUrl string,
+	discoveries map[Group]DiscoverySource,
+) *factory {
 	return &factory{
 		nodeRpcUrl:     nodeRpcUrl,
 		nodeFactory:    nf,
 		rpcSrvEndpoint: rpcSrvEndpoint,
 		groupConf:      groupConf,
+		discoveries:    discoveries,
 	}
 }
 
 // CreatRpcServer creates node manager RPC server
 func (f *factory) CreatRpcServer(db *mysql.MysqlStore) (*rpc.Server, string) {
-	return MustNewServer(db, f.nodeFactory, f.groupConf), f.rpcSrvEndpoint
+	return MustNewServer(db, f.nodeFactory, f.groupConf, f.discoveries), f.rpcSrvEndpoint
 }
 
 // CreateRouter creates node router
@@ -1,6 +1,7 @@
 package node
 
 import (
+	"slices"
 	"strings"
 	"sync"
 
@@ -15,12 +16,13 @@ type nodeFactory func(group Group, name, url string) (Node, error)
 // Manager manages full node cluster, including:
 // 1. Monitor node health and disable/enable full node automatically.
 // 2. Implements Router interface to route RPC requests to different full nodes
-// in manner of consistent hashing.
+// in manner of consistent hashing or the configured routing strategy.
 type Manager struct {
 	group    Group
 	nodes    map[string]Node        // node name => Node
-	hashRing *consistent.Consistent // consistent hashing algorithm
+	hashRing *consistent.Consistent // consistent hashing algorithm, which only contains healthy nodes
 	resolver RepartitionResolver    // support repartition for hash ring
+	strategy routingStrategy        // nil for consistent hashing
 	mu       sync.RWMutex
 
 	// health monitor
@@ -39,6 +41,7 @@ func NewManagerWithRepartition(group Group, resolver RepartitionResolver) *Manag
 		resolver:        resolver,
 		monitorStatuses: make(map[string]monitorStatus),
 		hashRing:        consistent.New(nil, cfg.HashRingRaw()),
+		strategy:        newManagerRoutingStrategy(group, cfg.Routing.Strategies[string(group)]),
 	}
 }
 
@@ -107,13 +110,18 @@ func (m *Manager) String() string {
 	return strings.Join(nodes, ", ")
 }
 
-// Distribute distributes a full node by specified key.
+// Distribute distributes a full node by specified key, which is ignored by the load-aware
+// routing strategies.
 func (m *Manager) Distribute(key []byte) Node {
-	k := xxhash.Sum64(key)
-
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	if m.strategy != nil {
+		return m.selectHealthyNode()
+	}
+
+	k := xxhash.Sum64(key)
+
 	// Use repartition resolver to distribute if configured.
 	if name, ok := m.resolver.Get(k); ok {
 		return m.nodes[name]
@@ -130,9 +138,86 @@ func (m *Manager) Distribute(key []byte) Node {
 	return node
 }
 
+// selectHealthyNode selects a node among the healthy ones in hash ring by the routing strategy.
+func (m *Manager) selectHealthyNode() Node {
+	members := m.hashRing.GetMembers()
+	if len(members) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(members))
+	for i, member := range members {
+		names[i] = member.(Node).Name()
+	}
+
+	return members[m.strategy.Select(names)].(Node)
+}
+
+// DistributeWithHint distributes a full node by specified key, and prefers the healthy full nodes
+// that have reached the hinted block height with the hinted capabilities. If no full node qualified,
+// the distributed one by key will be returned as best effort.
+func (m *Manager) DistributeWithHint(key []byte, hint RouteHint) Node {
+	n := m.Distribute(key)
+	if n == nil || hint.IsEmpty() {
+		return n
+	}
+
+	minHeight := hint.MinHeight
+	if hint.Synced {
+		minHeight = max(minHeight, m.HealthyEpoch())
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.qualified(n, hint, minHeight) {
+		return n
+	}
+
+	var candidates []Node
+	for _, member := range m.hashRing.GetMembers() {
+		if node := member.(Node); m.qualified(node, hint, minHeight) {
+			candidates = append(candidates, node)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return n
+	}
+
+	// sort by name so that the same key always goes to the same node
+	slices.SortFunc(candidates, func(a, b Node) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return candidates[xxhash.Sum64(key)%uint64(len(candidates))]
+}
+
+// qualified checks whether the full node has reached the min height with the hinted capabilities.
+func (m *Manager) qualified(n Node, hint RouteHint, minHeight uint64) bool {
+	epoch := m.monitorStatuses[n.Name()].epoch
+	if epoch < minHeight {
+		return false
+	}
+
+	if !hint.requiresCapability() {
+		return true
+	}
+
+	status := n.Status()
+	c := status.Capabilities()
+
+	return c.HasNamespace(hint.Namespace) && c.HasStateAt(epoch, hint.StateEpoch)
+}
+
 // Route implements the Router interface.
 func (m *Manager) Route(key []byte) string {
-	if n := m.Distribute(key); n != nil {
+	return m.RouteWithHint(key, RouteHint{})
+}
+
+// RouteWithHint routes the key to full node that prefers to have reached the hinted block height.
+func (m *Manager) RouteWithHint(key []byte, hint RouteHint) string {
+	if n := m.DistributeWithHint(key, hint); n != nil {
 		// metrics overall route QPS
 		metrics.Registry.Nodes.Routes(m.group.Space(), m.group.String(), "overall").Mark(1)
 		// metrics per node route QPS
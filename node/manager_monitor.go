@@ -13,6 +13,7 @@ type monitorStatus struct {
 	epoch            uint64    // the latest epoch height
 	unhealthy        bool      // whether the node is unhealthy
 	unhealthReportAt time.Time // the last unhealthy report time
+	draining         bool      // whether the node is being drained
 }
 
 // Implementations for HealthMonitor interface.
@@ -105,8 +106,17 @@ func (m *Manager) ReportHealthy(nodeName string) {
 	// alert
 	logrus.WithField("node", nodeName).Warn("Node became healthy now")
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// draining node will be added into hash ring again once undrained, which is checked under the
+	// same lock so as not to race with drain.
+	if m.monitorStatuses[nodeName].draining {
+		return
+	}
+
 	// add recovered node into hash ring again
-	if n, ok := m.Get(nodeName); ok {
+	if n, ok := m.nodes[nodeName]; ok {
 		m.hashRing.Add(n)
 	} else { // this should not happen, but just in case
 		logrus.WithField("node", nodeName).Error("Node not found in manager")
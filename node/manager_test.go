@@ -0,0 +1,56 @@
+package node
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerDistributeWithHint(t *testing.T) {
+	MustInit()
+
+	m := NewManager(GroupEthHttp)
+
+	epochs := map[string]uint64{"node1": 100, "node2": 101, "node3": 103}
+	for name, epoch := range epochs {
+		n, _ := newDummyNode(GroupEthHttp, name, "http://"+name)
+		m.Add(n)
+		m.ReportEpoch(name, epoch)
+	}
+
+	for i := 0; i < 100; i++ {
+		key := []byte(strconv.Itoa(i))
+
+		// sticky to the distributed node by key if no hint
+		assert.Equal(t, m.Distribute(key), m.DistributeWithHint(key, RouteHint{}))
+
+		n := m.DistributeWithHint(key, RouteHint{MinHeight: 102})
+		assert.Equal(t, "node3", n.Name())
+
+		n = m.DistributeWithHint(key, RouteHint{Synced: true})
+		assert.GreaterOrEqual(t, epochs[n.Name()], m.HealthyEpoch())
+
+		// best effort if no node reached
+		assert.Equal(t, m.Distribute(key), m.DistributeWithHint(key, RouteHint{MinHeight: 200}))
+	}
+}
+
+func TestManagerRouteLeastOutstanding(t *testing.T) {
+	MustInit()
+
+	cfg.Routing.Strategies = map[string]string{string(GroupCfxHttp): RoutingLeastOutstanding}
+	defer func() { cfg.Routing.Strategies = nil }()
+
+	m := NewManager(GroupCfxHttp)
+	for _, url := range testGroupNodeUrls[GroupCfxHttp] {
+		n, _ := newDummyNode(GroupCfxHttp, url[len("http://"):], url)
+		m.Add(n)
+	}
+
+	// outstanding requests are unknown to node manager, so routed by consistent hashing
+	for i := 0; i < 100; i++ {
+		key := []byte(strconv.Itoa(i))
+		assert.Equal(t, m.hashRing.LocateKey(key).(Node).Url(), m.Route(key))
+	}
+}
@@ -140,7 +140,7 @@ type EthNode struct {
 }
 
 // NewEthNode creates an instance of evm space node and start to monitor
-// node health in a separate goroutine until node closed.
+// node health and probe node capabilities in separate goroutines until node closed.
 func NewEthNode(group Group, name, url string) (*EthNode, error) {
 	eth, err := rpc.NewEthClient(url)
 	if err != nil {
@@ -156,6 +156,7 @@ func NewEthNode(group Group, name, url string) (*EthNode, error) {
 	n.atomicStatus.Store(NewStatus(group, name))
 
 	go n.monitor(ctx, n)
+	go n.probe(ctx, newEthCapabilityProber(n))
 
 	return n, nil
 }
@@ -182,7 +183,7 @@ type CfxNode struct {
 }
 
 // NewCfxNode creates an instance of core space fullnode and start to monitor
-// node health in a separate goroutine until node closed.
+// node health and probe node capabilities in separate goroutines until node closed.
 func NewCfxNode(group Group, name, url string) (*CfxNode, error) {
 	cfx, err := rpc.NewCfxClient(url)
 	if err != nil {
@@ -198,6 +199,7 @@ func NewCfxNode(group Group, name, url string) (*CfxNode, error) {
 	n.atomicStatus.Store(NewStatus(group, name))
 
 	go n.monitor(ctx, n)
+	go n.probe(ctx, newCfxCapabilityProber(n))
 
 	return n, nil
 }
@@ -3,6 +3,7 @@ package node
 import (
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/Conflux-Chain/confura/util/metrics"
@@ -43,6 +44,13 @@ type Status struct {
 	failureCounter   uint64
 
 	latestHeartBeatErrs *ring.Ring
+
+	// probed capabilities shared among status copies
+	capabilities *atomic.Pointer[Capabilities]
+	// drain start time shared among status copies, nil if not draining
+	drainingSince *atomic.Pointer[time.Time]
+	// drain counts aggregated from RPC servers shared among status copies, nil if not reported
+	drainProgress *atomic.Pointer[drainProgress]
 }
 
 func NewStatus(group Group, nodeName string) Status {
@@ -56,9 +64,48 @@ func NewStatus(group Group, nodeName string) Status {
 			metrics.Registry.Nodes.NodeAvailability(group.Space(), group.String(), nodeName),
 		),
 		latestHeartBeatErrs: hbErrRingBuf,
+		capabilities:        &atomic.Pointer[Capabilities]{},
+		drainingSince:       &atomic.Pointer[time.Time]{},
+		drainProgress:       &atomic.Pointer[drainProgress]{},
 	}
 }
 
+// Capabilities returns the probed capabilities of node, or empty capabilities if not probed yet.
+func (s *Status) Capabilities() Capabilities {
+	if c := s.capabilities.Load(); c != nil {
+		return *c
+	}
+
+	return Capabilities{}
+}
+
+func (s *Status) setCapabilities(c Capabilities) {
+	s.capabilities.Store(&c)
+}
+
+// DrainStatus returns the drain progress of node, or nil if not draining.
+func (s *Status) DrainStatus() *DrainStatus {
+	if since := s.drainingSince.Load(); since != nil {
+		return newDrainStatus(*since, s.drainProgress.Load())
+	}
+
+	return nil
+}
+
+// setDrainingSince sets the drain start time, or zero time if undrained.
+func (s *Status) setDrainingSince(since time.Time) {
+	if since.IsZero() {
+		s.drainingSince.Store(nil)
+		s.drainProgress.Store(nil)
+	} else {
+		s.drainingSince.Store(&since)
+	}
+}
+
+func (s *Status) setDrainProgress(progress *drainProgress) {
+	s.drainProgress.Store(progress)
+}
+
 // Update heartbeats with node and updates health status.
 func (s *Status) Update(n Node) {
 	s.heartbeat(n)
@@ -85,6 +132,9 @@ func (s *Status) MarshalJSON() ([]byte, error) {
 		FailureCounter   uint64 `json:"failureCounter"`
 
 		LatestHeartBeatErrs []string `json:"latestHeartBeatErrs"`
+
+		Capabilities *Capabilities `json:"capabilities,omitempty"`
+		Drain        *DrainStatus  `json:"drain,omitempty"`
 	}
 
 	availability := metricUtil.GetOrRegisterTimeWindowPercentageDefault(0, s.metric.availability).Snapshot().Value()
@@ -99,6 +149,8 @@ func (s *Status) MarshalJSON() ([]byte, error) {
 		LatestStateEpoch: s.latestStateEpoch,
 		SuccessCounter:   s.successCounter,
 		FailureCounter:   s.failureCounter,
+		Capabilities:     s.capabilities.Load(),
+		Drain:            s.DrainStatus(),
 	}
 
 	hbErrors := s.latestHeartBeatErrs.Values()
@@ -2,6 +2,7 @@ package node
 
 import (
 	"sync"
+	"time"
 
 	"github.com/Conflux-Chain/confura/util/rpc"
 	"github.com/pkg/errors"
@@ -25,6 +26,12 @@ type nodePool struct {
 	// all managed nodes:
 	// node name => refNode
 	nodes map[string]refNode
+	// draining nodes:
+	// node name => drain started at
+	drains map[string]time.Time
+	// latest drain reports of RPC servers:
+	// reporter => drain report
+	reports map[string]drainReport
 }
 
 func newNodePool(nf nodeFactory) *nodePool {
@@ -32,6 +39,8 @@ func newNodePool(nf nodeFactory) *nodePool {
 		nf:       nf,
 		managers: make(map[Group]*Manager),
 		nodes:    make(map[string]refNode),
+		drains:   make(map[string]time.Time),
+		reports:  make(map[string]drainReport),
 	}
 }
 
@@ -75,6 +84,14 @@ func (p *nodePool) add(grp Group, urls ...string) error {
 		m.Add(rn)
 		rn.Register(m)
 
+		// keep draining if node added into another group
+		if since, ok := p.drains[rn.Name()]; ok {
+			m.Drain(rn.Name())
+
+			status := rn.Status()
+			status.setDrainingSince(since)
+		}
+
 		// reference the shared node
 		rn.refCnt++
 		p.nodes[rn.Name()] = rn
@@ -33,13 +33,18 @@ type dummyNode struct {
 }
 
 func newDummyNode(group Group, name, url string) (*dummyNode, error) {
-	return &dummyNode{newBaseNode(name, url, nil)}, nil
+	n := &dummyNode{newBaseNode(name, url, nil)}
+	n.atomicStatus.Store(NewStatus(group, name))
+
+	return n, nil
 }
 
 func (n *dummyNode) LatestEpochNumber() (uint64, error) {
 	panic("not supported")
 }
 
+func (n *dummyNode) Close() {}
+
 func BenchmarkNodePoolRoute(b *testing.B) {
 	MustInit()
 
@@ -0,0 +1,61 @@
+package node
+
+import (
+	"context"
+
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+)
+
+const (
+	ctxKeyRouteHint = handlers.CtxKey("Infura-Route-Hint")
+)
+
+// RouteHint hints the router to route RPC requests to full nodes that have reached some block height,
+// so that clients never see the chain go backwards when routed to different full nodes. Besides, it
+// also hints the capabilities that full nodes shall have, e.g., archive state or `debug` namespace.
+type RouteHint struct {
+	// min epoch (or block) number that full nodes shall have reached
+	MinHeight uint64 `json:"minHeight,omitempty"`
+	// whether full nodes shall have reached the healthy epoch of group, e.g., to query by block hash
+	Synced bool `json:"synced,omitempty"`
+	// optional RPC namespace that full nodes shall support, e.g., `trace`, `debug` or `txpool`
+	Namespace string `json:"namespace,omitempty"`
+	// epoch (or block) number whose state is queried, which may require archive full nodes
+	StateEpoch uint64 `json:"stateEpoch,omitempty"`
+}
+
+// IsEmpty returns whether no height or capability requirement hinted.
+func (h RouteHint) IsEmpty() bool {
+	return h.MinHeight == 0 && !h.Synced && !h.requiresCapability()
+}
+
+func (h RouteHint) requiresCapability() bool {
+	return len(h.Namespace) > 0 || h.StateEpoch > 0
+}
+
+// HeightAwareRouter is implemented by routers that are aware of the latest epoch of full nodes.
+type HeightAwareRouter interface {
+	// RouteWithHint returns the full node URL for specified group and key, which prefers full nodes
+	// that have reached the hinted block height.
+	RouteWithHint(group Group, key []byte, hint RouteHint) string
+}
+
+// NewContextWithRouteHint returns a new context with the route hint.
+func NewContextWithRouteHint(ctx context.Context, hint RouteHint) context.Context {
+	return context.WithValue(ctx, ctxKeyRouteHint, hint)
+}
+
+// RouteHintFromContext returns the route hint from context if any.
+func RouteHintFromContext(ctx context.Context) (RouteHint, bool) {
+	hint, ok := ctx.Value(ctxKeyRouteHint).(RouteHint)
+	return hint, ok
+}
+
+// routeWithHint routes by the route hint if supported by router.
+func routeWithHint(r Router, group Group, key []byte, hint RouteHint) string {
+	if har, ok := r.(HeightAwareRouter); ok && !hint.IsEmpty() {
+		return har.RouteWithHint(group, key, hint)
+	}
+
+	return r.Route(group, key)
+}
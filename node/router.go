@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -123,8 +124,34 @@ func NewChainedRouter(groupConf map[Group]UrlConfig, routers ...Router) Router {
 }
 
 func (r *chainedRouter) Route(group Group, key []byte) string {
-	for _, r := range r.routers {
-		if val := r.Route(group, key); len(val) > 0 {
+	return r.RouteWithHint(group, key, RouteHint{})
+}
+
+// RouteWithHint implements the HeightAwareRouter interface.
+//
+// If hinted, height aware routers take precedence over the others (e.g., redis router) which are
+// unaware of the hint, and the latter are only tried as best effort.
+func (r *chainedRouter) RouteWithHint(group Group, key []byte, hint RouteHint) string {
+	routers := r.routers
+	if !hint.IsEmpty() {
+		routers = slices.Clone(r.routers)
+		slices.SortStableFunc(routers, func(a, b Router) int {
+			_, aok := a.(HeightAwareRouter)
+			_, bok := b.(HeightAwareRouter)
+
+			switch {
+			case aok && !bok:
+				return -1
+			case !aok && bok:
+				return 1
+			default:
+				return 0
+			}
+		})
+	}
+
+	for _, r := range routers {
+		if val := routeWithHint(r, group, key, hint); len(val) > 0 {
 			return val
 		}
 	}
@@ -193,6 +220,17 @@ func (r *NodeRpcRouter) Route(group Group, key []byte) string {
 	return result
 }
 
+// RouteWithHint implements the HeightAwareRouter interface.
+func (r *NodeRpcRouter) RouteWithHint(group Group, key []byte, hint RouteHint) string {
+	var result string
+	if err := r.client.Call(&result, "node_routeWithHint", group, hexutil.Bytes(key), hint); err != nil {
+		logrus.WithError(err).Error("Failed to route key with hint from node RPC")
+		return ""
+	}
+
+	return result
+}
+
 type localNode string
 
 func (n localNode) String() string { return string(n) }
@@ -206,11 +244,13 @@ func (h hasher) Sum64(data []byte) uint64 {
 type localNodeGroup struct {
 	nodes    map[string]localNode // name -> node URL
 	hashRing *consistent.Consistent
+	strategy routingStrategy // nil for consistent hashing
 }
 
-func newLocalNodeGroup(urls []string) *localNodeGroup {
+func newLocalNodeGroup(group Group, urls []string) *localNodeGroup {
 	item := localNodeGroup{
-		nodes: make(map[string]localNode),
+		nodes:    make(map[string]localNode),
+		strategy: newRoutingStrategy(group, cfg.Routing.Strategies[string(group)]),
 	}
 
 	var members []consistent.Member
@@ -228,17 +268,44 @@ func newLocalNodeGroup(urls []string) *localNodeGroup {
 	return &item
 }
 
-// LocalRouter routes RPC requests based on local hash ring.
+// selectNode selects a node URL by the routing strategy.
+func (g *localNodeGroup) selectNode() string {
+	if len(g.nodes) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(g.nodes))
+	for name := range g.nodes {
+		names = append(names, name)
+	}
+
+	return g.nodes[names[g.strategy.Select(names)]].String()
+}
+
+// locateKey locates a node URL by consistent hashing of the key.
+func (g *localNodeGroup) locateKey(key []byte) string {
+	if member := g.hashRing.LocateKey(key); member != nil {
+		return member.String()
+	}
+
+	return ""
+}
+
+// LocalRouter routes RPC requests based on local hash ring or the configured routing strategy.
 type LocalRouter struct {
 	mu     sync.Mutex
 	groups map[Group]*localNodeGroup
+	// URLs of draining nodes polled from node management RPC
+	drainings []string
+	// node management RPC client, nil if routed by static config
+	client *rpc.Client
 }
 
 func NewLocalRouter(group2Urls map[Group][]string) *LocalRouter {
 	groups := make(map[Group]*localNodeGroup)
 
 	for k, v := range group2Urls {
-		groups[k] = newLocalNodeGroup(v)
+		groups[k] = newLocalNodeGroup(k, v)
 	}
 	return &LocalRouter{groups: groups}
 }
@@ -252,8 +319,29 @@ func (r *LocalRouter) Route(group Group, key []byte) string {
 		return ""
 	}
 
-	if member := item.hashRing.LocateKey(key); member != nil {
-		return member.String()
+	if item.strategy != nil {
+		return item.selectNode()
+	}
+
+	return item.locateKey(key)
+}
+
+// RouteWithHint implements the HeightAwareRouter interface.
+//
+// Note, local router is not aware of the latest epoch of full nodes, so the height hint is satisfied
+// by consistent hashing regardless of the configured routing strategy, which sticks requests of the
+// same key (e.g., client IP) to the same full node to never see the chain go backwards. Whereas, the
+// capability hint is not supported and falls back to the normal routing.
+func (r *LocalRouter) RouteWithHint(group Group, key []byte, hint RouteHint) string {
+	if hint.MinHeight == 0 && !hint.Synced {
+		return r.Route(group, key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if item, ok := r.groups[group]; ok {
+		return item.locateKey(key)
 	}
 
 	return ""
@@ -279,6 +367,7 @@ func (r *LocalRouter) ListNodesByGroup(group Group) (urls []string) {
 func NewLocalRouterFromNodeRPC(client *rpc.Client) (*LocalRouter, error) {
 	router := &LocalRouter{
 		groups: make(map[Group]*localNodeGroup),
+		client: client,
 	}
 
 	if err := router.pollOnce(client); err != nil {
@@ -309,17 +398,57 @@ func (r *LocalRouter) pollOnce(client *rpc.Client) error {
 		return errors.WithMessage(err, "failed to list all group nodes")
 	}
 
+	var drainings []string
+	if err := client.Call(&drainings, "node_listDraining"); err != nil {
+		// in case of node management RPC not upgraded yet
+		logrus.WithError(err).Debug("Failed to list draining nodes from node manager RPC")
+	}
+
+	// stop routing new requests to the draining nodes
+	drainingSet := make(map[string]bool)
+	for _, url := range drainings {
+		drainingSet[rpcutil.Url2NodeName(url)] = true
+	}
+
+	for grp, urls := range groupNodes {
+		groupNodes[grp] = slices.DeleteFunc(urls, func(url string) bool {
+			return drainingSet[rpcutil.Url2NodeName(url)]
+		})
+	}
+
 	r.update(groupNodes)
+
+	r.mu.Lock()
+	r.drainings = drainings
+	r.mu.Unlock()
+
 	return nil
 }
 
+// ListDrainingNodes implements the DrainProvider interface.
+func (r *LocalRouter) ListDrainingNodes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.drainings
+}
+
+// ReportDrain implements the DrainReporter interface.
+func (r *LocalRouter) ReportDrain(reporter string, counts map[string]DrainCounts) error {
+	if r.client == nil {
+		return nil
+	}
+
+	return r.client.Call(nil, "node_reportDrain", reporter, counts)
+}
+
 func (r *LocalRouter) update(groupNodes map[Group][]string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	for grp, urls := range groupNodes {
 		if _, ok := r.groups[grp]; !ok { // create new local node group
-			r.groups[grp] = newLocalNodeGroup(urls)
+			r.groups[grp] = newLocalNodeGroup(grp, urls)
 			continue
 		}
 
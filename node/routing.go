@@ -0,0 +1,157 @@
+package node
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/Conflux-Chain/confura/util/metrics"
+	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
+	metricUtil "github.com/Conflux-Chain/go-conflux-util/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RoutingConsistentHash routes RPC requests by consistent hashing of the route key (e.g., client IP),
+	// which is suitable for sticky workloads. This is the default routing strategy.
+	RoutingConsistentHash = "consistenthash"
+	// RoutingLeastOutstanding routes RPC requests to the node with the least outstanding requests.
+	RoutingLeastOutstanding = "leastoutstanding"
+	// RoutingEwmaLatency routes RPC requests to nodes randomly weighted by the inverse of the EWMA latency.
+	RoutingEwmaLatency = "ewmalatency"
+)
+
+// routingStrategy selects a full node among the candidate nodes of a group to route RPC requests.
+//
+// Note, the RPC request statistics are collected by the client middlewares of the RPC proxy process,
+// so the node manager RPC server could only route by the heartbeat latency, and falls back to the
+// consistent hashing for the least outstanding strategy. Prefer the local router (e.g., neither Redis
+// nor node manager RPC configured) for load-aware routing.
+type routingStrategy interface {
+	// Select returns the index of the selected node by node names.
+	Select(nodeNames []string) int
+}
+
+// newRoutingStrategy creates the routing strategy by name, or nil for consistent hashing.
+func newRoutingStrategy(group Group, name string) routingStrategy {
+	switch strings.ToLower(name) {
+	case "", RoutingConsistentHash:
+		return nil
+	case RoutingLeastOutstanding:
+		return leastOutstandingStrategy{}
+	case RoutingEwmaLatency:
+		return ewmaLatencyStrategy{group: group}
+	default:
+		logrus.WithFields(logrus.Fields{
+			"group":    group,
+			"strategy": name,
+		}).Fatal("Unsupported node routing strategy")
+		return nil
+	}
+}
+
+// newManagerRoutingStrategy creates the routing strategy by name for node manager, which never sees
+// the outstanding requests served by RPC proxies, and thus routes by consistent hashing instead of
+// the least outstanding strategy.
+func newManagerRoutingStrategy(group Group, name string) routingStrategy {
+	if strings.ToLower(name) == RoutingLeastOutstanding {
+		logrus.WithField("group", group).Warn(
+			"Least outstanding routing strategy not supported by node manager, fallback to consistent hashing",
+		)
+		return nil
+	}
+
+	return newRoutingStrategy(group, name)
+}
+
+// leastOutstandingStrategy selects the node with the least outstanding requests, and ties are broken
+// randomly so as to avoid always routing to the same node when idle.
+type leastOutstandingStrategy struct{}
+
+func (leastOutstandingStrategy) Select(nodeNames []string) int {
+	selected, minInflight := -1, int64(0)
+
+	offset := rand.Intn(len(nodeNames))
+	for i := range nodeNames {
+		idx := (offset + i) % len(nodeNames)
+		inflight := rpcutil.GetNodeStats(nodeNames[idx]).Inflight()
+
+		if selected < 0 || inflight < minInflight {
+			selected, minInflight = idx, inflight
+		}
+	}
+
+	return selected
+}
+
+// ewmaLatencyStrategy selects node randomly weighted by the inverse of the EWMA latency of the RPC
+// requests, and falls back to the heartbeat latency if no RPC request completed recently, so that the
+// node rarely picked due to a latency spike will be routed again once it recovers.
+type ewmaLatencyStrategy struct {
+	group Group
+}
+
+func (s ewmaLatencyStrategy) Select(nodeNames []string) int {
+	latencies := make([]float64, len(nodeNames))
+	for i, name := range nodeNames {
+		latencies[i] = s.latency(name)
+	}
+
+	return selectWeighted(inverseWeights(latencies), rand.Float64())
+}
+
+// latency returns the latency of node in nanoseconds, or 0 if unknown.
+func (s ewmaLatencyStrategy) latency(nodeName string) float64 {
+	if latency := rpcutil.GetNodeStats(nodeName).EwmaLatency(); latency > 0 {
+		return float64(latency)
+	}
+
+	name := metrics.Registry.Nodes.NodeLatency(s.group.Space(), s.group.String(), nodeName)
+	return metricUtil.GetOrRegisterHistogram(name).Snapshot().Mean()
+}
+
+// inverseWeights returns weights by the inverse of the specified latencies, where unknown latency
+// is regarded as the average of known ones.
+func inverseWeights(latencies []float64) []float64 {
+	var sum float64
+	var known int
+
+	for _, l := range latencies {
+		if l > 0 {
+			sum += l
+			known++
+		}
+	}
+
+	weights := make([]float64, len(latencies))
+	for i, l := range latencies {
+		switch {
+		case l > 0:
+			weights[i] = 1 / l
+		case known > 0:
+			weights[i] = float64(known) / sum
+		default: // all unknown
+			weights[i] = 1
+		}
+	}
+
+	return weights
+}
+
+// selectWeighted selects the index by weights, where r is a random number in [0, 1).
+func selectWeighted(weights []float64, r float64) int {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	target := r * total
+	for i, w := range weights {
+		if target < w {
+			return i
+		}
+
+		target -= w
+	}
+
+	return len(weights) - 1
+}
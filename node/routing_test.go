@@ -0,0 +1,92 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInverseWeights(t *testing.T) {
+	assert.Equal(t, []float64{1, 1}, inverseWeights([]float64{0, 0}))
+	assert.Equal(t, []float64{0.5, 0.25}, inverseWeights([]float64{2, 4}))
+
+	// unknown latency regarded as the average of known ones
+	assert.Equal(t, []float64{0.5, 0.25, 1.0 / 3}, inverseWeights([]float64{2, 4, 0}))
+}
+
+func TestSelectWeighted(t *testing.T) {
+	weights := []float64{1, 2, 1}
+
+	assert.Equal(t, 0, selectWeighted(weights, 0))
+	assert.Equal(t, 0, selectWeighted(weights, 0.2))
+	assert.Equal(t, 1, selectWeighted(weights, 0.25))
+	assert.Equal(t, 1, selectWeighted(weights, 0.7))
+	assert.Equal(t, 2, selectWeighted(weights, 0.75))
+	assert.Equal(t, 2, selectWeighted(weights, 0.99))
+}
+
+func TestNewRoutingStrategy(t *testing.T) {
+	assert.Nil(t, newRoutingStrategy(GroupCfxHttp, ""))
+	assert.Nil(t, newRoutingStrategy(GroupCfxHttp, "consistentHash"))
+	assert.IsType(t, leastOutstandingStrategy{}, newRoutingStrategy(GroupCfxHttp, "leastOutstanding"))
+	assert.IsType(t, ewmaLatencyStrategy{}, newRoutingStrategy(GroupCfxHttp, "ewmaLatency"))
+}
+
+func TestLocalRouterRouteWithHint(t *testing.T) {
+	MustInit()
+
+	cfg.Routing.Strategies = map[string]string{string(GroupCfxHttp): RoutingLeastOutstanding}
+	defer func() { cfg.Routing.Strategies = nil }()
+
+	router := NewLocalRouter(map[Group][]string{GroupCfxHttp: testGroupNodeUrls[GroupCfxHttp]})
+	key := []byte("127.0.0.1")
+	expected := router.groups[GroupCfxHttp].locateKey(key)
+
+	// height hinted requests always stick to the same node by consistent hashing
+	for _, hint := range []RouteHint{{MinHeight: 100}, {Synced: true}} {
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, expected, routeWithHint(router, GroupCfxHttp, key, hint))
+		}
+	}
+
+	// routed by strategy if no height hinted
+	url := router.RouteWithHint(GroupCfxHttp, key, RouteHint{Namespace: "debug"})
+	assert.Contains(t, testGroupNodeUrls[GroupCfxHttp], url)
+	assert.Empty(t, router.RouteWithHint(GroupEthHttp, key, RouteHint{MinHeight: 100}))
+}
+
+type stubRouter string
+
+func (r stubRouter) Route(group Group, key []byte) string { return string(r) }
+
+type stubHeightAwareRouter struct {
+	stubRouter
+	hinted string
+}
+
+func (r stubHeightAwareRouter) RouteWithHint(group Group, key []byte, hint RouteHint) string {
+	return r.hinted
+}
+
+func TestChainedRouterRouteWithHint(t *testing.T) {
+	key := []byte("127.0.0.1")
+	groupConf := map[Group]UrlConfig{GroupCfxHttp: {Failover: "failover"}}
+
+	// redis router first in chain, which is unaware of the hint
+	router := NewChainedRouter(groupConf, stubRouter("redis"), stubHeightAwareRouter{"rpc", "rpc-hinted"})
+
+	assert.Equal(t, "redis", router.Route(GroupCfxHttp, key))
+	assert.Equal(t, "redis", routeWithHint(router, GroupCfxHttp, key, RouteHint{}))
+
+	// height aware router takes precedence if hinted
+	assert.Equal(t, "rpc-hinted", routeWithHint(router, GroupCfxHttp, key, RouteHint{MinHeight: 100}))
+	assert.Equal(t, "rpc-hinted", routeWithHint(router, GroupCfxHttp, key, RouteHint{Synced: true}))
+
+	// falls back to the router unaware of the hint as best effort
+	router = NewChainedRouter(groupConf, stubRouter("redis"), stubHeightAwareRouter{"", ""})
+	assert.Equal(t, "redis", routeWithHint(router, GroupCfxHttp, key, RouteHint{Synced: true}))
+
+	// failover if no router handled
+	router = NewChainedRouter(groupConf, stubRouter(""), stubHeightAwareRouter{"", ""})
+	assert.Equal(t, "failover", routeWithHint(router, GroupCfxHttp, key, RouteHint{Synced: true}))
+}
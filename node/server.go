@@ -2,6 +2,7 @@ package node
 
 import (
 	"sync"
+	"time"
 
 	"github.com/Conflux-Chain/confura/store/mysql"
 	"github.com/Conflux-Chain/confura/util/rpc"
@@ -12,10 +13,13 @@ import (
 
 var (
 	errDbNotAvailableForPersistence = errors.New("db not available for persistence")
+	errNodeNotFound                 = errors.New("node not found")
 )
 
 // MustNewServer creates node management RPC server
-func MustNewServer(db *mysql.MysqlStore, nf nodeFactory, grpConf map[Group]UrlConfig) *rpc.Server {
+func MustNewServer(
+	db *mysql.MysqlStore, nf nodeFactory, grpConf map[Group]UrlConfig, discoveries map[Group]DiscoverySource,
+) *rpc.Server {
 	npool := newNodePool(nf)
 
 	if db != nil {
@@ -41,8 +45,32 @@ func MustNewServer(db *mysql.MysqlStore, nf nodeFactory, grpConf map[Group]UrlCo
 		}
 	}
 
+	if db != nil {
+		// restore node maintenances from db
+		maints, err := db.LoadNodeMaintenances()
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load node maintenances from db")
+		}
+
+		for _, maint := range maints {
+			if !npool.drain(maint.Url, maint.Since) {
+				logrus.WithField("maintenance", maint).Warn("Draining node not found in the pool")
+			}
+		}
+	}
+
+	h := &apiHandler{dbs: db, pool: npool}
+
+	if len(discoveries) > 0 {
+		// reconcile group nodes against the discovery sources
+		d := mustNewDiscovery(h, discoveries)
+		d.reconcileOnce()
+
+		go d.run()
+	}
+
 	return rpc.MustNewServer("node", map[string]interface{}{
-		"node": &api{h: &apiHandler{dbs: db, pool: npool}},
+		"node": &api{h: h},
 	})
 }
 
@@ -89,6 +117,29 @@ func (api *api) ListAll() map[Group][]string {
 	return res
 }
 
+// Drain stops routing new requests to the node of all groups, so that in-flight requests and
+// subscriptions could be migrated gracefully before the node removed or upgraded. The drain state
+// is persisted if db available.
+func (api *api) Drain(url string) error {
+	return api.h.drainNode(url)
+}
+
+// Undrain resumes routing new requests to the node of all groups.
+func (api *api) Undrain(url string) error {
+	return api.h.undrainNode(url)
+}
+
+// ListDraining returns the URL list of all draining nodes.
+func (api *api) ListDraining() []string {
+	return api.h.pool.draining()
+}
+
+// ReportDrain reports the number of in-flight requests and delegated subscriptions (keyed by node
+// URL) on draining nodes from RPC server, so as to tell whether the nodes are drained.
+func (api *api) ReportDrain(reporter string, counts map[string]DrainCounts) {
+	api.h.pool.reportDrain(reporter, counts, time.Now())
+}
+
 // Route implements the Router interface. It routes the specified key to any node
 // and return the node URL.
 func (api *api) Route(group Group, key hexutil.Bytes) string {
@@ -99,6 +150,16 @@ func (api *api) Route(group Group, key hexutil.Bytes) string {
 	return ""
 }
 
+// RouteWithHint implements the HeightAwareRouter interface. It routes the specified key to any node
+// that prefers to have reached the hinted block height and return the node URL.
+func (api *api) RouteWithHint(group Group, key hexutil.Bytes, hint RouteHint) string {
+	if m, ok := api.h.pool.manager(group); ok {
+		return m.RouteWithHint(key, hint)
+	}
+
+	return ""
+}
+
 // apiHandler rpc handler for node api
 type apiHandler struct {
 	mu sync.Mutex
@@ -168,3 +229,85 @@ func (h *apiHandler) delGroupNode(grp Group, url string, saveGrp bool) (err erro
 
 	return err
 }
+
+// reconcileGroupNodes adds or removes group nodes to match the specified node URLs, and returns
+// the added and removed node URLs.
+func (h *apiHandler) reconcileGroupNodes(grp Group, urls []string, saveGrp bool) (added, removed []string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	added, removed = diffNodeUrls(h.pool.get(grp), urls)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil, nil, nil
+	}
+
+	if err := h.pool.add(grp, added...); err != nil {
+		return nil, nil, err
+	}
+
+	h.pool.del(grp, removed...)
+
+	if !saveGrp { // in-memory update only
+		return added, removed, nil
+	}
+
+	routeGroup := &mysql.NodeRouteGroup{
+		Name:  string(grp),
+		Nodes: dedupNodeUrls(h.pool.get(grp)),
+	}
+
+	if err := h.dbs.StoreNodeRouteGroup(routeGroup); err != nil {
+		// revert in-memory update
+		h.pool.del(grp, added...)
+		if err := h.pool.add(grp, removed...); err != nil {
+			logrus.WithField("group", grp).WithError(err).Error("Failed to revert removed group nodes")
+		}
+
+		return nil, nil, err
+	}
+
+	return added, removed, nil
+}
+
+func (h *apiHandler) drainNode(url string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.pool.contains(url) {
+		return errNodeNotFound
+	}
+
+	since := time.Now()
+	h.pool.drain(url, since)
+
+	if h.dbs == nil { // in-memory update only
+		return nil
+	}
+
+	maint := &mysql.NodeMaintenance{
+		Name:  rpc.Url2NodeName(url),
+		Url:   url,
+		Since: since,
+	}
+
+	if err := h.dbs.StoreNodeMaintenance(maint); err != nil {
+		h.pool.undrain(url) // revert in-memory update
+		return err
+	}
+
+	return nil
+}
+
+func (h *apiHandler) undrainNode(url string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.dbs != nil {
+		if err := h.dbs.DelNodeMaintenance(rpc.Url2NodeName(url)); err != nil {
+			return err
+		}
+	}
+
+	h.pool.undrain(url)
+	return nil
+}
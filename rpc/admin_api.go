@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/Conflux-Chain/confura/util/rate"
+	"github.com/pkg/errors"
+)
+
+const (
+	// max number of usage records returned at a time
+	maxKeyUsageRecords = 10_000
+)
+
+// adminAPI provides administrative RPC methods, e.g., to query the usages of rate limit keys.
+type adminAPI struct {
+	registry *rate.Registry
+}
+
+// GetKeyUsage returns hourly usages of the rate limit key within the specified time range in
+// unix seconds, optionally filtered by RPC methods.
+func (api *adminAPI) GetKeyUsage(
+	ctx context.Context, limitKey string, from, to int64, methods *[]string,
+) ([]*rate.Usage, error) {
+	if api.registry == nil {
+		return nil, errors.New("rate limit not enabled")
+	}
+
+	if len(limitKey) == 0 {
+		return nil, errors.New("limit key must not be empty")
+	}
+
+	if from > to {
+		return nil, errors.New("invalid time range")
+	}
+
+	filter := &rate.UsageFilter{
+		LimitKey: limitKey,
+		From:     time.Unix(from, 0).Truncate(time.Hour),
+		To:       time.Unix(to, 0),
+		Limit:    maxKeyUsageRecords,
+	}
+
+	if methods != nil {
+		filter.Methods = *methods
+	}
+
+	return api.registry.LoadUsages(filter)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/Conflux-Chain/confura/rpc/cfxbridge"
 	"github.com/Conflux-Chain/confura/rpc/handler"
 	"github.com/Conflux-Chain/confura/util/metrics/service"
+	"github.com/Conflux-Chain/confura/util/rate"
 	"github.com/Conflux-Chain/confura/util/rpc"
 	sdk "github.com/Conflux-Chain/go-conflux-sdk"
 	"github.com/pkg/errors"
@@ -55,16 +56,17 @@ func filterExposedApis(allApis []API, exposedModules []string) (map[string]inter
 
 // nativeSpaceApis returns the collection of built-in RPC APIs for core space.
 func nativeSpaceApis(
+	registry *rate.Registry,
 	clientProvider *node.CfxClientProvider,
 	gashandler *handler.CfxGasStationHandler,
 	option ...CfxAPIOption,
 ) []API {
 	stateHandler := handler.NewCfxStateHandler(clientProvider)
-	return []API{
+	apis := []API{
 		{
 			Namespace: "cfx",
 			Version:   "1.0",
-			Service:   newCfxAPI(clientProvider, option...),
+			Service:   newCfxAPI(clientProvider, gashandler, option...),
 			Public:    true,
 		}, {
 			Namespace: "txpool",
@@ -96,21 +98,38 @@ func nativeSpaceApis(
 			Version:   "1.0",
 			Service:   &cfxDebugAPI{stateHandler},
 			Public:    false,
+		}, {
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   &adminAPI{registry},
+			Public:    false,
 		},
 	}
+
+	if len(option) > 0 && (option[0].ReorgHandler != nil || option[0].TxnTracker != nil) {
+		apis = append(apis, API{
+			Namespace: "confura",
+			Version:   "1.0",
+			Service:   newConfuraAPI(option[0].ReorgHandler, option[0].TxnTracker),
+			Public:    true,
+		})
+	}
+
+	return apis
 }
 
 // evmSpaceApis returns the collection of built-in RPC APIs for EVM space.
 func evmSpaceApis(
+	registry *rate.Registry,
 	clientProvider *node.EthClientProvider,
 	gashandler *handler.EthGasStationHandler,
 	option ...EthAPIOption) ([]API, error) {
 	stateHandler := handler.NewEthStateHandler(clientProvider)
-	return []API{
+	apis := []API{
 		{
 			Namespace: "eth",
 			Version:   "1.0",
-			Service:   mustNewEthAPI(clientProvider, option...),
+			Service:   mustNewEthAPI(clientProvider, gashandler, option...),
 			Public:    true,
 		}, {
 			Namespace: "web3",
@@ -142,8 +161,24 @@ func evmSpaceApis(
 			Version:   "1.0",
 			Service:   newEthGasStationAPI(gashandler),
 			Public:    false,
+		}, {
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   &adminAPI{registry},
+			Public:    false,
 		},
-	}, nil
+	}
+
+	if len(option) > 0 && (option[0].ReorgHandler != nil || option[0].TxnTracker != nil) {
+		apis = append(apis, API{
+			Namespace: "confura",
+			Version:   "1.0",
+			Service:   newConfuraAPI(option[0].ReorgHandler, option[0].TxnTracker),
+			Public:    true,
+		})
+	}
+
+	return apis, nil
 }
 
 // nativeSpaceBridgeApis adapts evm space RPCs to core space RPCs.
@@ -2,11 +2,14 @@ package rpc
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/Conflux-Chain/confura/node"
 	"github.com/Conflux-Chain/confura/rpc/handler"
 	"github.com/Conflux-Chain/confura/util"
+	"github.com/Conflux-Chain/confura/util/accesslog"
 	"github.com/Conflux-Chain/confura/util/metrics"
+	"github.com/Conflux-Chain/confura/util/rpc/cache"
 	vfclient "github.com/Conflux-Chain/confura/virtualfilter/client"
 	sdk "github.com/Conflux-Chain/go-conflux-sdk"
 	"github.com/Conflux-Chain/go-conflux-sdk/types"
@@ -17,7 +20,9 @@ import (
 )
 
 const (
-	rpcMethodCfxGetLogs = "cfx_getLogs"
+	rpcMethodCfxGetLogs      = "cfx_getLogs"
+	rpcMethodCfxGetLogsPaged = "cfx_getLogsPaged"
+	rpcMethodCfxSubscribe    = "cfx_subscribe"
 )
 
 var (
@@ -31,6 +36,10 @@ type CfxAPIOption struct {
 	LogApiHandler       *handler.CfxLogsApiHandler
 	TxnHandler          *handler.CfxTxnHandler
 	VirtualFilterClient *vfclient.CfxClient
+	ResponseCache       *cache.ResponseCache  // optional cache for immutable RPC responses
+	ReorgHandler        *handler.ReorgHandler // optional handler to expose chain reorgs
+	TxnTracker          *handler.TxnTracker   // optional tracker of the submitted transactions
+	AccessLogger        *accesslog.Logger     // optional logger to record structured access logs
 }
 
 // cfxAPI provides main proxy API for core space.
@@ -39,10 +48,13 @@ type cfxAPI struct {
 	provider         *node.CfxClientProvider
 	inputEpochMetric metrics.InputEpochMetric
 	stateHandler     *handler.CfxStateHandler
+	gasStation       *handler.CfxGasStationHandler // gas station to estimate gas fees, could be nil
 	etPubsubLogger   *logutil.ErrorTolerantLogger
 }
 
-func newCfxAPI(provider *node.CfxClientProvider, option ...CfxAPIOption) *cfxAPI {
+func newCfxAPI(
+	provider *node.CfxClientProvider, gasStation *handler.CfxGasStationHandler, option ...CfxAPIOption,
+) *cfxAPI {
 	var opt CfxAPIOption
 	if len(option) > 0 {
 		opt = option[0]
@@ -52,6 +64,7 @@ func newCfxAPI(provider *node.CfxClientProvider, option ...CfxAPIOption) *cfxAPI
 		CfxAPIOption:   opt,
 		provider:       provider,
 		stateHandler:   handler.NewCfxStateHandler(provider),
+		gasStation:     gasStation,
 		etPubsubLogger: logutil.NewErrorTolerantLogger(logutil.DefaultETConfig),
 	}
 }
@@ -74,6 +87,10 @@ func toEpochOrBlockHashSlice(epoch *types.EpochOrBlockHash) []*types.EpochOrBloc
 
 func (api *cfxAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
 	cfx := GetCfxClientFromContext(ctx)
+	if gasPrice, _, ok := api.estimateFees(cfx); ok {
+		return (*hexutil.Big)(gasPrice), nil
+	}
+
 	return cfx.GetGasPrice()
 }
 
@@ -158,7 +175,7 @@ func (api *cfxAPI) GetBlockByHash(ctx context.Context, blockHash types.Hash, inc
 		block, err := api.StoreHandler.GetBlockByHash(ctx, blockHash, includeTxs)
 
 		logger.WithError(err).Debug("Delegated `cfx_getBlockByHash` to store handler")
-		api.collectHitStats("cfx_getBlockByHash", err == nil)
+		api.collectHitStats(ctx, "cfx_getBlockByHash", err == nil)
 
 		if err == nil {
 			return block, nil
@@ -196,7 +213,7 @@ func (api *cfxAPI) GetBlockByEpochNumber(ctx context.Context, epoch types.Epoch,
 		block, err := api.StoreHandler.GetBlockByEpochNumber(ctx, &epoch, includeTxs)
 
 		logger.WithError(err).Debug("Delegated `cfx_getBlockByEpochNumber` to store handler")
-		api.collectHitStats("cfx_getBlockByEpochNumber", err == nil)
+		api.collectHitStats(ctx, "cfx_getBlockByEpochNumber", err == nil)
 
 		if err == nil {
 			return block, nil
@@ -222,7 +239,7 @@ func (api *cfxAPI) GetBlockByBlockNumber(
 		block, err := api.StoreHandler.GetBlockByBlockNumber(ctx, blockNumer, includeTxs)
 
 		logger.WithError(err).Debug("Delegated `cfx_getBlockByBlockNumber` to store handler")
-		api.collectHitStats("cfx_getBlockByBlockNumber", err == nil)
+		api.collectHitStats(ctx, "cfx_getBlockByBlockNumber", err == nil)
 
 		if err == nil {
 			return block, nil
@@ -280,22 +297,13 @@ func (api *cfxAPI) getLogs(
 ) ([]types.Log, error) {
 	metrics.UpdateCfxRpcLogFilter(rpcMethod, cfx, &fq)
 
-	flag, ok := ParseLogFilterType(&fq)
-	if !ok {
-		return emptyLogs, ErrInvalidLogFilter
-	}
-
-	if err := NormalizeLogFilter(cfx, flag, &fq); err != nil {
-		return emptyLogs, err
-	}
-
-	if err := ValidateLogFilter(flag, &fq); err != nil {
+	if err := api.normalizeLogFilter(cfx, &fq); err != nil {
 		return emptyLogs, err
 	}
 
 	if api.LogApiHandler != nil {
 		logs, hitStore, err := api.LogApiHandler.GetLogs(ctx, cfx, &fq, rpcMethod)
-		api.collectHitStats(rpcMethod, hitStore)
+		api.collectHitStats(ctx, rpcMethod, hitStore)
 		return uniformCfxLogs(logs), err
 	}
 
@@ -303,6 +311,61 @@ func (api *cfxAPI) getLogs(
 	return cfx.GetLogs(fq)
 }
 
+// GetLogsPaged returns a page of logs matching a given epoch range filter object, along with an
+// opaque cursor to fetch the next page if there are more logs.
+func (api *cfxAPI) GetLogsPaged(
+	ctx context.Context, fq types.LogFilter, cursor *string, limit *hexutil.Uint64,
+) (*handler.LogsPage[types.Log], error) {
+	if api.LogApiHandler == nil {
+		return nil, errLogsPagingUnsupported
+	}
+
+	// cursors are bound to the raw log filter before block tags resolved
+	filterHash := handler.HashLogsFilter(&fq)
+
+	cfx := GetCfxClientFromContext(ctx)
+	metrics.UpdateCfxRpcLogFilter(rpcMethodCfxGetLogsPaged, cfx, &fq)
+
+	if err := api.normalizeLogFilter(cfx, &fq); err != nil {
+		return nil, err
+	}
+
+	var pageCursor string
+	if cursor != nil {
+		pageCursor = *cursor
+	}
+
+	var pageLimit uint64
+	if limit != nil {
+		pageLimit = uint64(*limit)
+	}
+
+	page, hitStore, err := api.LogApiHandler.GetLogsPaged(
+		ctx, cfx, &fq, filterHash, pageCursor, pageLimit, rpcMethodCfxGetLogsPaged,
+	)
+	api.collectHitStats(ctx, rpcMethodCfxGetLogsPaged, hitStore)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Logs = uniformCfxLogs(page.Logs)
+	return page, nil
+}
+
+// normalizeLogFilter normalizes and validates the log filter.
+func (api *cfxAPI) normalizeLogFilter(cfx sdk.ClientOperator, fq *types.LogFilter) error {
+	flag, ok := ParseLogFilterType(fq)
+	if !ok {
+		return ErrInvalidLogFilter
+	}
+
+	if err := NormalizeLogFilter(cfx, flag, fq); err != nil {
+		return err
+	}
+
+	return ValidateLogFilter(flag, fq)
+}
+
 func (api *cfxAPI) GetTransactionByHash(ctx context.Context, txHash types.Hash) (*types.Transaction, error) {
 	logger := logrus.WithFields(logrus.Fields{"txHash": txHash})
 
@@ -310,7 +373,7 @@ func (api *cfxAPI) GetTransactionByHash(ctx context.Context, txHash types.Hash)
 		txn, err := api.StoreHandler.GetTransactionByHash(ctx, txHash)
 
 		logger.WithError(err).Debug("Delegated `cfx_getTransactionByHash` to store handler")
-		api.collectHitStats("cfx_getTransactionByHash", err == nil)
+		api.collectHitStats(ctx, "cfx_getTransactionByHash", err == nil)
 
 		if err == nil {
 			return txn, nil
@@ -347,7 +410,7 @@ func (api *cfxAPI) GetBlocksByEpoch(ctx context.Context, epoch types.Epoch) ([]t
 		blocks, err := api.StoreHandler.GetBlocksByEpoch(ctx, &epoch)
 
 		logger.WithError(err).Debug("Delegated `cfx_getBlocksByEpoch` to store handler")
-		api.collectHitStats("cfx_getBlocksByEpoch", err == nil)
+		api.collectHitStats(ctx, "cfx_getBlocksByEpoch", err == nil)
 
 		if err == nil {
 			return blocks, nil
@@ -372,7 +435,7 @@ func (api *cfxAPI) GetTransactionReceipt(ctx context.Context, txHash types.Hash)
 		rcpt, err := api.StoreHandler.GetTransactionReceipt(ctx, txHash)
 
 		logger.WithError(err).Debug("Delegated `cfx_getTransactionReceipt` to store handler")
-		api.collectHitStats("cfx_getTransactionReceipt", err == nil)
+		api.collectHitStats(ctx, "cfx_getTransactionReceipt", err == nil)
 
 		if err == nil {
 			return rcpt, nil
@@ -481,14 +544,37 @@ func (api *cfxAPI) FeeHistory(
 
 func (api *cfxAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
 	cfx := GetCfxClientFromContext(ctx)
+	if _, priorityFee, ok := api.estimateFees(cfx); ok {
+		return (*hexutil.Big)(priorityFee), nil
+	}
+
 	return cfx.GetMaxPriorityFeePerGas()
 }
 
+// estimateFees estimates gas fees from gas station if fee estimation enabled, and returns false
+// if not enabled or gas station unavailable, in which case gas fees are delegated to full node.
+func (api *cfxAPI) estimateFees(cfx sdk.ClientOperator) (gasPrice, priorityFee *big.Int, ok bool) {
+	if api.gasStation == nil || !api.gasStation.FeeEstimationEnabled() {
+		return nil, nil, false
+	}
+
+	gasPrice, priorityFee, err := api.gasStation.EstimateFees(cfx)
+	if err != nil {
+		logrus.WithError(err).Debug("Failed to estimate gas fees from gas station, fallback to full node")
+		metrics.Registry.RPC.Percentage("cfx_gasStation", "fallback").Mark(true)
+		return nil, nil, false
+	}
+
+	metrics.Registry.RPC.Percentage("cfx_gasStation", "fallback").Mark(false)
+	return gasPrice, priorityFee, true
+}
+
 func (api *cfxAPI) GetFeeBurnt(ctx context.Context, epoch ...*types.Epoch) (info *hexutil.Big, err error) {
 	cfx := GetCfxClientFromContext(ctx)
 	return cfx.GetFeeBurnt(epoch...)
 }
 
-func (h *cfxAPI) collectHitStats(method string, hit bool) {
+func (h *cfxAPI) collectHitStats(ctx context.Context, method string, hit bool) {
 	metrics.Registry.RPC.StoreHit(method, "store").Mark(hit)
+	accesslog.SetStoreHit(ctx, hit)
 }
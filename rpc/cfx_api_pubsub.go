@@ -4,10 +4,12 @@ import (
 	"context"
 
 	"github.com/Conflux-Chain/confura/node"
+	"github.com/Conflux-Chain/confura/rpc/handler"
 	"github.com/Conflux-Chain/confura/util/metrics"
 	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
 	sdk "github.com/Conflux-Chain/go-conflux-sdk"
 	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/openweb3/go-rpc-provider"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -149,10 +151,27 @@ func (api *cfxAPI) Epochs(ctx context.Context, subEpoch *types.Epoch) (*rpc.Subs
 	return rpcSub, nil
 }
 
+// cfxLogsResumeOption is the option of resumable logs subscription, which replays the matched logs
+// since the specified epoch before live delivery, so that no log is lost due to dropped connection.
+type cfxLogsResumeOption struct {
+	// epoch number to replay logs from
+	FromEpoch *hexutil.Uint64 `json:"fromEpoch"`
+	// resume token of the last received log to resume right after it, which overrides `fromEpoch`
+	ResumeToken *string `json:"resumeToken"`
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *cfxAPI) Logs(ctx context.Context, filter types.LogFilter) (*rpc.Subscription, error) {
+// If resume option specified, the matched logs since the specified epoch will be replayed first,
+// and each log will be notified along with a resume token.
+func (api *cfxAPI) Logs(
+	ctx context.Context, filter types.LogFilter, option *cfxLogsResumeOption,
+) (*rpc.Subscription, error) {
 	metrics.Registry.PubSub.InputLogFilter("cfx").Mark(!isEmptyLogFilter(filter))
 
+	if option != nil && api.LogApiHandler == nil {
+		return &rpc.Subscription{}, errLogsResumeUnsupported
+	}
+
 	psCtx, supported, err := api.pubsubCtxFromContext(ctx)
 	if !supported {
 		logrus.WithError(err).Error("Logs pubsub notification unsupported")
@@ -180,6 +199,26 @@ func (api *cfxAPI) Logs(ctx context.Context, filter types.LogFilter) (*rpc.Subsc
 
 	logger := logrus.WithField("rpcSubID", rpcSub.ID)
 
+	notifyReplayed := func(log *types.Log, token string) {
+		psCtx.notifier.Notify(rpcSub.ID, resumableLog{log, token})
+	}
+
+	var replay *logsReplay[types.Log]
+	if option != nil {
+		// replay logs after the live subscription established so as not to miss any log
+		replay, err = api.newLogsReplay(ctx, psCtx.cfx, filter, option)
+		if err == nil {
+			// replay the first page to return error if any, e.g., invalid resume token
+			_, err = replay.replayOnce(notifyReplayed)
+		}
+
+		if err != nil {
+			dSub.unsubscribe()
+			logger.WithError(err).Debug("Failed to replay logs for resumable logs subscription")
+			return &rpc.Subscription{}, err
+		}
+	}
+
 	nodeName := rpcutil.Url2NodeName(psCtx.cfx.GetNodeURL())
 	counter := metrics.Registry.PubSub.Sessions("cfx", "logs", nodeName)
 	counter.Inc(1)
@@ -188,11 +227,51 @@ func (api *cfxAPI) Logs(ctx context.Context, filter types.LogFilter) (*rpc.Subsc
 		defer dSub.unsubscribe()
 		defer counter.Dec(1)
 
+		notifyLive := func(log *types.SubscriptionLog) error {
+			if replay == nil {
+				psCtx.notifier.Notify(rpcSub.ID, log)
+				return nil
+			}
+
+			if log.IsRevertLog() { // chain reorg
+				replay.revert(log.ChainReorg.RevertTo.ToInt().Uint64())
+				psCtx.notifier.Notify(rpcSub.ID, log)
+				return nil
+			}
+
+			token, ok, err := replay.track(log.EpochNumber.ToInt().Uint64())
+			if err != nil {
+				return errors.WithMessage(err, "failed to track log for resumable logs subscription")
+			}
+
+			if ok { // not replayed yet
+				psCtx.notifier.Notify(rpcSub.ID, resumableLog{log, token})
+			}
+
+			return nil
+		}
+
+		if replay != nil {
+			err := replayWithLive(
+				replay, logsCh, cfxLiveLogNumber, pubsubChannelBufferSize, notifyReplayed, notifyLive,
+			)
+			if err != nil {
+				logger.WithError(err).Info("Failed to replay logs for resumable logs subscription")
+				psCtx.rpcClient.Close()
+				return
+			}
+		}
+
 		for {
 			select {
 			case log := <-logsCh:
 				logger.WithField("log", log).Debug("Received new log from pubsub delegate")
-				psCtx.notifier.Notify(rpcSub.ID, log)
+
+				if err := notifyLive(log); err != nil {
+					logger.WithError(err).Info("Failed to notify live log")
+					psCtx.rpcClient.Close()
+					return
+				}
 
 			case err = <-dSub.err: // delegate subscription error
 				logger.WithError(err).Debug("Received error from logs pubsub delegate")
@@ -213,6 +292,59 @@ func (api *cfxAPI) Logs(ctx context.Context, filter types.LogFilter) (*rpc.Subsc
 	return rpcSub, nil
 }
 
+// newLogsReplay creates a logs replay for resumable logs subscription, which replays the matched
+// logs up to the latest executed epoch of the subscribed full node.
+func (api *cfxAPI) newLogsReplay(
+	ctx context.Context, cfx sdk.ClientOperator, filter types.LogFilter, option *cfxLogsResumeOption,
+) (*logsReplay[types.Log], error) {
+	latestEpoch, err := cfx.GetEpochNumber(types.EpochLatestState)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get latest state epoch number")
+	}
+
+	// resume tokens are bound to the subscription filter
+	filterHash := handler.HashLogsFilter(&filter)
+
+	tracker := api.LogApiHandler.NewLogsResumeTracker(filterHash)
+	fromEpoch, cursor, err := replayRange(
+		tracker, (*uint64)(option.FromEpoch), option.ResumeToken, latestEpoch.ToInt().Uint64(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// replay could last longer than the subscription request
+	replayCtx := context.WithoutCancel(ctx)
+
+	return &logsReplay[types.Log]{
+		tracker: tracker,
+		fetch: func(pageCursor string, from, to uint64) (*handler.LogsPage[types.Log], error) {
+			replayFilter := filter
+			replayFilter.FromEpoch = types.NewEpochNumberUint64(from)
+			replayFilter.ToEpoch = types.NewEpochNumberUint64(to)
+			replayFilter.FromBlock, replayFilter.ToBlock, replayFilter.BlockHashes = nil, nil, nil
+
+			page, _, err := api.LogApiHandler.GetLogsPaged(
+				replayCtx, cfx, &replayFilter, filterHash, pageCursor, 0, rpcMethodCfxSubscribe,
+			)
+			return page, err
+		},
+		numberOf:   func(log *types.Log) uint64 { return log.EpochNumber.ToInt().Uint64() },
+		fromNumber: fromEpoch,
+		cursor:     cursor,
+		toNumber:   latestEpoch.ToInt().Uint64(),
+	}, nil
+}
+
+// cfxLiveLogNumber returns the epoch number of live log, or the epoch reverted to for revert log.
+func cfxLiveLogNumber(log *types.SubscriptionLog) uint64 {
+	if log.IsRevertLog() {
+		return log.ChainReorg.RevertTo.ToInt().Uint64()
+	}
+
+	return log.EpochNumber.ToInt().Uint64()
+}
+
 type pubsubContext struct {
 	notifier  *rpc.Notifier
 	rpcClient *rpc.Client
@@ -0,0 +1,172 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Conflux-Chain/confura/rpc/handler"
+	"github.com/Conflux-Chain/confura/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// channel size to buffer lifecycle state changes of the subscribed transaction
+	txnStatusChannelBufferSize = 10
+)
+
+// rpcReorg is the chain reorg exposed to clients.
+type rpcReorg struct {
+	ForkPoint      hexutil.Uint64 `json:"forkPoint"`
+	Depth          hexutil.Uint64 `json:"depth"`
+	ReplacedHashes []string       `json:"replacedHashes"`
+	Timestamp      hexutil.Uint64 `json:"timestamp"`
+}
+
+func newRpcReorg(reorg *types.Reorg) *rpcReorg {
+	return &rpcReorg{
+		ForkPoint:      hexutil.Uint64(reorg.ForkPoint),
+		Depth:          hexutil.Uint64(reorg.Depth),
+		ReplacedHashes: reorg.ReplacedHashes,
+		Timestamp:      hexutil.Uint64(reorg.Timestamp.Unix()),
+	}
+}
+
+// confuraAPI provides Confura specific RPC APIs, e.g., chain reorg notifications and lifecycle
+// tracking of the submitted transactions.
+type confuraAPI struct {
+	reorgHandler *handler.ReorgHandler // optional
+	txnTracker   *handler.TxnTracker   // optional
+}
+
+func newConfuraAPI(reorgHandler *handler.ReorgHandler, txnTracker *handler.TxnTracker) *confuraAPI {
+	return &confuraAPI{reorgHandler: reorgHandler, txnTracker: txnTracker}
+}
+
+// GetReorgs returns the chain reorgs which replaced any epoch (or block in evm space) since the
+// specified one, in the order they happened. An error is returned if too many reorgs since then,
+// in which case clients should query from a later block.
+func (api *confuraAPI) GetReorgs(ctx context.Context, fromBlock hexutil.Uint64) ([]*rpcReorg, error) {
+	if api.reorgHandler == nil {
+		return nil, errReorgJournalUnsupported
+	}
+
+	reorgs, err := api.reorgHandler.GetReorgs(uint64(fromBlock))
+	if errors.Is(err, handler.ErrTooManyReorgs) {
+		return nil, err
+	}
+
+	if err != nil {
+		logrus.WithError(err).WithField("fromBlock", fromBlock).Error("Failed to get reorgs")
+		return nil, errReorgJournalUnavailable
+	}
+
+	result := make([]*rpcReorg, 0, len(reorgs))
+	for _, reorg := range reorgs {
+		result = append(result, newRpcReorg(reorg))
+	}
+
+	return result, nil
+}
+
+// Reorgs creates a subscription that fires each time chain reorg happens.
+func (api *confuraAPI) Reorgs(ctx context.Context) (*rpc.Subscription, error) {
+	if api.reorgHandler == nil {
+		return &rpc.Subscription{}, errReorgJournalUnsupported
+	}
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	reorgCh := make(chan *types.Reorg, pubsubChannelBufferSize)
+	unsubscribe := api.reorgHandler.Subscribe(reorgCh)
+
+	logger := logrus.WithField("rpcSubID", rpcSub.ID)
+
+	go func() {
+		defer unsubscribe()
+
+		for {
+			select {
+			case reorg := <-reorgCh:
+				logger.WithField("reorg", reorg).Debug("Received new reorg from reorg handler")
+				notifier.Notify(rpcSub.ID, newRpcReorg(reorg))
+
+			case err := <-rpcSub.Err(): // client connection closed or error
+				logger.WithError(err).Debug("Reorgs pubsub subscription error")
+				return
+
+			case <-notifier.Closed():
+				logger.Debug("Reorgs pubsub connection closed")
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// GetTransactionStatus returns the lifecycle state of transaction submitted via Confura, or null
+// if transaction not tracked, e.g., submitted via another RPC instance since states are kept in
+// memory per instance.
+func (api *confuraAPI) GetTransactionStatus(ctx context.Context, txHash common.Hash) (*handler.TxnState, error) {
+	if api.txnTracker == nil {
+		return nil, errTxnTrackingUnsupported
+	}
+
+	if state, ok := api.txnTracker.Status(txHash.Hex()); ok {
+		return &state, nil
+	}
+
+	return nil, nil
+}
+
+// TransactionStatus creates a subscription that fires each time the lifecycle status of the
+// transaction submitted via Confura changes.
+func (api *confuraAPI) TransactionStatus(ctx context.Context, txHash common.Hash) (*rpc.Subscription, error) {
+	if api.txnTracker == nil {
+		return &rpc.Subscription{}, errTxnTrackingUnsupported
+	}
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	stateCh := make(chan handler.TxnState, txnStatusChannelBufferSize)
+	unsubscribe, ok := api.txnTracker.Subscribe(txHash.Hex(), stateCh)
+	if !ok {
+		return &rpc.Subscription{}, errTxnNotTracked
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	logger := logrus.WithField("rpcSubID", rpcSub.ID)
+
+	go func() {
+		defer unsubscribe()
+
+		for {
+			select {
+			case state := <-stateCh:
+				logger.WithField("state", state).Debug("Received txn state change from txn tracker")
+				notifier.Notify(rpcSub.ID, state)
+
+			case err := <-rpcSub.Err(): // client connection closed or error
+				logger.WithError(err).Debug("Txn status pubsub subscription error")
+				return
+
+			case <-notifier.Closed():
+				logger.Debug("Txn status pubsub connection closed")
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
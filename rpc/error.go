@@ -28,6 +28,26 @@ var (
 		"(1) a block number range through `fromBlock` and `toBlock`",
 		"(2) a set of block hashes through `blockHash`",
 	)
+
+	errLogsPagingUnsupported = errors.New("paginated log query is not supported")
+
+	errLogsResumeUnsupported = errors.New("resumable logs subscription is not supported")
+
+	errLogsReplayFromRequired = errors.New("resumable logs subscription requires a position to replay from")
+
+	errLogsReplayAheadOfLatest = errors.New("position to replay logs from is ahead of the latest chain head")
+
+	errLogsReplayRangeTooLarge = errors.Errorf(
+		"position to replay logs from must be within the latest %v blocks or epochs", maxLogsReplayRange,
+	)
+
+	errReorgJournalUnsupported = errors.New("reorg journal is not supported")
+
+	errReorgJournalUnavailable = errors.New("reorg journal is temporarily unavailable")
+
+	errTxnTrackingUnsupported = errors.New("transaction tracking is not supported")
+
+	errTxnNotTracked = errors.New("transaction not submitted via this service or already expired")
 )
 
 func ErrExceedLogFilterBlockHashLimit(size int) error {
@@ -8,7 +8,9 @@ import (
 	"github.com/Conflux-Chain/confura/rpc/handler"
 	"github.com/Conflux-Chain/confura/store"
 	"github.com/Conflux-Chain/confura/util"
+	"github.com/Conflux-Chain/confura/util/accesslog"
 	"github.com/Conflux-Chain/confura/util/metrics"
+	"github.com/Conflux-Chain/confura/util/rpc/cache"
 	vfclient "github.com/Conflux-Chain/confura/virtualfilter/client"
 	"github.com/Conflux-Chain/go-conflux-sdk/types"
 	logutil "github.com/Conflux-Chain/go-conflux-util/log"
@@ -20,7 +22,9 @@ import (
 )
 
 const (
-	rpcMethodEthGetLogs = "eth_getLogs"
+	rpcMethodEthGetLogs      = "eth_getLogs"
+	rpcMethodEthGetLogsPaged = "eth_getLogsPaged"
+	rpcMethodEthSubscribe    = "eth_subscribe"
 
 	// The maximum number of percentile values to sample from each block's
 	// effective priority fees per gas in ascending order.
@@ -47,6 +51,10 @@ type EthAPIOption struct {
 	LogApiHandler       *handler.EthLogsApiHandler
 	TxnHandler          *handler.EthTxnHandler
 	VirtualFilterClient *vfclient.EthClient
+	ResponseCache       *cache.ResponseCache  // optional cache for immutable RPC responses
+	ReorgHandler        *handler.ReorgHandler // optional handler to expose chain reorgs
+	TxnTracker          *handler.TxnTracker   // optional tracker of the submitted transactions
+	AccessLogger        *accesslog.Logger     // optional logger to record structured access logs
 }
 
 // ethAPI provides Ethereum relative API within evm space according to:
@@ -57,13 +65,16 @@ type ethAPI struct {
 	provider         *node.EthClientProvider
 	inputBlockMetric metrics.InputBlockMetric
 	stateHandler     *handler.EthStateHandler
+	gasStation       *handler.EthGasStationHandler // gas station to estimate gas fees, could be nil
 	etPubsubLogger   *logutil.ErrorTolerantLogger
 
 	// return empty data before eSpace hardfork block number
 	hardforkBlockNumber web3Types.BlockNumber
 }
 
-func mustNewEthAPI(provider *node.EthClientProvider, option ...EthAPIOption) *ethAPI {
+func mustNewEthAPI(
+	provider *node.EthClientProvider, gasStation *handler.EthGasStationHandler, option ...EthAPIOption,
+) *ethAPI {
 	client, err := provider.GetClientRandom()
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to get eth client randomly")
@@ -87,6 +98,7 @@ func mustNewEthAPI(provider *node.EthClientProvider, option ...EthAPIOption) *et
 		EthAPIOption:        opt,
 		provider:            provider,
 		stateHandler:        handler.NewEthStateHandler(provider),
+		gasStation:          gasStation,
 		etPubsubLogger:      logutil.NewErrorTolerantLogger(logutil.DefaultETConfig),
 		hardforkBlockNumber: util.GetEthHardforkBlockNumber(*chainId),
 	}
@@ -105,7 +117,7 @@ func (api *ethAPI) GetBlockByHash(
 
 	if !store.EthStoreConfig().IsChainBlockDisabled() && !util.IsInterfaceValNil(api.StoreHandler) {
 		block, err := api.StoreHandler.GetBlockByHash(ctx, blockHash, fullTx)
-		metrics.Registry.RPC.StoreHit("eth_getBlockByHash", "store").Mark(err == nil)
+		api.collectHitStats(ctx, "eth_getBlockByHash", err == nil)
 		if err == nil {
 			logger.Debug("Loading eth data for eth_getBlockByHash hit in the store")
 			return block, nil
@@ -163,7 +175,7 @@ func (api *ethAPI) GetBlockByNumber(
 
 	if !store.EthStoreConfig().IsChainBlockDisabled() && !util.IsInterfaceValNil(api.StoreHandler) {
 		block, err := api.StoreHandler.GetBlockByNumber(ctx, &blockNum, fullTx)
-		metrics.Registry.RPC.StoreHit("eth_getBlockByNumber", "store").Mark(err == nil)
+		api.collectHitStats(ctx, "eth_getBlockByNumber", err == nil)
 		if err == nil {
 			logger.Debug("Loading eth data for eth_getBlockByNumber hit in the store")
 			return block, nil
@@ -214,6 +226,10 @@ func (api *ethAPI) ProtocolVersion(ctx context.Context) (string, error) {
 // GasPrice returns the current gas price in wei.
 func (api *ethAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
 	w3c := GetEthClientFromContext(ctx)
+	if gasPrice, _, ok := api.estimateFees(w3c); ok {
+		return (*hexutil.Big)(gasPrice), nil
+	}
+
 	gasPrice, err := w3c.Eth.GasPrice()
 	return (*hexutil.Big)(gasPrice), err
 }
@@ -296,7 +312,7 @@ func (api *ethAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (
 
 	if !store.EthStoreConfig().IsChainTxnDisabled() && !util.IsInterfaceValNil(api.StoreHandler) {
 		tx, err := api.StoreHandler.GetTransactionByHash(ctx, hash)
-		metrics.Registry.RPC.StoreHit("eth_getTransactionByHash", "store").Mark(err == nil)
+		api.collectHitStats(ctx, "eth_getTransactionByHash", err == nil)
 		if err == nil {
 			logger.Debug("Loading eth data for eth_getTransactionByHash hit in the store")
 			return tx, nil
@@ -318,7 +334,7 @@ func (api *ethAPI) GetTransactionReceipt(ctx context.Context, txHash common.Hash
 
 	if !store.EthStoreConfig().IsChainReceiptDisabled() && !util.IsInterfaceValNil(api.StoreHandler) {
 		tx, err := api.StoreHandler.GetTransactionReceipt(ctx, txHash)
-		metrics.Registry.RPC.StoreHit("eth_getTransactionReceipt", "store").Mark(err == nil)
+		api.collectHitStats(ctx, "eth_getTransactionReceipt", err == nil)
 		if err == nil {
 			logger.Debug("Loading eth data for eth_getTransactionReceipt hit in the ethstore")
 			return tx, nil
@@ -338,6 +354,35 @@ func (api *ethAPI) GetTransactionReceipt(ctx context.Context, txHash common.Hash
 	return receipt, err
 }
 
+// GetBlockReceipts returns the receipts of all transactions in the block with the given block
+// number or hash.
+func (api *ethAPI) GetBlockReceipts(
+	ctx context.Context, blockNumOrHash *web3Types.BlockNumberOrHash,
+) ([]*web3Types.Receipt, error) {
+	if blockNumOrHash == nil {
+		return nil, handler.ErrBlockNumOrHashRequired
+	}
+
+	logger := logrus.WithField("blockNumOrHash", blockNumOrHash)
+
+	if !store.EthStoreConfig().IsChainReceiptDisabled() && !util.IsInterfaceValNil(api.StoreHandler) {
+		receipts, err := api.StoreHandler.GetBlockReceipts(ctx, blockNumOrHash)
+		api.collectHitStats(ctx, "eth_getBlockReceipts", err == nil)
+		if err == nil {
+			logger.Debug("Loading eth data for eth_getBlockReceipts hit in the ethstore")
+			return receipts, nil
+		}
+
+		logger.WithError(err).Debug("Loading eth data for eth_getBlockReceipts missed from the ethstore")
+	}
+
+	logger.Debug("Delegating eth_getBlockReceipts rpc request to fullnode")
+
+	w3c := GetEthClientFromContext(ctx)
+	api.inputBlockMetric.Update2(blockNumOrHash, "eth_getBlockReceipts", w3c.Eth)
+	return w3c.Eth.BlockReceipts(blockNumOrHash)
+}
+
 // Returns pending transactions for a given account.
 func (api *ethAPI) GetAccountPendingTransactions(
 	ctx context.Context, addr common.Address, startNonce *hexutil.Big, limit *hexutil.Uint64,
@@ -361,16 +406,7 @@ func (api *ethAPI) getLogs(
 ) ([]web3Types.Log, error) {
 	metrics.UpdateEthRpcLogFilter(rpcMethod, w3c.Eth, fq)
 
-	flag, ok := ParseEthLogFilterType(fq)
-	if !ok {
-		return ethEmptyLogs, ErrInvalidEthLogFilter
-	}
-
-	if err := NormalizeEthLogFilter(w3c.Client, flag, fq, api.hardforkBlockNumber); err != nil {
-		return ethEmptyLogs, err
-	}
-
-	if err := ValidateEthLogFilter(flag, fq); err != nil {
+	if err := api.normalizeLogFilter(w3c, fq); err != nil {
 		return ethEmptyLogs, err
 	}
 
@@ -381,7 +417,7 @@ func (api *ethAPI) getLogs(
 
 	if api.LogApiHandler != nil {
 		logs, hitStore, err := api.LogApiHandler.GetLogs(ctx, w3c.Client.Eth, fq, rpcMethod)
-		metrics.Registry.RPC.StoreHit(rpcMethod, "store").Mark(hitStore)
+		api.collectHitStats(ctx, rpcMethod, hitStore)
 		return uniformEthLogs(logs), err
 	}
 
@@ -389,6 +425,66 @@ func (api *ethAPI) getLogs(
 	return w3c.Eth.Logs(*fq)
 }
 
+// GetLogsPaged returns a page of logs matching a given block range filter object, along with an
+// opaque cursor to fetch the next page if there are more logs.
+func (api *ethAPI) GetLogsPaged(
+	ctx context.Context, fq web3Types.FilterQuery, cursor *string, limit *hexutil.Uint64,
+) (*handler.LogsPage[web3Types.Log], error) {
+	if api.LogApiHandler == nil {
+		return nil, errLogsPagingUnsupported
+	}
+
+	// cursors are bound to the raw log filter before block tags resolved
+	filterHash := handler.HashLogsFilter(&fq)
+
+	w3c := GetEthClientFromContext(ctx)
+	metrics.UpdateEthRpcLogFilter(rpcMethodEthGetLogsPaged, w3c.Eth, &fq)
+
+	if err := api.normalizeLogFilter(w3c, &fq); err != nil {
+		return nil, err
+	}
+
+	// return empty directly if filter block range before eSpace hardfork
+	if fq.ToBlock != nil && *fq.ToBlock <= api.hardforkBlockNumber {
+		return &handler.LogsPage[web3Types.Log]{Logs: ethEmptyLogs}, nil
+	}
+
+	var pageCursor string
+	if cursor != nil {
+		pageCursor = *cursor
+	}
+
+	var pageLimit uint64
+	if limit != nil {
+		pageLimit = uint64(*limit)
+	}
+
+	page, hitStore, err := api.LogApiHandler.GetLogsPaged(
+		ctx, w3c.Client.Eth, &fq, filterHash, pageCursor, pageLimit, rpcMethodEthGetLogsPaged,
+	)
+	api.collectHitStats(ctx, rpcMethodEthGetLogsPaged, hitStore)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Logs = uniformEthLogs(page.Logs)
+	return page, nil
+}
+
+// normalizeLogFilter normalizes and validates the log filter.
+func (api *ethAPI) normalizeLogFilter(w3c *node.Web3goClient, fq *web3Types.FilterQuery) error {
+	flag, ok := ParseEthLogFilterType(fq)
+	if !ok {
+		return ErrInvalidEthLogFilter
+	}
+
+	if err := NormalizeEthLogFilter(w3c.Client, flag, fq, api.hardforkBlockNumber); err != nil {
+		return err
+	}
+
+	return ValidateEthLogFilter(flag, fq)
+}
+
 // GetBlockTransactionCountByHash returns the total number of transactions in the given block.
 func (api *ethAPI) GetBlockTransactionCountByHash(ctx context.Context, blockHash common.Hash) (*hexutil.Big, error) {
 	w3c := GetEthClientFromContext(ctx)
@@ -438,10 +534,32 @@ func (api *ethAPI) Mining(ctx context.Context) (bool, error) {
 // a priority fee, or "tip", to get a transaction included in the current block.
 func (api *ethAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
 	w3c := GetEthClientFromContext(ctx)
+	if _, priorityFee, ok := api.estimateFees(w3c); ok {
+		return (*hexutil.Big)(priorityFee), nil
+	}
+
 	priorityFee, err := w3c.Eth.MaxPriorityFeePerGas()
 	return (*hexutil.Big)(priorityFee), err
 }
 
+// estimateFees estimates gas fees from gas station if fee estimation enabled, and returns false
+// if not enabled or gas station unavailable, in which case gas fees are delegated to full node.
+func (api *ethAPI) estimateFees(w3c *node.Web3goClient) (gasPrice, priorityFee *big.Int, ok bool) {
+	if api.gasStation == nil || !api.gasStation.FeeEstimationEnabled() {
+		return nil, nil, false
+	}
+
+	gasPrice, priorityFee, err := api.gasStation.EstimateFees(w3c)
+	if err != nil {
+		logrus.WithError(err).Debug("Failed to estimate gas fees from gas station, fallback to full node")
+		metrics.Registry.RPC.Percentage("eth_gasStation", "fallback").Mark(true)
+		return nil, nil, false
+	}
+
+	metrics.Registry.RPC.Percentage("eth_gasStation", "fallback").Mark(false)
+	return gasPrice, priorityFee, true
+}
+
 // Accounts returns a list of addresses owned by client.
 func (api *ethAPI) Accounts(ctx context.Context) ([]common.Address, error) {
 	w3c := GetEthClientFromContext(ctx)
@@ -514,3 +632,8 @@ func (api *ethAPI) filterLogger(filter *web3Types.FilterQuery) *logrus.Entry {
 
 	return logger
 }
+
+func (api *ethAPI) collectHitStats(ctx context.Context, method string, hit bool) {
+	metrics.Registry.RPC.StoreHit(method, "store").Mark(hit)
+	accesslog.SetStoreHit(ctx, hit)
+}
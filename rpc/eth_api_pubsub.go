@@ -4,8 +4,10 @@ import (
 	"context"
 
 	"github.com/Conflux-Chain/confura/node"
+	"github.com/Conflux-Chain/confura/rpc/handler"
 	"github.com/Conflux-Chain/confura/util/metrics"
 	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/openweb3/go-rpc-provider"
 	"github.com/openweb3/web3go/types"
 	"github.com/pkg/errors"
@@ -80,10 +82,27 @@ func (api *ethAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	return rpcSub, nil
 }
 
+// ethLogsResumeOption is the option of resumable logs subscription, which replays the matched logs
+// since the specified block before live delivery, so that no log is lost due to dropped connection.
+type ethLogsResumeOption struct {
+	// block number to replay logs from
+	FromBlock *hexutil.Uint64 `json:"fromBlock"`
+	// resume token of the last received log to resume right after it, which overrides `fromBlock`
+	ResumeToken *string `json:"resumeToken"`
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *ethAPI) Logs(ctx context.Context, filter types.FilterQuery) (*rpc.Subscription, error) {
+// If resume option specified, the matched logs since the specified block will be replayed first,
+// and each log will be notified along with a resume token.
+func (api *ethAPI) Logs(
+	ctx context.Context, filter types.FilterQuery, option *ethLogsResumeOption,
+) (*rpc.Subscription, error) {
 	metrics.Registry.PubSub.InputLogFilter("eth").Mark(!isEmptyEthLogFilter(filter))
 
+	if option != nil && api.LogApiHandler == nil {
+		return &rpc.Subscription{}, errLogsResumeUnsupported
+	}
+
 	psCtx, supported, err := api.pubsubCtxFromContext(ctx)
 	if !supported {
 		logrus.WithError(err).Error("Logs pubsub notification unsupported")
@@ -111,6 +130,26 @@ func (api *ethAPI) Logs(ctx context.Context, filter types.FilterQuery) (*rpc.Sub
 
 	logger := logrus.WithField("rpcSubID", rpcSub.ID)
 
+	notifyReplayed := func(log *types.Log, token string) {
+		psCtx.notifier.Notify(rpcSub.ID, resumableLog{log, token})
+	}
+
+	var replay *logsReplay[types.Log]
+	if option != nil {
+		// replay logs after the live subscription established so as not to miss any log
+		replay, err = api.newLogsReplay(ctx, psCtx.eth, filter, option)
+		if err == nil {
+			// replay the first page to return error if any, e.g., invalid resume token
+			_, err = replay.replayOnce(notifyReplayed)
+		}
+
+		if err != nil {
+			dSub.unsubscribe()
+			logger.WithError(err).Debug("Failed to replay logs for resumable logs subscription")
+			return &rpc.Subscription{}, err
+		}
+	}
+
 	nodeName := rpcutil.Url2NodeName(psCtx.eth.URL)
 	counter := metrics.Registry.PubSub.Sessions("eth", "logs", nodeName)
 	counter.Inc(1)
@@ -119,11 +158,51 @@ func (api *ethAPI) Logs(ctx context.Context, filter types.FilterQuery) (*rpc.Sub
 		defer dSub.unsubscribe()
 		defer counter.Dec(1)
 
+		notifyLive := func(log *types.Log) error {
+			if replay == nil {
+				psCtx.notifier.Notify(rpcSub.ID, log)
+				return nil
+			}
+
+			if log.Removed { // chain reorg
+				replay.revert(log.BlockNumber)
+				psCtx.notifier.Notify(rpcSub.ID, log)
+				return nil
+			}
+
+			token, ok, err := replay.track(log.BlockNumber)
+			if err != nil {
+				return errors.WithMessage(err, "failed to track log for resumable logs subscription")
+			}
+
+			if ok { // not replayed yet
+				psCtx.notifier.Notify(rpcSub.ID, resumableLog{log, token})
+			}
+
+			return nil
+		}
+
+		if replay != nil {
+			err := replayWithLive(
+				replay, logsCh, ethLiveLogNumber, pubsubChannelBufferSize, notifyReplayed, notifyLive,
+			)
+			if err != nil {
+				logger.WithError(err).Info("Failed to replay logs for resumable logs subscription")
+				psCtx.rpcClient.Close()
+				return
+			}
+		}
+
 		for {
 			select {
 			case log := <-logsCh:
 				logger.WithField("log", log).Debug("Received new log from pubsub delegate")
-				psCtx.notifier.Notify(rpcSub.ID, log)
+
+				if err := notifyLive(log); err != nil {
+					logger.WithError(err).Info("Failed to notify live log")
+					psCtx.rpcClient.Close()
+					return
+				}
 
 			case err = <-dSub.err: // delegate subscription error
 				logger.WithError(err).Debug("Received error from logs pubsub delegate")
@@ -144,6 +223,55 @@ func (api *ethAPI) Logs(ctx context.Context, filter types.FilterQuery) (*rpc.Sub
 	return rpcSub, nil
 }
 
+// newLogsReplay creates a logs replay for resumable logs subscription, which replays the matched
+// logs up to the latest block of the subscribed full node.
+func (api *ethAPI) newLogsReplay(
+	ctx context.Context, eth *node.Web3goClient, filter types.FilterQuery, option *ethLogsResumeOption,
+) (*logsReplay[types.Log], error) {
+	latestBlock, err := eth.Eth.BlockNumber()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get latest block number")
+	}
+
+	// resume tokens are bound to the subscription filter
+	filterHash := handler.HashLogsFilter(&filter)
+
+	tracker := api.LogApiHandler.NewLogsResumeTracker(filterHash)
+	fromBlock, cursor, err := replayRange(
+		tracker, (*uint64)(option.FromBlock), option.ResumeToken, latestBlock.Uint64(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// replay could last longer than the subscription request
+	replayCtx := context.WithoutCancel(ctx)
+
+	return &logsReplay[types.Log]{
+		tracker: tracker,
+		fetch: func(pageCursor string, from, to uint64) (*handler.LogsPage[types.Log], error) {
+			fromBn, toBn := types.BlockNumber(from), types.BlockNumber(to)
+
+			replayFilter := filter
+			replayFilter.FromBlock, replayFilter.ToBlock, replayFilter.BlockHash = &fromBn, &toBn, nil
+
+			page, _, err := api.LogApiHandler.GetLogsPaged(
+				replayCtx, eth.Client.Eth, &replayFilter, filterHash, pageCursor, 0, rpcMethodEthSubscribe,
+			)
+			return page, err
+		},
+		numberOf:   func(log *types.Log) uint64 { return log.BlockNumber },
+		fromNumber: fromBlock,
+		cursor:     cursor,
+		toNumber:   latestBlock.Uint64(),
+	}, nil
+}
+
+// ethLiveLogNumber returns the block number of live log.
+func ethLiveLogNumber(log *types.Log) uint64 {
+	return log.BlockNumber
+}
+
 type epubsubContext struct {
 	notifier  *rpc.Notifier
 	rpcClient *rpc.Client
@@ -22,6 +22,26 @@ func getOrNewEthDelegateClient(eth *node.Web3goClient) *ethDelegateClient {
 	return client.(*ethDelegateClient)
 }
 
+// evictDelegateSubs evicts all delegated subscriptions of the client with error.
+func (client *ethDelegateClient) evictDelegateSubs(err error) (n int) {
+	client.delegateContexts.Range(func(key, value interface{}) bool {
+		n += value.(*delegateContext).evict(err)
+		return true
+	})
+
+	return n
+}
+
+// numDelegateSubs returns the number of all delegated subscriptions of the client.
+func (client *ethDelegateClient) numDelegateSubs() (n int) {
+	client.delegateContexts.Range(func(key, value interface{}) bool {
+		n += value.(*delegateContext).numDelegateSubs()
+		return true
+	})
+
+	return n
+}
+
 func (client *ethDelegateClient) getDelegateCtx(ctxName string) *delegateContext {
 	dctx, _ := client.delegateContexts.LoadOrStore(ctxName, newDelegateContext())
 	return dctx.(*delegateContext)
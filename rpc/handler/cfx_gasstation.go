@@ -38,6 +38,10 @@ func MustNewCfxGasStationHandlerFromViper(cp *node.CfxClientProvider) *CfxGasSta
 		return nil
 	}
 
+	if cfg.FeeEstimation.Enabled {
+		cfg.FeeEstimation.mustValidate()
+	}
+
 	// Get all clients in the http group.
 	clients, err := cp.GetClientsByGroup(node.GroupCfxHttp)
 	if err != nil {
@@ -320,3 +324,15 @@ func (h *CfxGasStationHandler) Suggest(cfx sdk.ClientOperator) (*types.Suggested
 
 	return assembleSuggestedGasFees(baseFeePerGas, &stats), nil
 }
+
+// EstimateFees estimates the gas price and priority fee per gas from gas station for the configured
+// level of urgency.
+func (h *CfxGasStationHandler) EstimateFees(cfx sdk.ClientOperator) (gasPrice, priorityFee *big.Int, err error) {
+	fees, err := h.Suggest(cfx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gasPrice, priorityFee = h.config.FeeEstimation.estimate(fees)
+	return gasPrice, priorityFee, nil
+}
@@ -26,6 +26,8 @@ var (
 	errEventLogsTooStale = errors.New("event logs are too stale (already pruned)")
 )
 
+const errResponseBodySizeTooLargeFmt = "result body size is too large with more than %d bytes, please narrow down your filter condition"
+
 func MustInitFromViper() {
 	var resrcLimit struct {
 		MaxGetLogsResponseBytes uint64 `default:"10485760"` // default 10MB
@@ -33,10 +35,25 @@ func MustInitFromViper() {
 	viper.MustUnmarshalKey("requestControl.resourceLimits", &resrcLimit)
 
 	maxGetLogsResponseBytes = resrcLimit.MaxGetLogsResponseBytes
-	errResponseBodySizeTooLarge = fmt.Errorf(
-		"result body size is too large with more than %d bytes, please narrow down your filter condition",
-		maxGetLogsResponseBytes,
-	)
+	errResponseBodySizeTooLarge = fmt.Errorf(errResponseBodySizeTooLargeFmt, maxGetLogsResponseBytes)
+}
+
+// getLogLimits returns the bounds for getLogs from context, e.g., customized by rate limit strategy.
+func getLogLimits(ctx context.Context) store.LogLimits {
+	limits := store.GetLogLimits(ctx)
+	if limits.MaxResponseBytes == 0 {
+		limits.MaxResponseBytes = maxGetLogsResponseBytes
+	}
+
+	return limits
+}
+
+func newResponseBodySizeTooLargeError(maxBytes uint64) error {
+	if maxBytes == maxGetLogsResponseBytes {
+		return errResponseBodySizeTooLarge
+	}
+
+	return fmt.Errorf(errResponseBodySizeTooLargeFmt, maxBytes)
 }
 
 // CfxLogsApiHandler RPC handler to get core space event logs from store or fullnode.
@@ -88,6 +105,52 @@ func (handler *CfxLogsApiHandler) GetLogs(
 	}
 }
 
+// GetLogsPaged returns a page of event logs matching the normalized epoch range log filter,
+// starting from the position of the cursor if provided. Note, the cursor is bound to the hash
+// of the raw log filter from client.
+func (handler *CfxLogsApiHandler) GetLogsPaged(
+	ctx context.Context,
+	cfx sdk.ClientOperator,
+	filter *types.LogFilter,
+	filterHash string,
+	cursor string,
+	limit uint64,
+	delegatedRpcMethod string,
+) (*LogsPage[types.Log], bool, error) {
+	if len(filter.BlockHashes) > 0 || filter.FromBlock != nil || filter.ToBlock != nil {
+		return nil, false, errCfxLogsPagingEpochRangeRequired
+	}
+
+	epochRange, ok := calculateEpochRange(filter)
+	if !ok {
+		return nil, false, errCfxLogsPagingEpochRangeRequired
+	}
+
+	return GetLogsPage(ctx, handler.ms, &LogsPageQuery[types.Log]{
+		FilterHash: filterHash,
+		From:       epochRange.From,
+		To:         epochRange.To,
+		Cursor:     cursor,
+		Limit:      normalizeLogsPageLimit(limit, getLogLimits(ctx).MaxLogs),
+		GetLogs: func(from, to uint64) ([]types.Log, bool, error) {
+			windowFilter := *filter
+			windowFilter.FromEpoch = types.NewEpochNumberUint64(from)
+			windowFilter.ToEpoch = types.NewEpochNumberUint64(to)
+
+			return handler.GetLogs(ctx, cfx, &windowFilter, delegatedRpcMethod)
+		},
+		SuggestedTo: func(err error) (uint64, bool) {
+			var oversizedErr *store.SuggestedFilterOversizedError[store.SuggestedEpochRange]
+			if errors.As(err, &oversizedErr) {
+				return oversizedErr.SuggestedRange.To, true
+			}
+
+			return 0, false
+		},
+		NumberOf: func(log *types.Log) uint64 { return log.EpochNumber.ToInt().Uint64() },
+	})
+}
+
 func (handler *CfxLogsApiHandler) getLogsReorgGuard(
 	ctx context.Context,
 	cfx sdk.ClientOperator,
@@ -119,6 +182,7 @@ func (handler *CfxLogsApiHandler) getLogsReorgGuard(
 	var logs []types.Log
 	var accumulator int
 
+	limits := getLogLimits(ctx)
 	useBoundCheck := handler.RequireBoundChecks(filter)
 	if len(dbFilters) > 0 {
 		if useBoundCheck {
@@ -142,8 +206,8 @@ func (handler *CfxLogsApiHandler) getLogsReorgGuard(
 		// succeeded to get logs from database
 		if err == nil {
 			for _, v := range dbLogs {
-				if accumulator += len(v.Extra); useBoundCheck && uint64(accumulator) > maxGetLogsResponseBytes {
-					return nil, false, newSuggestedBodyBytesOversizedError(cfx, filter, v)
+				if accumulator += len(v.Extra); useBoundCheck && uint64(accumulator) > limits.MaxResponseBytes {
+					return nil, false, newSuggestedBodyBytesOversizedError(cfx, filter, v, limits.MaxResponseBytes)
 				}
 
 				log, _ := v.ToCfxLog()
@@ -180,7 +244,7 @@ func (handler *CfxLogsApiHandler) getLogsReorgGuard(
 		}
 
 		// ensure fullnode delegation is rational
-		if err := handler.checkFullnodeLogFilter(originalFilter); err != nil {
+		if err := handler.checkFullnodeLogFilter(originalFilter, limits); err != nil {
 			return nil, false, err
 		}
 
@@ -190,8 +254,8 @@ func (handler *CfxLogsApiHandler) getLogsReorgGuard(
 		}
 
 		for i := range fnLogs {
-			if accumulator += len(fnLogs[i].Data); useBoundCheck && uint64(accumulator) > maxGetLogsResponseBytes {
-				return nil, false, newSuggestedBodyBytesOversizedError(cfx, filter, &fnLogs[i])
+			if accumulator += len(fnLogs[i].Data); useBoundCheck && uint64(accumulator) > limits.MaxResponseBytes {
+				return nil, false, newSuggestedBodyBytesOversizedError(cfx, filter, &fnLogs[i], limits.MaxResponseBytes)
 			}
 		}
 		logs = append(logs, fnLogs...)
@@ -205,7 +269,7 @@ func (handler *CfxLogsApiHandler) getLogsReorgGuard(
 		}
 
 		// ensure split log filter for fullnode is rational
-		if err := handler.checkFullnodeLogFilter(fnFilter); err != nil {
+		if err := handler.checkFullnodeLogFilter(fnFilter, limits); err != nil {
 			return nil, false, err
 		}
 
@@ -215,20 +279,20 @@ func (handler *CfxLogsApiHandler) getLogsReorgGuard(
 		}
 
 		for i := range fnLogs {
-			if accumulator += len(fnLogs[i].Data); useBoundCheck && uint64(accumulator) > maxGetLogsResponseBytes {
-				return nil, false, newSuggestedBodyBytesOversizedError(cfx, filter, &fnLogs[i])
+			if accumulator += len(fnLogs[i].Data); useBoundCheck && uint64(accumulator) > limits.MaxResponseBytes {
+				return nil, false, newSuggestedBodyBytesOversizedError(cfx, filter, &fnLogs[i], limits.MaxResponseBytes)
 			}
 		}
 		logs = append(logs, fnLogs...)
 	}
 
 	// ensure result set never oversized
-	if useBoundCheck && uint64(len(logs)) > store.MaxLogLimit {
-		return nil, false, newSuggestedResultSetOversizedError(cfx, filter, &logs[store.MaxLogLimit])
+	if useBoundCheck && uint64(len(logs)) > limits.MaxLogs {
+		return nil, false, newSuggestedResultSetOversizedError(cfx, filter, &logs[limits.MaxLogs], limits.MaxLogs)
 	}
 
 	// Rare case: log context information for diagnostic purposes if the result exceeds limits.
-	if uint64(len(logs)) > store.MaxLogLimit || uint64(accumulator) > maxGetLogsResponseBytes {
+	if uint64(len(logs)) > limits.MaxLogs || uint64(accumulator) > limits.MaxResponseBytes {
 		logrus.WithFields(logrus.Fields{
 			"logFilter":         filter,
 			"databaseFilters":   dbFilters,
@@ -440,12 +504,12 @@ func (handler *CfxLogsApiHandler) splitLogFilterByEpochRange(
 // checkFullnodeLogFilter checks if the log filter is rational for fullnode delegation.
 //
 // Note this function assumes the log filter is valid and normalized.
-func (handler *CfxLogsApiHandler) checkFullnodeLogFilter(filter *types.LogFilter) error {
+func (handler *CfxLogsApiHandler) checkFullnodeLogFilter(filter *types.LogFilter, limits store.LogLimits) error {
 	// Epoch range bound checking
 	if epochRange, valid := calculateEpochRange(filter); valid {
 		numEpochs := epochRange.To - epochRange.From + 1
-		if numEpochs > uint64(store.MaxLogEpochRange) {
-			epochRange.To = epochRange.From + uint64(store.MaxLogEpochRange) - 1
+		if numEpochs > limits.MaxEpochRange {
+			epochRange.To = epochRange.From + limits.MaxEpochRange - 1
 			suggestedRange := store.NewSuggestedEpochRange(epochRange.From, epochRange.To)
 			return store.NewSuggestedFilterQuerySetTooLargeError(&suggestedRange)
 		}
@@ -454,8 +518,8 @@ func (handler *CfxLogsApiHandler) checkFullnodeLogFilter(filter *types.LogFilter
 	// Block range bound checking
 	if blockRange, valid := calculateCfxBlockRange(filter); valid {
 		numBlocks := blockRange.To - blockRange.From + 1
-		if numBlocks > uint64(store.MaxLogBlockRange) {
-			blockRange.To = blockRange.From + uint64(store.MaxLogBlockRange) - 1
+		if numBlocks > limits.MaxBlockRange {
+			blockRange.To = blockRange.From + limits.MaxBlockRange - 1
 			suggestedRange := store.SuggestedBlockRange{RangeUint64: blockRange}
 			return store.NewSuggestedFilterQuerySetTooLargeError(&suggestedRange)
 		}
@@ -498,13 +562,15 @@ func (handler *CfxLogsApiHandler) RequireBoundChecks(filter *types.LogFilter) bo
 }
 
 func newSuggestedBodyBytesOversizedError[T types.Log | store.Log](
-	cfx sdk.ClientOperator, filter *types.LogFilter, exceedingLog *T) error {
-	return newSuggestedFilterOversizedError[T](errResponseBodySizeTooLarge, cfx, filter, exceedingLog)
+	cfx sdk.ClientOperator, filter *types.LogFilter, exceedingLog *T, maxBytes uint64) error {
+	inner := newResponseBodySizeTooLargeError(maxBytes)
+	return newSuggestedFilterOversizedError[T](inner, cfx, filter, exceedingLog)
 }
 
 func newSuggestedResultSetOversizedError[T types.Log | store.Log](
-	cfx sdk.ClientOperator, filter *types.LogFilter, exceedingLog *T) error {
-	return newSuggestedFilterOversizedError[T](store.ErrFilterResultSetTooLarge, cfx, filter, exceedingLog)
+	cfx sdk.ClientOperator, filter *types.LogFilter, exceedingLog *T, maxLogs uint64) error {
+	inner := store.NewFilterResultSetTooLargeError(maxLogs)
+	return newSuggestedFilterOversizedError[T](inner, cfx, filter, exceedingLog)
 }
 
 func newSuggestedFilterOversizedError[T types.Log | store.Log](
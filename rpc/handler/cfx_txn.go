@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"math/big"
 	"strings"
 
 	"github.com/Conflux-Chain/confura/node"
@@ -12,6 +13,7 @@ import (
 	"github.com/Conflux-Chain/go-conflux-util/viper"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
+	w3rpc "github.com/openweb3/go-rpc-provider"
 	"github.com/openweb3/go-rpc-provider/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -24,9 +26,10 @@ type CfxTxnHandler struct {
 	nclient  *rpc.Client         // node RPC client
 	clients  *util.ConcurrentMap // sdk clients: node name => RPC client
 	relayTxn bool                // whether to relay to other group nodes while sending txn
+	tracker  *TxnTracker         // optional tracker to track the submitted transactions
 }
 
-func MustNewCfxTxnHandler(relayer relay.TxnRelayer) *CfxTxnHandler {
+func MustNewCfxTxnHandler(relayer relay.TxnRelayer, tracker *TxnTracker) *CfxTxnHandler {
 	cfg := struct{ RelayTxn bool }{}
 	viper.MustUnmarshalKey("relay", &cfg)
 
@@ -47,6 +50,7 @@ func MustNewCfxTxnHandler(relayer relay.TxnRelayer) *CfxTxnHandler {
 		nclient:  nodeRpcClient,
 		clients:  &util.ConcurrentMap{},
 		relayTxn: cfg.RelayTxn,
+		tracker:  tracker,
 	}
 }
 
@@ -66,6 +70,10 @@ func (h *CfxTxnHandler) SendRawTxn(cfx sdk.ClientOperator, group node.Group, sig
 		h.replicateRawTxnSendingByGroup(cfx, group, signedTx)
 	}
 
+	if h.tracker != nil {
+		h.tracker.track(txHash.ToCommonHash().Hex(), &cfxTxnProber{cfx: cfx, hash: txHash})
+	}
+
 	return txHash, err
 }
 
@@ -120,3 +128,84 @@ func (h *CfxTxnHandler) replicateRawTxnSendingToNodes(cfx sdk.ClientOperator, no
 		}
 	}
 }
+
+// cfxTxnProber probes core space transaction status from the full node which it is submitted to.
+type cfxTxnProber struct {
+	cfx  sdk.ClientOperator
+	hash types.Hash
+
+	// sender and nonce to tell if transaction replaced, which are known once seen in txpool
+	from  *types.Address
+	nonce *big.Int
+}
+
+func (p *cfxTxnProber) nodeName() string {
+	return rpcutil.Url2NodeName(p.cfx.GetNodeURL())
+}
+
+// probeBatch probes the receipts at first, then the unexecuted transactions from txpool, and
+// finally the sender nonces of transactions not found anywhere, each in a batch RPC request.
+func (p *cfxTxnProber) probeBatch(probers []txnProber) ([]txnProbe, []error) {
+	results, errs := make([]txnProbe, len(probers)), make([]error, len(probers))
+
+	cps, indexes := make([]*cfxTxnProber, len(probers)), make([]int, len(probers))
+	for i := range probers {
+		cps[i], indexes[i] = probers[i].(*cfxTxnProber), i
+	}
+
+	receipts := make([]*types.TransactionReceipt, len(cps))
+	indexes = batchProbe(p.cfx.BatchCallRPC, indexes, errs, "failed to get transaction receipt", func(i int) w3rpc.BatchElem {
+		return w3rpc.BatchElem{Method: "cfx_getTransactionReceipt", Args: []interface{}{cps[i].hash}, Result: &receipts[i]}
+	})
+
+	var unexecuted []int
+	for _, i := range indexes {
+		receipt := receipts[i]
+		if receipt == nil {
+			unexecuted = append(unexecuted, i)
+			continue
+		}
+
+		// outcome status: 0 for success, 1 for failure, 2 for skipped
+		status := TxnStatusMined
+		if receipt.OutcomeStatus != 0 {
+			status = TxnStatusFailed
+		}
+
+		results[i] = txnProbe{status: status, blockHash: receipt.BlockHash.String()}
+		if receipt.EpochNumber != nil {
+			results[i].blockNumber = uint64(*receipt.EpochNumber)
+		}
+	}
+
+	txns := make([]*types.Transaction, len(cps))
+	indexes = batchProbe(p.cfx.BatchCallRPC, unexecuted, errs, "failed to get transaction", func(i int) w3rpc.BatchElem {
+		return w3rpc.BatchElem{Method: "cfx_getTransactionByHash", Args: []interface{}{cps[i].hash}, Result: &txns[i]}
+	})
+
+	var unseen []int
+	for _, i := range indexes {
+		if txn := txns[i]; txn != nil { // in txpool, or packed but not executed yet
+			if txn.Nonce != nil {
+				cps[i].from, cps[i].nonce = &txn.From, txn.Nonce.ToInt()
+			}
+
+			results[i] = txnProbe{status: TxnStatusPending}
+		} else if cps[i].from != nil {
+			unseen = append(unseen, i)
+		}
+	}
+
+	nonces := make([]*hexutil.Big, len(cps))
+	indexes = batchProbe(p.cfx.BatchCallRPC, unseen, errs, "failed to get nonce", func(i int) w3rpc.BatchElem {
+		return w3rpc.BatchElem{Method: "cfx_getNextNonce", Args: []interface{}{*cps[i].from}, Result: &nonces[i]}
+	})
+
+	for _, i := range indexes {
+		if nonces[i] != nil && nonces[i].ToInt().Cmp(cps[i].nonce) > 0 { // nonce used by another transaction
+			results[i] = txnProbe{status: TxnStatusReplaced}
+		}
+	}
+
+	return results, errs
+}
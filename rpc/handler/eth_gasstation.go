@@ -33,6 +33,10 @@ func MustNewEthGasStationHandlerFromViper(cp *node.EthClientProvider) *EthGasSta
 		return nil
 	}
 
+	if cfg.FeeEstimation.Enabled {
+		cfg.FeeEstimation.mustValidate()
+	}
+
 	// Get all clients in the http group.
 	clients, err := cp.GetClientsByGroup(node.GroupEthHttp)
 	if err != nil {
@@ -259,3 +263,15 @@ func (h *EthGasStationHandler) Suggest(eth *node.Web3goClient) (*types.Suggested
 
 	return assembleSuggestedGasFees(baseFeePerGas, &stats), nil
 }
+
+// EstimateFees estimates the gas price and priority fee per gas from gas station for the configured
+// level of urgency.
+func (h *EthGasStationHandler) EstimateFees(eth *node.Web3goClient) (gasPrice, priorityFee *big.Int, err error) {
+	fees, err := h.Suggest(eth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gasPrice, priorityFee = h.config.FeeEstimation.estimate(fees)
+	return gasPrice, priorityFee, nil
+}
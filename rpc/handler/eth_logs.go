@@ -64,6 +64,48 @@ func (handler *EthLogsApiHandler) GetLogs(
 	}
 }
 
+// GetLogsPaged returns a page of event logs matching the normalized block range log filter,
+// starting from the position of the cursor if provided. Note, the cursor is bound to the hash
+// of the raw log filter from client.
+func (handler *EthLogsApiHandler) GetLogsPaged(
+	ctx context.Context,
+	eth *client.RpcEthClient,
+	filter *types.FilterQuery,
+	filterHash string,
+	cursor string,
+	limit uint64,
+	delegatedRpcMethod string,
+) (*LogsPage[types.Log], bool, error) {
+	if filter.BlockHash != nil || filter.FromBlock == nil || filter.ToBlock == nil {
+		return nil, false, errEthLogsPagingBlockRangeRequired
+	}
+
+	return GetLogsPage(ctx, handler.ms, &LogsPageQuery[types.Log]{
+		FilterHash: filterHash,
+		From:       uint64(*filter.FromBlock),
+		To:         uint64(*filter.ToBlock),
+		Cursor:     cursor,
+		Limit:      normalizeLogsPageLimit(limit, getLogLimits(ctx).MaxLogs),
+		GetLogs: func(from, to uint64) ([]types.Log, bool, error) {
+			fromBn, toBn := types.BlockNumber(from), types.BlockNumber(to)
+
+			windowFilter := *filter
+			windowFilter.FromBlock, windowFilter.ToBlock = &fromBn, &toBn
+
+			return handler.GetLogs(ctx, eth, &windowFilter, delegatedRpcMethod)
+		},
+		SuggestedTo: func(err error) (uint64, bool) {
+			var oversizedErr *store.SuggestedFilterOversizedError[store.SuggestedBlockRange]
+			if errors.As(err, &oversizedErr) {
+				return oversizedErr.SuggestedRange.To, true
+			}
+
+			return 0, false
+		},
+		NumberOf: func(log *types.Log) uint64 { return log.BlockNumber },
+	})
+}
+
 func (handler *EthLogsApiHandler) getLogsReorgGuard(
 	ctx context.Context,
 	eth *client.RpcEthClient,
@@ -90,6 +132,7 @@ func (handler *EthLogsApiHandler) getLogsReorgGuard(
 	var logs []types.Log
 	var accumulator int
 
+	limits := getLogLimits(ctx)
 	useBoundCheck := handler.RequiresBoundChecks(filter)
 	if dbFilter != nil {
 		if useBoundCheck {
@@ -109,8 +152,8 @@ func (handler *EthLogsApiHandler) getLogsReorgGuard(
 		}
 
 		for _, v := range dbLogs {
-			if accumulator += len(v.Extra); useBoundCheck && uint64(accumulator) > maxGetLogsResponseBytes {
-				return nil, false, handler.newSuggestedBodyBytesOversizedError(filter, v.BlockNumber)
+			if accumulator += len(v.Extra); useBoundCheck && uint64(accumulator) > limits.MaxResponseBytes {
+				return nil, false, handler.newSuggestedBodyBytesOversizedError(filter, v.BlockNumber, limits.MaxResponseBytes)
 			}
 
 			cfxLog, ext := v.ToCfxLog()
@@ -126,7 +169,7 @@ func (handler *EthLogsApiHandler) getLogsReorgGuard(
 		}
 
 		// ensure fullnode delegation is rational
-		if err := handler.checkFnEthLogFilter(fnFilter); err != nil {
+		if err := handler.checkFnEthLogFilter(fnFilter, limits); err != nil {
 			return nil, false, err
 		}
 
@@ -136,21 +179,21 @@ func (handler *EthLogsApiHandler) getLogsReorgGuard(
 		}
 
 		for i := range fnLogs {
-			if accumulator += len(fnLogs[i].Data); useBoundCheck && uint64(accumulator) > maxGetLogsResponseBytes {
-				return nil, false, handler.newSuggestedBodyBytesOversizedError(filter, fnLogs[i].BlockNumber)
+			if accumulator += len(fnLogs[i].Data); useBoundCheck && uint64(accumulator) > limits.MaxResponseBytes {
+				return nil, false, handler.newSuggestedBodyBytesOversizedError(filter, fnLogs[i].BlockNumber, limits.MaxResponseBytes)
 			}
 		}
 		logs = append(logs, fnLogs...)
 	}
 
 	// ensure result set never oversized
-	if useBoundCheck && uint64(len(logs)) > store.MaxLogLimit {
-		exceedingBlockNum := logs[store.MaxLogLimit].BlockNumber
-		return nil, false, handler.newSuggestedResultSetOversizedError(filter, exceedingBlockNum)
+	if useBoundCheck && uint64(len(logs)) > limits.MaxLogs {
+		exceedingBlockNum := logs[limits.MaxLogs].BlockNumber
+		return nil, false, handler.newSuggestedResultSetOversizedError(filter, exceedingBlockNum, limits.MaxLogs)
 	}
 
 	// Rare case: log context information for diagnostic purposes if the result exceeds limits.
-	if uint64(len(logs)) > store.MaxLogLimit || uint64(accumulator) > maxGetLogsResponseBytes {
+	if uint64(len(logs)) > limits.MaxLogs || uint64(accumulator) > limits.MaxResponseBytes {
 		logrus.WithFields(logrus.Fields{
 			"logFilter":         filter,
 			"databaseFilter":    dbFilter,
@@ -276,11 +319,11 @@ func (handler *EthLogsApiHandler) GetNetworkId(eth *client.RpcEthClient) (uint32
 // checkFnEthLogFilter checks if the eth log filter is rational for fullnode delegation.
 //
 // Note this function assumes the log filter is valid and normalized.
-func (handler *EthLogsApiHandler) checkFnEthLogFilter(filter *types.FilterQuery) error {
+func (handler *EthLogsApiHandler) checkFnEthLogFilter(filter *types.FilterQuery, limits store.LogLimits) error {
 	if blockRange, valid := calculateEthBlockRange(filter); valid {
 		numBlocks := blockRange.To - blockRange.From + 1
-		if numBlocks > uint64(store.MaxLogBlockRange) {
-			blockRange.To = blockRange.From + uint64(store.MaxLogBlockRange) - 1
+		if numBlocks > limits.MaxBlockRange {
+			blockRange.To = blockRange.From + limits.MaxBlockRange - 1
 			suggestedRange := store.SuggestedBlockRange{RangeUint64: blockRange}
 			return store.NewSuggestedFilterQuerySetTooLargeError(&suggestedRange)
 		}
@@ -297,12 +340,16 @@ func (handler *EthLogsApiHandler) RequiresBoundChecks(filter *types.FilterQuery)
 	return false
 }
 
-func (handler *EthLogsApiHandler) newSuggestedResultSetOversizedError(filter *types.FilterQuery, exceedingBlockNum uint64) error {
-	return handler.newSuggestedFilterOversizedError(store.ErrFilterResultSetTooLarge, filter, exceedingBlockNum)
+func (handler *EthLogsApiHandler) newSuggestedResultSetOversizedError(
+	filter *types.FilterQuery, exceedingBlockNum, maxLogs uint64) error {
+	inner := store.NewFilterResultSetTooLargeError(maxLogs)
+	return handler.newSuggestedFilterOversizedError(inner, filter, exceedingBlockNum)
 }
 
-func (handler *EthLogsApiHandler) newSuggestedBodyBytesOversizedError(filter *types.FilterQuery, exceedingBlockNum uint64) error {
-	return handler.newSuggestedFilterOversizedError(errResponseBodySizeTooLarge, filter, exceedingBlockNum)
+func (handler *EthLogsApiHandler) newSuggestedBodyBytesOversizedError(
+	filter *types.FilterQuery, exceedingBlockNum, maxBytes uint64) error {
+	inner := newResponseBodySizeTooLargeError(maxBytes)
+	return handler.newSuggestedFilterOversizedError(inner, filter, exceedingBlockNum)
 }
 
 func (handler *EthLogsApiHandler) newSuggestedFilterOversizedError(inner error, filter *types.FilterQuery, exceedingBlockNum uint64) error {
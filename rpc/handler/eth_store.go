@@ -8,10 +8,17 @@ import (
 	"github.com/Conflux-Chain/confura/store"
 	"github.com/Conflux-Chain/confura/util"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/openweb3/go-rpc-provider"
 	web3Types "github.com/openweb3/web3go/types"
 	"github.com/sirupsen/logrus"
 )
 
+// ErrBlockNumOrHashRequired is returned if block number or hash is not specified, e.g., missing or null.
+var ErrBlockNumOrHashRequired = &rpc.JsonError{
+	Code:    -32602, // invalid params
+	Message: "invalid params: block number or hash required",
+}
+
 // EthStoreHandler RPC handler to get block/txn/receipt data from store.
 type EthStoreHandler struct {
 	store store.Readable
@@ -150,3 +157,48 @@ func (h *EthStoreHandler) GetTransactionReceipt(ctx context.Context, txHash comm
 
 	return nil, err
 }
+
+func (h *EthStoreHandler) GetBlockReceipts(ctx context.Context, blockNumOrHash *web3Types.BlockNumberOrHash) (
+	receipts []*web3Types.Receipt, err error,
+) {
+	if blockNumOrHash == nil {
+		return nil, ErrBlockNumOrHashRequired
+	}
+
+	logger := logrus.WithField("blockNumOrHash", blockNumOrHash)
+
+	var srcpts []*store.TransactionReceipt
+	if blockNum, ok := blockNumOrHash.Number(); ok {
+		if blockNum <= 0 {
+			return nil, store.ErrUnsupported
+		}
+
+		// block number is the same as epoch number on eSpace
+		srcpts, err = h.store.GetReceiptsByEpoch(ctx, uint64(blockNum))
+	} else if blockHash, ok := blockNumOrHash.Hash(); ok {
+		var sblocksum *store.BlockSummary
+		sblocksum, err = h.store.GetBlockSummaryByHash(ctx, cfxbridge.ConvertHash(blockHash))
+		if err == nil {
+			srcpts, err = h.store.GetReceiptsByEpoch(ctx, sblocksum.CfxBlockSummary.EpochNumber.ToInt().Uint64())
+		}
+	} else {
+		return nil, store.ErrUnsupported
+	}
+
+	if err != nil {
+		logger.WithError(err).Debug("ETH handler failed to handle GetBlockReceipts")
+
+		if !util.IsInterfaceValNil(h.next) {
+			return h.next.GetBlockReceipts(ctx, blockNumOrHash)
+		}
+
+		return nil, err
+	}
+
+	receipts = make([]*web3Types.Receipt, len(srcpts))
+	for i, rcpt := range srcpts {
+		receipts[i] = ethbridge.ConvertReceipt(rcpt.CfxReceipt, rcpt.Extra)
+	}
+
+	return receipts, nil
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEthStoreGetBlockReceiptsWithoutBlockNumOrHash(t *testing.T) {
+	h := NewEthStoreHandler(nil, nil)
+
+	receipts, err := h.GetBlockReceipts(context.Background(), nil)
+	assert.Nil(t, receipts)
+	assert.Equal(t, ErrBlockNumOrHashRequired, err)
+}
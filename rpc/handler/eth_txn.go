@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"strings"
 
 	"github.com/Conflux-Chain/confura/node"
@@ -11,8 +12,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
+	w3rpc "github.com/openweb3/go-rpc-provider"
 	"github.com/openweb3/go-rpc-provider/utils"
 	"github.com/openweb3/web3go"
+	web3Types "github.com/openweb3/web3go/types"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,9 +25,10 @@ type EthTxnHandler struct {
 	nclient  *rpc.Client         // node RPC client
 	clients  *util.ConcurrentMap // sdk clients: node name => RPC client
 	relayTxn bool                // whether to relay to other group nodes while sending txn
+	tracker  *TxnTracker         // optional tracker to track the submitted transactions
 }
 
-func MustNewEthTxnHandler(relayer relay.TxnRelayer) *EthTxnHandler {
+func MustNewEthTxnHandler(relayer relay.TxnRelayer, tracker *TxnTracker) *EthTxnHandler {
 	cfg := struct{ RelayTxn bool }{}
 	viper.MustUnmarshalKey("relay", &cfg)
 
@@ -45,6 +49,7 @@ func MustNewEthTxnHandler(relayer relay.TxnRelayer) *EthTxnHandler {
 		nclient:  nodeRpcClient,
 		clients:  &util.ConcurrentMap{},
 		relayTxn: cfg.RelayTxn,
+		tracker:  tracker,
 	}
 }
 
@@ -64,6 +69,10 @@ func (h *EthTxnHandler) SendRawTxn(w3c *node.Web3goClient, group node.Group, sig
 		h.replicateRawTxnSendingByGroup(w3c, group, signedTx)
 	}
 
+	if h.tracker != nil {
+		h.tracker.track(txHash.Hex(), &ethTxnProber{w3c: w3c, hash: txHash})
+	}
+
 	return txHash, err
 }
 
@@ -118,3 +127,89 @@ func (h *EthTxnHandler) replicateRawTxnSendingToNodes(w3c *node.Web3goClient, no
 		}
 	}
 }
+
+// ethTxnProber probes evm space transaction status from the full node which it is submitted to.
+type ethTxnProber struct {
+	w3c  *node.Web3goClient
+	hash common.Hash
+
+	// sender and nonce to tell if transaction replaced, which are known once seen in txpool
+	from  *common.Address
+	nonce uint64
+}
+
+func (p *ethTxnProber) nodeName() string {
+	return p.w3c.NodeName()
+}
+
+// probeBatch probes the receipts at first, then the unmined transactions from txpool, and finally
+// the sender nonces of transactions not found anywhere, each in a batch RPC request.
+func (p *ethTxnProber) probeBatch(probers []txnProber) ([]txnProbe, []error) {
+	results, errs := make([]txnProbe, len(probers)), make([]error, len(probers))
+
+	eps, indexes := make([]*ethTxnProber, len(probers)), make([]int, len(probers))
+	for i := range probers {
+		eps[i], indexes[i] = probers[i].(*ethTxnProber), i
+	}
+
+	batchCall := func(elems []w3rpc.BatchElem) error {
+		return p.w3c.Provider().BatchCallContext(context.Background(), elems)
+	}
+
+	receipts := make([]*web3Types.Receipt, len(eps))
+	indexes = batchProbe(batchCall, indexes, errs, "failed to get transaction receipt", func(i int) w3rpc.BatchElem {
+		return w3rpc.BatchElem{Method: "eth_getTransactionReceipt", Args: []interface{}{eps[i].hash}, Result: &receipts[i]}
+	})
+
+	var unmined []int
+	for _, i := range indexes {
+		receipt := receipts[i]
+		if receipt == nil {
+			unmined = append(unmined, i)
+			continue
+		}
+
+		status := TxnStatusMined
+		if receipt.Status == nil || *receipt.Status != 1 {
+			status = TxnStatusFailed
+		}
+
+		results[i] = txnProbe{
+			status:      status,
+			blockHash:   receipt.BlockHash.Hex(),
+			blockNumber: receipt.BlockNumber,
+		}
+	}
+
+	txns := make([]*web3Types.TransactionDetail, len(eps))
+	indexes = batchProbe(batchCall, unmined, errs, "failed to get transaction", func(i int) w3rpc.BatchElem {
+		return w3rpc.BatchElem{Method: "eth_getTransactionByHash", Args: []interface{}{eps[i].hash}, Result: &txns[i]}
+	})
+
+	var unseen []int
+	for _, i := range indexes {
+		if txn := txns[i]; txn != nil { // in txpool, or mined but receipt not available yet
+			eps[i].from, eps[i].nonce = &txn.From, txn.Nonce
+			results[i] = txnProbe{status: TxnStatusPending}
+		} else if eps[i].from != nil {
+			unseen = append(unseen, i)
+		}
+	}
+
+	nonces := make([]*hexutil.Big, len(eps))
+	indexes = batchProbe(batchCall, unseen, errs, "failed to get nonce", func(i int) w3rpc.BatchElem {
+		return w3rpc.BatchElem{
+			Method: "eth_getTransactionCount",
+			Args:   []interface{}{*eps[i].from, web3Types.LatestBlockNumber},
+			Result: &nonces[i],
+		}
+	})
+
+	for _, i := range indexes {
+		if nonces[i] != nil && nonces[i].ToInt().Uint64() > eps[i].nonce { // nonce used by another transaction
+			results[i] = txnProbe{status: TxnStatusReplaced}
+		}
+	}
+
+	return results, errs
+}
@@ -50,6 +50,63 @@ type GasStationConfig struct {
 	HistoricalPeekCount int `default:"100"`
 	// Percentiles for average txn gas price mapped to three levels of urgency (`low`, `medium` and `high`).
 	Percentiles [3]float64 `default:"[1, 50, 99]"`
+	// Fee estimation to answer the standard fee methods from gas station.
+	FeeEstimation FeeEstimationConfig
+}
+
+// FeeEstimationConfig is the configuration to answer the standard fee methods (eg., `eth_gasPrice` and
+// `eth_maxPriorityFeePerGas`) from gas station rather than the routed full node, so that consistent
+// gas fees are returned across full nodes.
+type FeeEstimationConfig struct {
+	// Whether to answer the standard fee methods from gas station.
+	Enabled bool
+	// Level of urgency (`low`, `medium` or `high`) to estimate gas fees.
+	Urgency string `default:"medium"`
+	// Floor and ceiling of the estimated gas price, zero for unbounded.
+	MinGasPrice uint64
+	MaxGasPrice uint64
+	// Floor and ceiling of the estimated priority fee per gas, zero for unbounded.
+	MinPriorityFee uint64
+	MaxPriorityFee uint64
+}
+
+// mustValidate validates the configuration or exits on error.
+func (c *FeeEstimationConfig) mustValidate() {
+	switch c.Urgency {
+	case "low", "medium", "high":
+	default:
+		logrus.WithField("urgency", c.Urgency).Fatal("Invalid urgency level for gas fee estimation")
+	}
+}
+
+// estimate picks the gas price and priority fee per gas of the configured urgency level from the
+// suggested gas fees, which are bounded by the configured floor and ceiling.
+func (c *FeeEstimationConfig) estimate(fees *types.SuggestedGasFees) (gasPrice, priorityFee *big.Int) {
+	estimation := fees.Medium
+	switch c.Urgency {
+	case "low":
+		estimation = fees.Low
+	case "high":
+		estimation = fees.High
+	}
+
+	gasPrice = boundGasFee(estimation.SuggestedMaxFeePerGas.ToInt(), c.MinGasPrice, c.MaxGasPrice)
+	priorityFee = boundGasFee(estimation.SuggestedMaxPriorityFeePerGas.ToInt(), c.MinPriorityFee, c.MaxPriorityFee)
+
+	return gasPrice, priorityFee
+}
+
+// boundGasFee bounds the gas fee within the floor and ceiling, which are ignored if zero.
+func boundGasFee(fee *big.Int, floor, ceiling uint64) *big.Int {
+	if floor > 0 && fee.Cmp(new(big.Int).SetUint64(floor)) < 0 {
+		return new(big.Int).SetUint64(floor)
+	}
+
+	if ceiling > 0 && fee.Cmp(new(big.Int).SetUint64(ceiling)) > 0 {
+		return new(big.Int).SetUint64(ceiling)
+	}
+
+	return fee
 }
 
 type baseGasStationHandler struct {
@@ -67,6 +124,11 @@ func newBaseGasStationHandler(config *GasStationConfig) *baseGasStationHandler {
 	}
 }
 
+// FeeEstimationEnabled checks whether to answer the standard fee methods from gas station.
+func (h *baseGasStationHandler) FeeEstimationEnabled() bool {
+	return h.config.FeeEstimation.Enabled
+}
+
 // run starts to sync historical data and refresh cluster nodes.
 func (h *baseGasStationHandler) run(sync func() (bool, error), refresh func() error) {
 	syncTicker := time.NewTimer(0)
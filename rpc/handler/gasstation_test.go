@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/Conflux-Chain/confura/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -192,3 +193,36 @@ func TestPriorityFeeWindow(t *testing.T) {
 		t, big.NewInt(12500000000), estPriorityFees[2], "99th percentile priority fee should be 12500000000",
 	)
 }
+
+func TestFeeEstimation(t *testing.T) {
+	newEstimation := func(priorityFee, maxFee int64) types.GasFeeEstimation {
+		return types.GasFeeEstimation{
+			SuggestedMaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(priorityFee)),
+			SuggestedMaxFeePerGas:         (*hexutil.Big)(big.NewInt(maxFee)),
+		}
+	}
+
+	fees := &types.SuggestedGasFees{
+		Low:    newEstimation(1, 11),
+		Medium: newEstimation(5, 15),
+		High:   newEstimation(9, 19),
+	}
+
+	testCases := []struct {
+		config                FeeEstimationConfig
+		gasPrice, priorityFee int64
+	}{
+		{FeeEstimationConfig{Urgency: "low"}, 11, 1},
+		{FeeEstimationConfig{Urgency: "medium"}, 15, 5},
+		{FeeEstimationConfig{Urgency: "high"}, 19, 9},
+		{FeeEstimationConfig{Urgency: "low", MinGasPrice: 12, MinPriorityFee: 2}, 12, 2},
+		{FeeEstimationConfig{Urgency: "high", MaxGasPrice: 18, MaxPriorityFee: 8}, 18, 8},
+		{FeeEstimationConfig{Urgency: "medium", MinGasPrice: 10, MaxGasPrice: 20, MinPriorityFee: 1, MaxPriorityFee: 10}, 15, 5},
+	}
+
+	for _, tc := range testCases {
+		gasPrice, priorityFee := tc.config.estimate(fees)
+		assert.Equal(t, big.NewInt(tc.gasPrice), gasPrice)
+		assert.Equal(t, big.NewInt(tc.priorityFee), priorityFee)
+	}
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	web3Types "github.com/openweb3/web3go/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidLogsCursor = errors.New("invalid logs cursor")
+
+	errLogsCursorOutOfRange = errors.New("logs cursor is out of the filter range")
+
+	errLogsCursorFilterMismatch = errors.New(
+		"logs cursor does not match the filter, please query the next page with the same filter",
+	)
+
+	errLogsCursorReorged = errors.New(
+		"logs cursor is invalidated due to chain reorg, please restart the query from a safe block",
+	)
+
+	errEthLogsPagingBlockRangeRequired = errors.New(
+		"paginated log query requires a block range through `fromBlock` and `toBlock`",
+	)
+
+	errCfxLogsPagingEpochRangeRequired = errors.New(
+		"paginated log query requires an epoch range through `fromEpoch` and `toEpoch`",
+	)
+)
+
+// LogsPage is a page of event logs returned from a paginated log query.
+type LogsPage[T types.Log | web3Types.Log] struct {
+	// event logs of this page
+	Logs []T `json:"logs"`
+	// opaque cursor to fetch the next page, empty if no more logs
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// LogsCursorStore is the store to stamp and validate the cursors of paginated log query against
+// chain reorg, e.g., the MySQL store.
+type LogsCursorStore interface {
+	GetReorgVersion() (int, error)
+	PivotHash(number uint64) (string, bool, error)
+}
+
+// logsCursor is the position to resume a paginated log query.
+//
+// Note that the position number is block number for eSpace and epoch number for core space,
+// and the log index is the index of the next log to return among all the matched logs of
+// the position number.
+type logsCursor struct {
+	Number       uint64 `json:"n"`
+	LogIndex     int    `json:"i,omitempty"`
+	ReorgVersion int    `json:"v"`
+	// pivot hash of the position number used to tell if the cursor is affected by reorg,
+	// empty if the position number is not synced into the database yet.
+	PivotHash string `json:"h,omitempty"`
+	// hash of the raw log filter from client (or the subscription filter for resume tokens) that
+	// the cursor belongs to
+	FilterHash string `json:"f,omitempty"`
+	// end of the filter range resolved at the first page, e.g., from `latest` block tag, so that
+	// the range won't move along with the chain head across pages, 0 if unknown.
+	ToNumber uint64 `json:"t,omitempty"`
+	// end of the range window that is known not oversized, so that the next page could be queried
+	// from the cursor position without probing the whole range again, 0 if unknown.
+	WindowTo uint64 `json:"w,omitempty"`
+}
+
+func newLogsCursor(cs LogsCursorStore, number uint64, logIndex, reorgVersion int) (*logsCursor, error) {
+	pivotHash, _, err := cs.PivotHash(number)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get pivot hash")
+	}
+
+	return &logsCursor{
+		Number:       number,
+		LogIndex:     logIndex,
+		ReorgVersion: reorgVersion,
+		PivotHash:    pivotHash,
+	}, nil
+}
+
+func decodeLogsCursor(cursor string) (*logsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidLogsCursor
+	}
+
+	var c logsCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.LogIndex < 0 {
+		return nil, errInvalidLogsCursor
+	}
+
+	return &c, nil
+}
+
+func (c *logsCursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// HashLogsFilter returns the hash of the raw log filter from client to bind the cursors of paginated
+// log query, which shall be computed before the block tags (e.g., `latest`) resolved.
+func HashLogsFilter(filter any) string {
+	data, _ := json.Marshal(filter)
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:8])
+}
+
+// toNumber returns the end of the filter range resolved at the first page, which is bounded by the
+// end of the filter range resolved currently.
+func (c *logsCursor) toNumber(to uint64) uint64 {
+	if c.ToNumber > 0 && c.ToNumber < to {
+		return c.ToNumber
+	}
+
+	return to
+}
+
+// windowTo returns the end of range window to query the page at the cursor, which is bounded by
+// the end of the filter range.
+func (c *logsCursor) windowTo(to uint64) uint64 {
+	if c.WindowTo >= c.Number && c.WindowTo < to {
+		return c.WindowTo
+	}
+
+	return to
+}
+
+// validate checks if the cursor is still valid for the log filter, which is invalidated only if
+// the chain reorg touched the position of the cursor.
+func (c *logsCursor) validate(cs LogsCursorStore, filterHash string, from, to uint64) error {
+	if c.FilterHash != filterHash {
+		return errLogsCursorFilterMismatch
+	}
+
+	if c.Number < from || c.Number > to {
+		return errLogsCursorOutOfRange
+	}
+
+	reorgVersion, err := cs.GetReorgVersion()
+	if err != nil {
+		return err
+	}
+
+	if reorgVersion == c.ReorgVersion {
+		return nil
+	}
+
+	// unable to tell if the position is reorged or not
+	if len(c.PivotHash) == 0 {
+		return errLogsCursorReorged
+	}
+
+	pivotHash, ok, err := cs.PivotHash(c.Number)
+	if err != nil {
+		return err
+	}
+
+	if !ok || pivotHash != c.PivotHash {
+		return errLogsCursorReorged
+	}
+
+	return nil
+}
+
+// normalizeLogsPageLimit returns the page size, which is bounded by the max log limit.
+func normalizeLogsPageLimit(limit, maxLogs uint64) int {
+	if limit == 0 || limit > maxLogs {
+		return int(maxLogs)
+	}
+
+	return int(limit)
+}
+
+// LogsPageQuery is a paginated log query of the resolved position number range.
+type LogsPageQuery[T types.Log | web3Types.Log] struct {
+	FilterHash string // hash of the raw log filter from client
+	From, To   uint64 // position number range of the log filter resolved currently
+	Cursor     string // cursor of the page to query, empty for the first page
+	Limit      int    // max number of logs of the page
+
+	// queries the logs of the position number range window
+	GetLogs func(from, to uint64) ([]T, bool, error)
+	// returns the end of window suggested if the range window is oversized
+	SuggestedTo func(err error) (uint64, bool)
+	// returns the position number of log
+	NumberOf func(*T) uint64
+}
+
+// GetLogsPage returns a page of event logs starting from the position of the cursor if provided,
+// where the range window to query is shrunk as suggested if oversized.
+func GetLogsPage[T types.Log | web3Types.Log](
+	ctx context.Context, cs LogsCursorStore, q *LogsPageQuery[T],
+) (*LogsPage[T], bool, error) {
+	from, to, windowTo := q.From, q.To, q.To
+
+	var skip int
+	if len(q.Cursor) > 0 {
+		c, err := decodeLogsCursor(q.Cursor)
+		if err != nil {
+			return nil, false, err
+		}
+
+		to = c.toNumber(to)
+		if err := c.validate(cs, q.FilterHash, from, to); err != nil {
+			return nil, false, err
+		}
+
+		from, skip, windowTo = c.Number, c.LogIndex, c.windowTo(to)
+	}
+
+	for {
+		// record the reorg version before query to stamp the cursor
+		reorgVersion, err := cs.GetReorgVersion()
+		if err != nil {
+			return nil, false, err
+		}
+
+		logs, hitStore, err := q.GetLogs(from, windowTo)
+
+		// shrink the range window as suggested and try again
+		if suggestedTo, ok := q.SuggestedTo(err); ok && suggestedTo >= from && suggestedTo < windowTo {
+			if err := checkTimeout(ctx); err != nil {
+				return nil, false, err
+			}
+
+			windowTo = suggestedTo
+			continue
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		page, nextNumber, nextLogIndex, truncated := paginateLogs(logs, q.NumberOf, from, skip, q.Limit)
+
+		result := &LogsPage[T]{Logs: page}
+		if !truncated {
+			if windowTo >= to { // no more logs
+				return result, hitStore, nil
+			}
+
+			nextNumber, nextLogIndex = windowTo+1, 0
+		}
+
+		nextCursor, err := newLogsCursor(cs, nextNumber, nextLogIndex, reorgVersion)
+		if err != nil {
+			return nil, false, err
+		}
+
+		nextCursor.FilterHash, nextCursor.ToNumber = q.FilterHash, to
+		if truncated { // the rest of the window is known not oversized
+			nextCursor.WindowTo = windowTo
+		}
+
+		result.Cursor = nextCursor.String()
+		return result, hitStore, nil
+	}
+}
+
+// paginateLogs cuts a page of at most `limit` logs from the logs of a range starting at position
+// number `from`, whose first `skip` logs at `from` have already been returned in previous pages.
+// It returns the page logs, and if truncated, the position number and log index where the next
+// page begins.
+func paginateLogs[T any](
+	logs []T, numberOf func(*T) uint64, from uint64, skip, limit int,
+) (page []T, nextNumber uint64, nextLogIndex int, truncated bool) {
+	skipped := 0
+	for skipped < skip && len(logs) > 0 && numberOf(&logs[0]) == from {
+		logs, skipped = logs[1:], skipped+1
+	}
+
+	if len(logs) <= limit {
+		return logs, 0, 0, false
+	}
+
+	page, nextNumber = logs[:limit], numberOf(&logs[limit])
+	for i := limit - 1; i >= 0 && numberOf(&page[i]) == nextNumber; i-- {
+		nextLogIndex++
+	}
+
+	if nextNumber == from { // take the logs skipped before this page into account
+		nextLogIndex += skipped
+	}
+
+	return page, nextNumber, nextLogIndex, true
+}
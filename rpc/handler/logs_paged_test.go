@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/openweb3/web3go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginateLogs(t *testing.T) {
+	// block numbers of the matched logs
+	logs := []uint64{10, 10, 10, 11, 12, 12, 12, 14}
+	numberOf := func(bn *uint64) uint64 { return *bn }
+
+	// walk through all the logs page by page
+	var walked []uint64
+	from, skip := uint64(10), 0
+
+	for i := 0; ; i++ {
+		var window []uint64
+		for _, bn := range logs {
+			if bn >= from {
+				window = append(window, bn)
+			}
+		}
+
+		page, nextNumber, nextLogIndex, truncated := paginateLogs(window, numberOf, from, skip, 2)
+		assert.LessOrEqual(t, len(page), 2)
+		walked = append(walked, page...)
+
+		if !truncated {
+			break
+		}
+
+		from, skip = nextNumber, nextLogIndex
+		assert.Less(t, i, len(logs), "pagination should terminate")
+	}
+
+	assert.Equal(t, logs, walked)
+
+	// page ends in the middle of the position number with logs skipped before
+	page, nextNumber, nextLogIndex, truncated := paginateLogs(logs, numberOf, 10, 1, 1)
+	assert.Equal(t, []uint64{10}, page)
+	assert.True(t, truncated)
+	assert.Equal(t, uint64(10), nextNumber)
+	assert.Equal(t, 2, nextLogIndex)
+}
+
+func TestLogsCursorCodec(t *testing.T) {
+	c := &logsCursor{
+		Number: 100, LogIndex: 3, ReorgVersion: 7, PivotHash: "0x1234", FilterHash: "abcd", WindowTo: 200,
+	}
+
+	decoded, err := decodeLogsCursor(c.String())
+	assert.NoError(t, err)
+	assert.Equal(t, c, decoded)
+
+	_, err = decodeLogsCursor("not-a-cursor")
+	assert.ErrorIs(t, err, errInvalidLogsCursor)
+}
+
+func TestLogsCursorFilterMismatch(t *testing.T) {
+	fromBlock, toBlock := types.BlockNumber(100), types.BlockNumber(1000)
+	filter := types.FilterQuery{
+		FromBlock: &fromBlock,
+		ToBlock:   &toBlock,
+		Addresses: []common.Address{common.HexToAddress("0x0000000000000000000000000000000000000001")},
+	}
+
+	c := &logsCursor{Number: 200, FilterHash: HashLogsFilter(&filter)}
+
+	// cursor replayed with a different filter
+	otherFilter := filter
+	otherFilter.Addresses = []common.Address{common.HexToAddress("0x0000000000000000000000000000000000000002")}
+	err := c.validate(nil, HashLogsFilter(&otherFilter), 100, 1000)
+	assert.ErrorIs(t, err, errLogsCursorFilterMismatch)
+
+	otherToBlock := types.BlockNumber(2000)
+	otherFilter = filter
+	otherFilter.ToBlock = &otherToBlock
+	err = c.validate(nil, HashLogsFilter(&otherFilter), 100, 2000)
+	assert.ErrorIs(t, err, errLogsCursorFilterMismatch)
+
+	// cursor not bound to any filter
+	err = (&logsCursor{Number: 200}).validate(nil, HashLogsFilter(&filter), 100, 1000)
+	assert.ErrorIs(t, err, errLogsCursorFilterMismatch)
+}
+
+func TestLogsCursorWindowTo(t *testing.T) {
+	assert.Equal(t, uint64(1000), (&logsCursor{Number: 200}).windowTo(1000))
+	assert.Equal(t, uint64(300), (&logsCursor{Number: 200, WindowTo: 300}).windowTo(1000))
+	assert.Equal(t, uint64(1000), (&logsCursor{Number: 200, WindowTo: 2000}).windowTo(1000))
+	assert.Equal(t, uint64(1000), (&logsCursor{Number: 200, WindowTo: 100}).windowTo(1000))
+}
+
+type memLogsCursorStore struct {
+	reorgVersion int
+}
+
+func (s *memLogsCursorStore) GetReorgVersion() (int, error) {
+	return s.reorgVersion, nil
+}
+
+func (s *memLogsCursorStore) PivotHash(number uint64) (string, bool, error) {
+	return "0x01", true, nil
+}
+
+// newMemLogsPageQuery returns a paginated log query of the logs at the block numbers.
+func newMemLogsPageQuery(blockNumbers []uint64, filterHash string, from, to uint64) *LogsPageQuery[types.Log] {
+	return &LogsPageQuery[types.Log]{
+		FilterHash: filterHash,
+		From:       from,
+		To:         to,
+		Limit:      2,
+		GetLogs: func(from, to uint64) (logs []types.Log, _ bool, _ error) {
+			for _, bn := range blockNumbers {
+				if bn >= from && bn <= to {
+					logs = append(logs, types.Log{BlockNumber: bn})
+				}
+			}
+			return logs, true, nil
+		},
+		SuggestedTo: func(err error) (uint64, bool) { return 0, false },
+		NumberOf:    func(log *types.Log) uint64 { return log.BlockNumber },
+	}
+}
+
+func TestGetLogsPagedRangeResolvedAtFirstPage(t *testing.T) {
+	cs := &memLogsCursorStore{}
+	blockNumbers := []uint64{10, 11, 12, 25}
+
+	// `latest` resolved as block 20 at the first page
+	q := newMemLogsPageQuery(blockNumbers, "hash", 10, 20)
+	page, _, err := GetLogsPage(context.Background(), cs, q)
+	assert.NoError(t, err)
+	assert.Len(t, page.Logs, 2)
+	assert.NotEmpty(t, page.Cursor)
+
+	// the chain head moved to block 30 for the next page of the same raw filter
+	q = newMemLogsPageQuery(blockNumbers, "hash", 10, 30)
+	q.Cursor = page.Cursor
+	page, _, err = GetLogsPage(context.Background(), cs, q)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Log{{BlockNumber: 12}}, page.Logs)
+	assert.Empty(t, page.Cursor)
+}
+
+func TestGetLogsPagedWithResumeToken(t *testing.T) {
+	cs := &memLogsCursorStore{}
+	blockNumbers := []uint64{10, 10, 11, 12, 12, 14}
+	subHash := HashLogsFilter(&types.FilterQuery{})
+
+	// logs notified before the subscription dropped
+	tracker := NewLogsResumeTracker(cs, subHash)
+
+	var token string
+	for _, bn := range blockNumbers[:4] {
+		var err error
+		token, err = tracker.Track(bn)
+		assert.NoError(t, err)
+	}
+
+	// resume token is bound to the subscription filter
+	otherHash := HashLogsFilter(&types.FilterQuery{Addresses: []common.Address{{}}})
+	_, err := NewLogsResumeTracker(cs, otherHash).Resume(token)
+	assert.ErrorIs(t, err, errLogsCursorFilterMismatch)
+
+	// resume on a new subscription
+	tracker = NewLogsResumeTracker(cs, subHash)
+	from, err := tracker.Resume(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12), from)
+
+	var replayed []uint64
+	cursor := token
+
+	for len(cursor) > 0 {
+		q := newMemLogsPageQuery(blockNumbers, subHash, from, 20)
+		q.Cursor = cursor
+
+		page, _, err := GetLogsPage(context.Background(), cs, q)
+		assert.NoError(t, err)
+
+		for _, log := range page.Logs {
+			replayed = append(replayed, log.BlockNumber)
+		}
+
+		cursor = page.Cursor
+	}
+
+	// replayed right after the last notified log without gap or duplicate
+	assert.Equal(t, blockNumbers[4:], replayed)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/pkg/errors"
+)
+
+// LogsResumeTracker tracks the position of event logs notified by a resumable logs subscription,
+// so as to generate resume tokens, which are the same as the cursors of paginated log query and
+// bound to the hash of the subscription filter.
+//
+// Note that the position number is block number for eSpace and epoch number for core space.
+type LogsResumeTracker struct {
+	cs         LogsCursorStore
+	filterHash string      // hash of the subscription filter
+	cursor     *logsCursor // position right after the last notified log
+}
+
+// NewLogsResumeTracker creates a tracker for resumable logs subscription of the filter hash.
+func NewLogsResumeTracker(cs LogsCursorStore, filterHash string) *LogsResumeTracker {
+	return &LogsResumeTracker{cs: cs, filterHash: filterHash}
+}
+
+// Resume resumes tracking from the resume token, and returns the position number to replay logs from.
+func (t *LogsResumeTracker) Resume(token string) (uint64, error) {
+	c, err := decodeLogsCursor(token)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.FilterHash != t.filterHash {
+		return 0, errLogsCursorFilterMismatch
+	}
+
+	t.cursor = c
+	return c.Number, nil
+}
+
+// Token returns the resume token of the position right after the last tracked log if any.
+func (t *LogsResumeTracker) Token() (string, bool) {
+	if t.cursor == nil {
+		return "", false
+	}
+
+	return t.cursor.String(), true
+}
+
+// Track tracks the notified log at the position number, and returns the resume token to resume
+// right after the log. Note, logs must be tracked in the order they are notified.
+func (t *LogsResumeTracker) Track(number uint64) (string, error) {
+	if t.cursor == nil || t.cursor.Number != number {
+		reorgVersion, err := t.cs.GetReorgVersion()
+		if err != nil {
+			return "", errors.WithMessage(err, "failed to get reorg version")
+		}
+
+		if t.cursor, err = newLogsCursor(t.cs, number, 0, reorgVersion); err != nil {
+			return "", err
+		}
+
+		t.cursor.FilterHash = t.filterHash
+	}
+
+	t.cursor.LogIndex++
+	return t.cursor.String(), nil
+}
+
+// Reset resets the tracked position due to chain reorg, so that logs will be tracked from scratch.
+func (t *LogsResumeTracker) Reset() {
+	t.cursor = nil
+}
+
+// NewLogsResumeTracker creates a tracker for resumable logs subscription of the filter hash.
+func (handler *EthLogsApiHandler) NewLogsResumeTracker(filterHash string) *LogsResumeTracker {
+	return NewLogsResumeTracker(handler.ms, filterHash)
+}
+
+// NewLogsResumeTracker creates a tracker for resumable logs subscription of the filter hash.
+func (handler *CfxLogsApiHandler) NewLogsResumeTracker(filterHash string) *LogsResumeTracker {
+	return NewLogsResumeTracker(handler.ms, filterHash)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Conflux-Chain/confura/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// interval to poll the newly journaled reorgs
+	reorgPollingInterval = time.Second
+	// max number of reorgs to query once
+	maxReorgQuerySize = 100
+)
+
+var (
+	// ErrTooManyReorgs is returned if too many reorgs to return at a time, in which case clients
+	// should query from a later epoch (or block).
+	ErrTooManyReorgs = errors.Errorf(
+		"too many reorgs (more than %v), please query from a later epoch or block", maxReorgQuerySize,
+	)
+)
+
+// ReorgJournal provides the journaled chain reorgs, e.g., mysql store.
+type ReorgJournal interface {
+	GetReorgs(fromEpoch uint64, limit int) ([]*types.Reorg, error)
+	GetReorgsAfter(id uint64, limit int) ([]*types.Reorg, error)
+	LatestReorgID() (uint64, error)
+}
+
+// ReorgHandler RPC handler to query journaled chain reorgs, and notifies subscribers of the newly
+// journaled ones.
+type ReorgHandler struct {
+	journal ReorgJournal
+
+	mu          sync.Mutex
+	startOnce   sync.Once
+	subscribers map[chan<- *types.Reorg]struct{}
+}
+
+func NewReorgHandler(journal ReorgJournal) *ReorgHandler {
+	return &ReorgHandler{
+		journal:     journal,
+		subscribers: make(map[chan<- *types.Reorg]struct{}),
+	}
+}
+
+// GetReorgs returns the journaled reorgs which replaced any epoch (or block in evm space) since
+// the specified one, or ErrTooManyReorgs rather than the truncated reorgs if exceeds the query size.
+func (h *ReorgHandler) GetReorgs(fromEpoch uint64) ([]*types.Reorg, error) {
+	reorgs, err := h.journal.GetReorgs(fromEpoch, maxReorgQuerySize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reorgs) > maxReorgQuerySize {
+		return nil, ErrTooManyReorgs
+	}
+
+	return reorgs, nil
+}
+
+// Subscribe subscribes the newly journaled reorgs, which will be skipped if the channel is full.
+// Returns a function to unsubscribe.
+func (h *ReorgHandler) Subscribe(ch chan<- *types.Reorg) (unsubscribe func()) {
+	h.startOnce.Do(func() {
+		go h.run()
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscribers[ch] = struct{}{}
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.subscribers, ch)
+	}
+}
+
+// run polls the newly journaled reorgs periodically.
+func (h *ReorgHandler) run() {
+	lastID, err := h.journal.LatestReorgID()
+	for err != nil {
+		logrus.WithError(err).Info("Reorg handler failed to get the latest reorg ID")
+		time.Sleep(reorgPollingInterval)
+
+		lastID, err = h.journal.LatestReorgID()
+	}
+
+	ticker := time.NewTicker(reorgPollingInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if lastID, err = h.pollOnce(lastID); err != nil {
+			logrus.WithError(err).Info("Reorg handler failed to poll the journaled reorgs")
+		}
+	}
+}
+
+// pollOnce notifies subscribers of the reorgs journaled after the specified ID, and returns
+// the ID of the last notified one.
+func (h *ReorgHandler) pollOnce(lastID uint64) (uint64, error) {
+	for {
+		reorgs, err := h.journal.GetReorgsAfter(lastID, maxReorgQuerySize)
+		if err != nil {
+			return lastID, errors.WithMessage(err, "failed to get reorgs")
+		}
+
+		for _, reorg := range reorgs {
+			h.notify(reorg)
+			lastID = reorg.ID
+		}
+
+		if len(reorgs) < maxReorgQuerySize {
+			return lastID, nil
+		}
+	}
+}
+
+func (h *ReorgHandler) notify(reorg *types.Reorg) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- reorg:
+		default: // subscriber too slow
+			logrus.WithField("reorg", reorg).Debug("Reorg notification skipped due to channel full")
+		}
+	}
+}
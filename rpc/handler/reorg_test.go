@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/Conflux-Chain/confura/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type memReorgJournal []*types.Reorg
+
+func (j memReorgJournal) GetReorgs(fromEpoch uint64, limit int) (res []*types.Reorg, _ error) {
+	for _, r := range j {
+		if r.ForkPoint+r.Depth > fromEpoch && len(res) < limit {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func (j memReorgJournal) GetReorgsAfter(id uint64, limit int) (res []*types.Reorg, _ error) {
+	for _, r := range j {
+		if r.ID > id && len(res) < limit {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+func (j memReorgJournal) LatestReorgID() (uint64, error) {
+	if len(j) == 0 {
+		return 0, nil
+	}
+	return j[len(j)-1].ID, nil
+}
+
+func TestReorgHandlerPollOnce(t *testing.T) {
+	var journal memReorgJournal
+	for i := uint64(1); i <= maxReorgQuerySize+5; i++ {
+		journal = append(journal, &types.Reorg{ID: i, ForkPoint: i * 10, Depth: 2})
+	}
+
+	h := NewReorgHandler(journal)
+
+	ch := make(chan *types.Reorg, 2*maxReorgQuerySize)
+	slowCh := make(chan *types.Reorg, 1)
+	h.subscribers[ch] = struct{}{}
+	h.subscribers[slowCh] = struct{}{}
+
+	// notify all reorgs even more than the query size
+	lastID, err := h.pollOnce(3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(maxReorgQuerySize+5), lastID)
+	assert.Len(t, ch, maxReorgQuerySize+2)
+	assert.Equal(t, uint64(4), (<-ch).ID)
+
+	// slow subscriber skips reorgs once channel full
+	assert.Len(t, slowCh, 1)
+	assert.Equal(t, uint64(4), (<-slowCh).ID)
+
+	// nothing new to notify
+	lastID, err = h.pollOnce(lastID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(maxReorgQuerySize+5), lastID)
+	assert.Len(t, slowCh, 0)
+
+	// query reorgs which replaced blocks since 61, i.e., reorg #6 replaced blocks 60 and 61
+	reorgs, err := h.GetReorgs(61)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), reorgs[0].ID)
+	assert.Len(t, reorgs, maxReorgQuerySize)
+
+	// never truncated if exceeds the query size
+	_, err = h.GetReorgs(21)
+	assert.ErrorIs(t, err, ErrTooManyReorgs)
+}
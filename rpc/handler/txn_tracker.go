@@ -0,0 +1,395 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Conflux-Chain/confura/store"
+	"github.com/Conflux-Chain/confura/util"
+	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// TxnStatus is the lifecycle status of transaction submitted via Confura.
+type TxnStatus string
+
+const (
+	// submitted to full node, but not seen in txpool yet
+	TxnStatusSubmitted TxnStatus = "submitted"
+	// seen in txpool and waiting to be mined
+	TxnStatusPending TxnStatus = "pending"
+	// mined and executed successfully
+	TxnStatusMined TxnStatus = "mined"
+	// mined but failed to execute, or skipped in core space
+	TxnStatusFailed TxnStatus = "failed"
+	// dropped from txpool and never mined
+	TxnStatusDropped TxnStatus = "dropped"
+	// replaced by another transaction of the same sender and nonce
+	TxnStatusReplaced TxnStatus = "replaced"
+)
+
+// IsFinal checks whether the lifecycle of transaction is done.
+func (s TxnStatus) IsFinal() bool {
+	return s != TxnStatusSubmitted && s != TxnStatusPending
+}
+
+// TxnState is the lifecycle state of transaction submitted via Confura.
+type TxnState struct {
+	Hash   string    `json:"hash"`
+	Status TxnStatus `json:"status"`
+	// block (or epoch for core space) where transaction mined
+	BlockHash   string          `json:"blockHash,omitempty"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"`
+	SubmittedAt time.Time       `json:"submittedAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+// txnProbe is the transaction status probed from chain data and txpool.
+type txnProbe struct {
+	status      TxnStatus // empty if transaction not found anywhere
+	blockHash   string
+	blockNumber uint64
+}
+
+// txnProber probes transaction status from the full node which it is submitted to.
+type txnProber interface {
+	// name of the full node, so that transactions submitted to the same full node are probed in batch
+	nodeName() string
+	// probes the status of transactions submitted to the same full node in batch, and returns the
+	// result or error of each transaction
+	probeBatch(probers []txnProber) ([]txnProbe, []error)
+}
+
+// TxnReceiptStore reads the receipts of transactions from the synced store in batch.
+type TxnReceiptStore interface {
+	GetReceipts(ctx context.Context, txHashes []types.Hash) (map[types.Hash]*store.TransactionReceipt, error)
+}
+
+// trackedTxn is the transaction under tracking.
+type trackedTxn struct {
+	TxnState
+	prober   txnProber
+	lastSeen time.Time // last time seen in txpool or chain
+}
+
+type TxnTrackerConfig struct {
+	// whether to track transactions submitted via Confura
+	Enabled bool
+	// interval to probe the status of tracked transactions
+	PollingInterval time.Duration `default:"1s"`
+	// duration to regard transaction as dropped if not seen in txpool
+	DropTimeout time.Duration `default:"5m"`
+	// duration to keep transaction states since submitted
+	TTL time.Duration `default:"1h"`
+	// max number of tracked transactions
+	Capacity int `default:"100000"`
+	// max number of transactions to probe in a batch
+	ProbeBatchSize int `default:"500"`
+}
+
+// TxnTracker tracks the lifecycle of transactions submitted via Confura by watching full node
+// txpool and chain data, so that clients could query or subscribe to the transaction status
+// rather than polling receipts.
+//
+// Note the transaction states are kept in memory of each RPC instance, so the status could only
+// be queried from the instance where transaction submitted, e.g., with sticky sessions if behind
+// a load balancer. Otherwise, transaction is regarded as not tracked.
+type TxnTracker struct {
+	config *TxnTrackerConfig
+	store  TxnReceiptStore // optional synced store to read receipts
+
+	mu          sync.Mutex
+	startOnce   sync.Once
+	txns        map[string]*trackedTxn                  // txn hash => tracked txn
+	subscribers map[string]map[chan<- TxnState]struct{} // txn hash => subscribers
+}
+
+// MustNewTxnTrackerFromViper creates a transaction tracker from viper settings, or nil if disabled.
+func MustNewTxnTrackerFromViper(store TxnReceiptStore) *TxnTracker {
+	var config TxnTrackerConfig
+	viper.MustUnmarshalKey("relay.tracker", &config)
+
+	if !config.Enabled {
+		return nil
+	}
+
+	return NewTxnTracker(&config, store)
+}
+
+func NewTxnTracker(config *TxnTrackerConfig, store TxnReceiptStore) *TxnTracker {
+	if util.IsInterfaceValNil(store) {
+		store = nil
+	}
+
+	return &TxnTracker{
+		config:      config,
+		store:       store,
+		txns:        make(map[string]*trackedTxn),
+		subscribers: make(map[string]map[chan<- TxnState]struct{}),
+	}
+}
+
+// track starts to track the submitted transaction.
+func (t *TxnTracker) track(hash string, prober txnProber) {
+	t.startOnce.Do(func() {
+		go t.run()
+	})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.txns[hash]; ok { // already tracked, e.g., resubmitted
+		return
+	}
+
+	if len(t.txns) >= t.config.Capacity {
+		logrus.WithField("txHash", hash).Debug("Txn tracker is full, skip tracking transaction")
+		return
+	}
+
+	now := time.Now()
+	t.txns[hash] = &trackedTxn{
+		TxnState: TxnState{
+			Hash: hash, Status: TxnStatusSubmitted, SubmittedAt: now, UpdatedAt: now,
+		},
+		prober:   prober,
+		lastSeen: now,
+	}
+}
+
+// Status returns the lifecycle state of the tracked transaction.
+func (t *TxnTracker) Status(hash string) (TxnState, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if txn, ok := t.txns[hash]; ok {
+		return txn.TxnState, true
+	}
+
+	return TxnState{}, false
+}
+
+// Subscribe subscribes the lifecycle state changes of the tracked transaction, and the current
+// state will be delivered at once. Returns false if transaction not tracked.
+func (t *TxnTracker) Subscribe(hash string, ch chan<- TxnState) (unsubscribe func(), ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	txn, ok := t.txns[hash]
+	if !ok {
+		return nil, false
+	}
+
+	if _, ok := t.subscribers[hash]; !ok {
+		t.subscribers[hash] = make(map[chan<- TxnState]struct{})
+	}
+
+	t.subscribers[hash][ch] = struct{}{}
+	t.notify(ch, txn.TxnState)
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		delete(t.subscribers[hash], ch)
+		if len(t.subscribers[hash]) == 0 {
+			delete(t.subscribers, hash)
+		}
+	}, true
+}
+
+// run probes the tracked transactions periodically.
+func (t *TxnTracker) run() {
+	ticker := time.NewTicker(t.config.PollingInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		t.probeOnce()
+	}
+}
+
+// probeOnce probes the status of all tracked transactions that are not done yet, and evicts the
+// expired ones.
+func (t *TxnTracker) probeOnce() {
+	now := time.Now()
+
+	var probing []*trackedTxn
+
+	t.mu.Lock()
+	for hash, txn := range t.txns {
+		if now.Sub(txn.SubmittedAt) > t.config.TTL {
+			delete(t.txns, hash)
+			continue
+		}
+
+		if !txn.Status.IsFinal() {
+			probing = append(probing, txn)
+		}
+	}
+	t.mu.Unlock()
+
+	// read status from the synced store at first, and probe the rest from full nodes
+	probing = t.probeStore(probing)
+	t.probeNodes(probing)
+}
+
+// probeStore updates the status of transactions whose receipts are found in the synced store, and
+// returns the rest ones.
+func (t *TxnTracker) probeStore(txns []*trackedTxn) (unresolved []*trackedTxn) {
+	if t.store == nil {
+		return txns
+	}
+
+	for start := 0; start < len(txns); start += t.config.ProbeBatchSize {
+		batch := txns[start:min(start+t.config.ProbeBatchSize, len(txns))]
+
+		hashes := make([]types.Hash, len(batch))
+		for i, txn := range batch {
+			hashes[i] = types.Hash(txn.Hash)
+		}
+
+		receipts, err := t.store.GetReceipts(context.Background(), hashes)
+		if err != nil {
+			logrus.WithError(err).Debug("Txn tracker failed to read receipts from store")
+			unresolved = append(unresolved, batch...)
+			continue
+		}
+
+		now := time.Now()
+		for _, txn := range batch {
+			receipt, ok := receipts[types.Hash(txn.Hash)]
+			if !ok {
+				unresolved = append(unresolved, txn)
+				continue
+			}
+
+			// outcome status: 0 for success, otherwise failure
+			result := txnProbe{status: TxnStatusMined, blockHash: receipt.CfxReceipt.BlockHash.String()}
+			if receipt.CfxReceipt.OutcomeStatus != 0 {
+				result.status = TxnStatusFailed
+			}
+
+			if receipt.CfxReceipt.EpochNumber != nil {
+				result.blockNumber = uint64(*receipt.CfxReceipt.EpochNumber)
+			}
+
+			t.update(txn, result, now)
+		}
+	}
+
+	return unresolved
+}
+
+// probeNodes probes the status of transactions in batch grouped by the full node submitted to.
+func (t *TxnTracker) probeNodes(txns []*trackedTxn) {
+	nodeTxns := make(map[string][]*trackedTxn)
+	for _, txn := range txns {
+		nodeName := txn.prober.nodeName()
+		nodeTxns[nodeName] = append(nodeTxns[nodeName], txn)
+	}
+
+	for _, txns := range nodeTxns {
+		for start := 0; start < len(txns); start += t.config.ProbeBatchSize {
+			batch := txns[start:min(start+t.config.ProbeBatchSize, len(txns))]
+
+			probers := make([]txnProber, len(batch))
+			for i, txn := range batch {
+				probers[i] = txn.prober
+			}
+
+			results, errs := probers[0].probeBatch(probers)
+
+			now := time.Now()
+			for i, txn := range batch {
+				if errs[i] != nil {
+					logrus.WithField("txHash", txn.Hash).WithError(errs[i]).Debug("Txn tracker failed to probe transaction")
+					continue
+				}
+
+				t.update(txn, results[i], now)
+			}
+		}
+	}
+}
+
+// update updates the state of tracked transaction with the probe result, and notifies the
+// subscribers if state changed.
+func (t *TxnTracker) update(txn *trackedTxn, result txnProbe, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := result.status
+	switch {
+	case len(status) > 0:
+		txn.lastSeen = now
+	case now.Sub(txn.lastSeen) > t.config.DropTimeout:
+		status = TxnStatusDropped
+	default: // not found for a while, e.g., not propagated yet
+		return
+	}
+
+	if status == txn.Status {
+		return
+	}
+
+	txn.Status, txn.UpdatedAt = status, now
+	if status == TxnStatusMined || status == TxnStatusFailed {
+		bn := hexutil.Uint64(result.blockNumber)
+		txn.BlockHash, txn.BlockNumber = result.blockHash, &bn
+	}
+
+	for ch := range t.subscribers[txn.Hash] {
+		t.notify(ch, txn.TxnState)
+	}
+}
+
+func (t *TxnTracker) notify(ch chan<- TxnState, state TxnState) {
+	select {
+	case ch <- state:
+	default: // subscriber too slow
+		logrus.WithField("state", state).Debug("Txn status notification skipped due to channel full")
+	}
+}
+
+// batchProbe sends batch RPC request with an element for each indexed transaction, and returns the
+// indexes of transactions succeeded, while the errors of the failed ones are recorded.
+func batchProbe(
+	batchCall func(elems []rpc.BatchElem) error,
+	indexes []int,
+	errs []error,
+	errMsg string,
+	newElem func(i int) rpc.BatchElem,
+) (succeeded []int) {
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	elems := make([]rpc.BatchElem, len(indexes))
+	for j, i := range indexes {
+		elems[j] = newElem(i)
+	}
+
+	if err := batchCall(elems); err != nil {
+		for _, i := range indexes {
+			errs[i] = errors.WithMessage(err, errMsg)
+		}
+
+		return nil
+	}
+
+	for j, i := range indexes {
+		if elems[j].Error != nil {
+			errs[i] = errors.WithMessage(elems[j].Error, errMsg)
+			continue
+		}
+
+		succeeded = append(succeeded, i)
+	}
+
+	return succeeded
+}
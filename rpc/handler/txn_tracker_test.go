@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Conflux-Chain/confura/store"
+	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTxnProber struct {
+	node    string
+	result  txnProbe
+	batches *[]int // size of each probed batch
+}
+
+func (p *mockTxnProber) nodeName() string {
+	return p.node
+}
+
+func (p *mockTxnProber) probeBatch(probers []txnProber) ([]txnProbe, []error) {
+	if p.batches != nil {
+		*p.batches = append(*p.batches, len(probers))
+	}
+
+	results := make([]txnProbe, len(probers))
+	for i := range probers {
+		results[i] = probers[i].(*mockTxnProber).result
+	}
+
+	return results, make([]error, len(probers))
+}
+
+type mockTxnReceiptStore struct {
+	receipts map[types.Hash]*store.TransactionReceipt
+}
+
+func (s *mockTxnReceiptStore) GetReceipts(
+	ctx context.Context, txHashes []types.Hash,
+) (map[types.Hash]*store.TransactionReceipt, error) {
+	receipts := make(map[types.Hash]*store.TransactionReceipt)
+	for _, hash := range txHashes {
+		if receipt, ok := s.receipts[hash]; ok {
+			receipts[hash] = receipt
+		}
+	}
+
+	return receipts, nil
+}
+
+func TestTxnTrackerLifecycle(t *testing.T) {
+	tracker := NewTxnTracker(&TxnTrackerConfig{
+		PollingInterval: time.Hour, DropTimeout: time.Minute, TTL: time.Hour, Capacity: 2,
+	}, nil)
+
+	prober := &mockTxnProber{}
+	tracker.track("0x1", prober)
+
+	state, ok := tracker.Status("0x1")
+	assert.True(t, ok)
+	assert.Equal(t, TxnStatusSubmitted, state.Status)
+
+	ch := make(chan TxnState, 10)
+	unsubscribe, ok := tracker.Subscribe("0x1", ch)
+	assert.True(t, ok)
+	assert.Equal(t, TxnStatusSubmitted, (<-ch).Status)
+
+	txn := tracker.txns["0x1"]
+	now := txn.SubmittedAt
+
+	// not found within drop timeout
+	tracker.update(txn, txnProbe{}, now.Add(time.Second))
+	assert.Len(t, ch, 0)
+
+	// seen in txpool
+	tracker.update(txn, txnProbe{status: TxnStatusPending}, now.Add(2*time.Second))
+	assert.Equal(t, TxnStatusPending, (<-ch).Status)
+
+	// status unchanged
+	tracker.update(txn, txnProbe{status: TxnStatusPending}, now.Add(3*time.Second))
+	assert.Len(t, ch, 0)
+
+	// mined
+	tracker.update(txn, txnProbe{status: TxnStatusMined, blockHash: "0xb", blockNumber: 5}, now.Add(4*time.Second))
+	state = <-ch
+	assert.Equal(t, TxnStatusMined, state.Status)
+	assert.Equal(t, "0xb", state.BlockHash)
+	assert.Equal(t, uint64(5), uint64(*state.BlockNumber))
+	assert.True(t, state.Status.IsFinal())
+
+	unsubscribe()
+	assert.Empty(t, tracker.subscribers)
+
+	// dropped if not found since drop timeout
+	tracker.track("0x2", prober)
+	txn = tracker.txns["0x2"]
+	tracker.update(txn, txnProbe{}, txn.lastSeen.Add(2*time.Minute))
+	assert.Equal(t, TxnStatusDropped, txn.Status)
+
+	// tracker is full
+	tracker.track("0x3", prober)
+	_, ok = tracker.Status("0x3")
+	assert.False(t, ok)
+
+	// untracked transaction
+	_, ok = tracker.Subscribe("0x3", ch)
+	assert.False(t, ok)
+}
+
+func TestTxnTrackerProbeOnce(t *testing.T) {
+	epoch := hexutil.Uint64(7)
+	receiptStore := &mockTxnReceiptStore{receipts: map[types.Hash]*store.TransactionReceipt{
+		"0x1": {CfxReceipt: &types.TransactionReceipt{BlockHash: "0xb", EpochNumber: &epoch}},
+		"0x2": {CfxReceipt: &types.TransactionReceipt{BlockHash: "0xb", EpochNumber: &epoch, OutcomeStatus: 1}},
+	}}
+
+	tracker := NewTxnTracker(&TxnTrackerConfig{
+		PollingInterval: time.Hour, DropTimeout: time.Minute, TTL: time.Hour, Capacity: 10, ProbeBatchSize: 2,
+	}, receiptStore)
+
+	var batches []int
+	pending := txnProbe{status: TxnStatusPending}
+	for _, hash := range []string{"0x1", "0x2", "0x3", "0x4", "0x5"} {
+		tracker.track(hash, &mockTxnProber{node: "node1", result: pending, batches: &batches})
+	}
+	tracker.track("0x6", &mockTxnProber{node: "node2", result: pending, batches: &batches})
+
+	tracker.probeOnce()
+
+	// status read from the synced store
+	state, _ := tracker.Status("0x1")
+	assert.Equal(t, TxnStatusMined, state.Status)
+	assert.Equal(t, uint64(7), uint64(*state.BlockNumber))
+
+	state, _ = tracker.Status("0x2")
+	assert.Equal(t, TxnStatusFailed, state.Status)
+
+	// the rest probed from full nodes in batches
+	for _, hash := range []string{"0x3", "0x4", "0x5", "0x6"} {
+		state, _ = tracker.Status(hash)
+		assert.Equal(t, TxnStatusPending, state.Status)
+	}
+
+	assert.ElementsMatch(t, []int{2, 1, 1}, batches)
+}
+
+func TestBatchProbe(t *testing.T) {
+	errs := make([]error, 4)
+
+	var called []rpc.BatchElem
+	batchCall := func(elems []rpc.BatchElem) error {
+		called = elems
+		elems[1].Error = errors.New("bad request")
+		return nil
+	}
+
+	succeeded := batchProbe(batchCall, []int{0, 2, 3}, errs, "failed", func(i int) rpc.BatchElem {
+		return rpc.BatchElem{Method: "test", Args: []interface{}{i}}
+	})
+
+	assert.Len(t, called, 3)
+	assert.Equal(t, []int{0, 3}, succeeded)
+	assert.Nil(t, errs[0])
+	assert.Error(t, errs[2])
+
+	// all failed if batch request failed
+	errs = make([]error, 4)
+	succeeded = batchProbe(func([]rpc.BatchElem) error { return errors.New("io error") }, []int{1, 3}, errs, "failed",
+		func(i int) rpc.BatchElem { return rpc.BatchElem{Method: "test"} },
+	)
+	assert.Empty(t, succeeded)
+	assert.Error(t, errs[1])
+	assert.Error(t, errs[3])
+
+	// no request if nothing to probe
+	called = nil
+	assert.Empty(t, batchProbe(batchCall, nil, errs, "failed", nil))
+	assert.Nil(t, called)
+}
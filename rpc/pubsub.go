@@ -210,6 +210,38 @@ func (dctx *delegateContext) cancel(err error) {
 	dctx.oncer = sync.Once{}
 }
 
+// evict all delegated subscriptions with error, but keeps the delegate pubsub run loop running
+// for new subscriptions. Returns the number of evicted subscriptions.
+func (dctx *delegateContext) evict(err error) (n int) {
+	dctx.lock.Lock()
+	defer dctx.lock.Unlock()
+
+	dctx.delegateSubs.Range(func(key, value interface{}) bool {
+		dsub := value.(*delegateSubscription)
+		select {
+		case dsub.err <- err:
+		default: // error already sent, e.g., subscription queue overflow
+		}
+
+		dctx.delegateSubs.Delete(key)
+		n++
+
+		return true
+	})
+
+	return n
+}
+
+// numDelegateSubs returns the number of delegated subscriptions.
+func (dctx *delegateContext) numDelegateSubs() (n int) {
+	dctx.delegateSubs.Range(func(key, value interface{}) bool {
+		n++
+		return true
+	})
+
+	return n
+}
+
 // notify all delegated subscriptions for new result
 func (dctx *delegateContext) notify(result interface{}) {
 	dctx.lock.RLock()
@@ -236,6 +268,26 @@ func getOrNewDelegateClient(cfx sdk.ClientOperator) *delegateClient {
 	return client.(*delegateClient)
 }
 
+// evictDelegateSubs evicts all delegated subscriptions of the client with error.
+func (client *delegateClient) evictDelegateSubs(err error) (n int) {
+	client.delegateContexts.Range(func(key, value interface{}) bool {
+		n += value.(*delegateContext).evict(err)
+		return true
+	})
+
+	return n
+}
+
+// numDelegateSubs returns the number of all delegated subscriptions of the client.
+func (client *delegateClient) numDelegateSubs() (n int) {
+	client.delegateContexts.Range(func(key, value interface{}) bool {
+		n += value.(*delegateContext).numDelegateSubs()
+		return true
+	})
+
+	return n
+}
+
 func (client *delegateClient) getDelegateCtx(ctxName string) *delegateContext {
 	dctx, _ := client.delegateContexts.LoadOrStore(ctxName, newDelegateContext())
 	return dctx.(*delegateContext)
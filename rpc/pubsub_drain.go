@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Conflux-Chain/confura/node"
+	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// interval to check draining full nodes
+	drainCheckInterval = 15 * time.Second
+)
+
+var (
+	// errNodeDraining returned to delegated subscriptions when the full node is draining, so that
+	// the client connections are closed and reconnected to other full nodes.
+	errNodeDraining = errors.New("full node is draining")
+)
+
+// drainingNodeProvider provides the URLs of draining full nodes, and collects the drain counts.
+type drainingNodeProvider interface {
+	ListDrainingNodes() []string
+	ReportDrain(reporter string, counts map[string]node.DrainCounts) error
+}
+
+// evictableDelegateClient is implemented by delegate clients that could evict delegated subscriptions.
+type evictableDelegateClient interface {
+	evictDelegateSubs(err error) int
+	numDelegateSubs() int
+}
+
+// watchDrainingNodes periodically evicts the delegated pubsub subscriptions on draining full nodes
+// if not finished within the subscription grace period, so that subscriptions are migrated to other
+// full nodes once clients reconnected. Besides, the remaining in-flight requests and subscriptions
+// are reported to node manager, which tells whether the full nodes are drained.
+func watchDrainingNodes(space string, provider drainingNodeProvider) {
+	reporter := drainReporterName(space)
+	drainingSince := make(map[string]time.Time) // node URL => first seen draining at
+
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		drainingUrls := provider.ListDrainingNodes()
+		evictDrainingDelegateSubs(expiredDrainingNodes(drainingSince, drainingUrls, now))
+
+		// report even if no draining nodes, so that node manager is aware of this RPC server
+		if err := provider.ReportDrain(reporter, collectDrainCounts(drainingUrls)); err != nil {
+			logrus.WithError(err).Debug("Failed to report drain counts to node manager")
+		}
+	}
+}
+
+// expiredDrainingNodes tracks when the draining nodes are first seen, and returns the URLs of nodes
+// that are draining for longer than the subscription grace period.
+func expiredDrainingNodes(drainingSince map[string]time.Time, drainingUrls []string, now time.Time) (urls []string) {
+	draining := make(map[string]bool, len(drainingUrls))
+
+	for _, url := range drainingUrls {
+		draining[url] = true
+
+		since, ok := drainingSince[url]
+		if !ok {
+			since = now
+			drainingSince[url] = now
+		}
+
+		if now.Sub(since) >= node.SubscriptionGracePeriod() {
+			urls = append(urls, url)
+		}
+	}
+
+	// forget the undrained nodes
+	for url := range drainingSince {
+		if !draining[url] {
+			delete(drainingSince, url)
+		}
+	}
+
+	return urls
+}
+
+// drainReporterName returns the unique name of RPC server process to report drain counts.
+func drainReporterName(space string) string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%v-%v-%v", hostname, os.Getpid(), space)
+}
+
+// collectDrainCounts collects the in-flight requests and delegated subscriptions on draining nodes.
+func collectDrainCounts(drainingUrls []string) map[string]node.DrainCounts {
+	counts := make(map[string]node.DrainCounts, len(drainingUrls))
+
+	for _, url := range drainingUrls {
+		nodeName := rpcutil.Url2NodeName(url)
+		c := node.DrainCounts{InFlight: rpcutil.GetNodeStats(nodeName).Inflight()}
+
+		if v, ok := delegateClients.Load(nodeName); ok {
+			c.Subscriptions = int64(v.(evictableDelegateClient).numDelegateSubs())
+		}
+
+		counts[url] = c
+	}
+
+	return counts
+}
+
+func evictDrainingDelegateSubs(drainingUrls []string) {
+	for _, url := range drainingUrls {
+		nodeName := rpcutil.Url2NodeName(url)
+
+		v, ok := delegateClients.Load(nodeName)
+		if !ok {
+			continue
+		}
+
+		if n := v.(evictableDelegateClient).evictDelegateSubs(errNodeDraining); n > 0 {
+			logrus.WithFields(logrus.Fields{
+				"node":    nodeName,
+				"numSubs": n,
+			}).Info("Evicted delegated pubsub subscriptions on draining node")
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Conflux-Chain/confura/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiredDrainingNodes(t *testing.T) {
+	node.MustInit()
+
+	now := time.Now()
+	drainingSince := make(map[string]time.Time)
+
+	// subscriptions are not evicted once drain started
+	urls := expiredDrainingNodes(drainingSince, []string{"http://node1", "http://node2"}, now)
+	assert.Empty(t, urls)
+
+	// evicted if not finished within grace period
+	now = now.Add(node.SubscriptionGracePeriod())
+	urls = expiredDrainingNodes(drainingSince, []string{"http://node1", "http://node3"}, now)
+	assert.Equal(t, []string{"http://node1"}, urls)
+
+	// undrained nodes are forgotten
+	assert.Len(t, drainingSince, 2)
+	assert.NotContains(t, drainingSince, "http://node2")
+}
@@ -0,0 +1,256 @@
+package rpc
+
+import (
+	"encoding/json"
+
+	"github.com/Conflux-Chain/confura/rpc/handler"
+	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	web3Types "github.com/openweb3/web3go/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// max number of blocks (or epochs for core space) to replay logs for resumable logs subscription
+	maxLogsReplayRange = 10_000
+)
+
+// resumableLog is the notification of resumable logs subscription, which is the log object along
+// with the resume token to resume the subscription right after the log.
+type resumableLog struct {
+	log   interface{}
+	token string
+}
+
+func (l resumableLog) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(l.log)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 2 || data[len(data)-1] != '}' {
+		return nil, errors.New("log must be a JSON object")
+	}
+
+	token, err := json.Marshal(l.token)
+	if err != nil {
+		return nil, err
+	}
+
+	result := append([]byte{}, data[:len(data)-1]...)
+	if len(data) > 2 { // not an empty object
+		result = append(result, ',')
+	}
+
+	result = append(result, `"resumeToken":`...)
+	result = append(result, token...)
+
+	return append(result, '}'), nil
+}
+
+// logsReplay replays the matched event logs from store or fullnode page by page before switching
+// to live delivery for resumable logs subscription, without any gap or duplicate.
+type logsReplay[T types.Log | web3Types.Log] struct {
+	tracker *handler.LogsResumeTracker
+	// fetch page of logs by cursor within the position number range
+	fetch    func(cursor string, from, to uint64) (*handler.LogsPage[T], error)
+	numberOf func(*T) uint64 // position number of log
+
+	fromNumber uint64 // position number to replay from
+	cursor     string // cursor of the next page to replay
+	done       bool   // whether all logs replayed
+
+	// logs up to this position number are replayed rather than delivered from live
+	toNumber uint64
+}
+
+// replayOnce replays a page of logs, and returns true if all logs replayed.
+func (r *logsReplay[T]) replayOnce(notify func(log *T, token string)) (bool, error) {
+	if r.done {
+		return true, nil
+	}
+
+	page, err := r.fetch(r.cursor, r.fromNumber, r.toNumber)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range page.Logs {
+		token, err := r.tracker.Track(r.numberOf(&page.Logs[i]))
+		if err != nil {
+			return false, errors.WithMessage(err, "failed to track replayed log")
+		}
+
+		notify(&page.Logs[i], token)
+	}
+
+	r.cursor, r.done = page.Cursor, len(page.Cursor) == 0
+	return r.done, nil
+}
+
+// replayAll replays the rest logs page by page.
+func (r *logsReplay[T]) replayAll(notify func(log *T, token string)) error {
+	for {
+		if done, err := r.replayOnce(notify); err != nil || done {
+			return err
+		}
+	}
+}
+
+// extend extends the replay up to the specified position number, which resumes from the position
+// right after the last tracked log.
+func (r *logsReplay[T]) extend(toNumber uint64) {
+	if toNumber <= r.toNumber {
+		return
+	}
+
+	if token, ok := r.tracker.Token(); ok {
+		r.cursor = token
+	} else { // no log tracked yet
+		r.cursor, r.fromNumber = "", r.toNumber+1
+	}
+
+	r.toNumber, r.done = toNumber, false
+}
+
+// track tracks the log delivered from live at the position number, and returns the resume token
+// or false if the log has already been replayed.
+func (r *logsReplay[T]) track(number uint64) (string, bool, error) {
+	if number <= r.toNumber {
+		return "", false, nil
+	}
+
+	token, err := r.tracker.Track(number)
+	if err != nil {
+		return "", false, errors.WithMessage(err, "failed to track live log")
+	}
+
+	return token, true, nil
+}
+
+// revert reverts the tracked logs since the position number due to chain reorg, so that logs of
+// the new pivot chain will be delivered from live.
+func (r *logsReplay[T]) revert(number uint64) {
+	r.tracker.Reset()
+
+	if number <= r.toNumber {
+		r.toNumber = max(number, 1) - 1
+	}
+}
+
+// liveLogsBuffer buffers the live logs in background during replay, so that the live subscription
+// never overflows however long the replay lasts. Once the buffer is full, the later live logs are
+// discarded, and shall be replayed from the last tracked position instead.
+type liveLogsBuffer[L any] struct {
+	ch       <-chan L
+	numberOf func(L) uint64
+	capacity int
+
+	quit chan struct{}
+	done chan struct{}
+
+	logs       []L
+	overflowTo uint64 // max position number of the discarded live logs
+}
+
+func startLiveLogsBuffer[L any](ch <-chan L, numberOf func(L) uint64, capacity int) *liveLogsBuffer[L] {
+	b := &liveLogsBuffer[L]{
+		ch:       ch,
+		numberOf: numberOf,
+		capacity: capacity,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go b.run()
+
+	return b
+}
+
+func (b *liveLogsBuffer[L]) run() {
+	defer close(b.done)
+
+	for {
+		select {
+		case <-b.quit:
+			return
+		case log := <-b.ch:
+			if b.overflowTo == 0 && len(b.logs) < b.capacity {
+				b.logs = append(b.logs, log)
+			} else { // keep the order of live logs by discarding all the later ones
+				b.overflowTo = max(b.overflowTo, b.numberOf(log))
+			}
+		}
+	}
+}
+
+// stop stops buffering, and returns the buffered live logs along with the max position number of
+// the discarded live logs if overflowed.
+func (b *liveLogsBuffer[L]) stop() ([]L, uint64) {
+	close(b.quit)
+	<-b.done
+
+	return b.logs, b.overflowTo
+}
+
+// replayWithLive replays the rest logs while the live logs are buffered, and then notifies the
+// buffered live logs. If the live logs buffer overflowed, the replay is extended to cover the
+// discarded live logs until no more live log discarded.
+func replayWithLive[T types.Log | web3Types.Log, L any](
+	r *logsReplay[T],
+	liveCh <-chan L,
+	liveNumber func(L) uint64,
+	bufferSize int,
+	notifyReplayed func(log *T, token string),
+	notifyLive func(L) error,
+) error {
+	for {
+		buffer := startLiveLogsBuffer(liveCh, liveNumber, bufferSize)
+		err := r.replayAll(notifyReplayed)
+		pending, overflowTo := buffer.stop()
+
+		if err != nil {
+			return errors.WithMessage(err, "failed to replay logs")
+		}
+
+		for _, log := range pending {
+			if err := notifyLive(log); err != nil {
+				return errors.WithMessage(err, "failed to notify buffered live log")
+			}
+		}
+
+		if overflowTo <= r.toNumber {
+			return nil
+		}
+
+		// replay the live logs discarded due to buffer overflow
+		r.extend(overflowTo)
+	}
+}
+
+// replayRange validates and returns the position number range to replay logs, which are the
+// blocks (or epochs for core space) up to the latest one of the subscribed full node.
+func replayRange(tracker *handler.LogsResumeTracker, from *uint64, token *string, latest uint64) (
+	fromNumber uint64, cursor string, err error,
+) {
+	switch {
+	case token != nil: // resume token overrides the position number to replay from
+		if fromNumber, err = tracker.Resume(*token); err != nil {
+			return 0, "", err
+		}
+		cursor = *token
+	case from != nil:
+		fromNumber = *from
+	default:
+		return 0, "", errLogsReplayFromRequired
+	}
+
+	if fromNumber > latest {
+		return 0, "", errLogsReplayAheadOfLatest
+	}
+
+	if latest-fromNumber >= maxLogsReplayRange {
+		return 0, "", errLogsReplayRangeTooLarge
+	}
+
+	return fromNumber, cursor, nil
+}
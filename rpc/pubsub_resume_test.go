@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Conflux-Chain/confura/rpc/handler"
+	"github.com/openweb3/web3go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumableLogMarshalJSON(t *testing.T) {
+	log := &types.Log{BlockNumber: 10, Index: 2}
+
+	data, err := json.Marshal(resumableLog{log, "token"})
+	assert.NoError(t, err)
+
+	var result map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "token", result["resumeToken"])
+	assert.Equal(t, "0xa", result["blockNumber"])
+	assert.Equal(t, "0x2", result["logIndex"])
+
+	data, err = json.Marshal(resumableLog{struct{}{}, "token"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"resumeToken":"token"}`, string(data))
+
+	_, err = json.Marshal(resumableLog{"log", "token"})
+	assert.Error(t, err)
+}
+
+func TestLogsReplayRange(t *testing.T) {
+	tracker := handler.NewEthLogsApiHandler(nil).NewLogsResumeTracker("")
+	from := uint64(100)
+
+	_, _, err := replayRange(tracker, nil, nil, 200)
+	assert.Equal(t, errLogsReplayFromRequired, err)
+
+	_, _, err = replayRange(tracker, &from, nil, 99)
+	assert.Equal(t, errLogsReplayAheadOfLatest, err)
+
+	_, _, err = replayRange(tracker, &from, nil, from+maxLogsReplayRange)
+	assert.Equal(t, errLogsReplayRangeTooLarge, err)
+
+	invalidToken := "invalid"
+	_, _, err = replayRange(tracker, &from, &invalidToken, 200)
+	assert.Error(t, err)
+
+	fromNumber, cursor, err := replayRange(tracker, &from, nil, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, from, fromNumber)
+	assert.Empty(t, cursor)
+}
+
+func TestLogsReplayRevert(t *testing.T) {
+	replay := &logsReplay[types.Log]{
+		tracker:  handler.NewEthLogsApiHandler(nil).NewLogsResumeTracker(""),
+		toNumber: 100,
+	}
+
+	// logs replayed already
+	_, ok, err := replay.track(100)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// reverted after replayed position
+	replay.revert(101)
+	assert.Equal(t, uint64(100), replay.toNumber)
+
+	// logs of the new pivot chain will be delivered from live
+	replay.revert(90)
+	assert.Equal(t, uint64(89), replay.toNumber)
+
+	replay.revert(0)
+	assert.Equal(t, uint64(0), replay.toNumber)
+}
+
+type memLogsCursorStore struct{}
+
+func (memLogsCursorStore) GetReorgVersion() (int, error) { return 0, nil }
+
+func (memLogsCursorStore) PivotHash(number uint64) (string, bool, error) { return "0x01", true, nil }
+
+// newMemLogsReplay creates a logs replay of the logs in memory.
+func newMemLogsReplay(
+	t *testing.T, logs []types.Log, filterHash string, from *uint64, token *string, latest uint64,
+) *logsReplay[types.Log] {
+	tracker := handler.NewLogsResumeTracker(memLogsCursorStore{}, filterHash)
+
+	fromNumber, cursor, err := replayRange(tracker, from, token, latest)
+	assert.NoError(t, err)
+
+	return &logsReplay[types.Log]{
+		tracker: tracker,
+		fetch: func(cursor string, from, to uint64) (*handler.LogsPage[types.Log], error) {
+			q := &handler.LogsPageQuery[types.Log]{
+				FilterHash: filterHash,
+				From:       from,
+				To:         to,
+				Cursor:     cursor,
+				Limit:      3,
+				GetLogs: func(from, to uint64) (result []types.Log, _ bool, _ error) {
+					for _, log := range logs {
+						if log.BlockNumber >= from && log.BlockNumber <= to {
+							result = append(result, log)
+						}
+					}
+					return result, true, nil
+				},
+				SuggestedTo: func(err error) (uint64, bool) { return 0, false },
+				NumberOf:    func(log *types.Log) uint64 { return log.BlockNumber },
+			}
+
+			page, _, err := handler.GetLogsPage(context.Background(), memLogsCursorStore{}, q)
+			return page, err
+		},
+		numberOf:   func(log *types.Log) uint64 { return log.BlockNumber },
+		fromNumber: fromNumber,
+		cursor:     cursor,
+		toNumber:   latest,
+	}
+}
+
+func TestLogsReplayResumeWithLiveOverflow(t *testing.T) {
+	// 2 logs per block of block 1 ~ 25
+	var logs []types.Log
+	for bn := uint64(1); bn <= 25; bn++ {
+		logs = append(logs, types.Log{BlockNumber: bn, Index: 0}, types.Log{BlockNumber: bn, Index: 1})
+	}
+
+	filterHash := handler.HashLogsFilter(&types.FilterQuery{})
+
+	// the first subscription dropped after 9 logs notified
+	from := uint64(1)
+	replay := newMemLogsReplay(t, logs, filterHash, &from, nil, 20)
+
+	var notified []types.Log
+	var token string
+	for len(notified) < 9 {
+		_, err := replay.replayOnce(func(log *types.Log, logToken string) {
+			if len(notified) < 9 {
+				notified, token = append(notified, *log), logToken
+			}
+		})
+		assert.NoError(t, err)
+	}
+
+	// resume with the resume token of the last notified log, while logs of block 21 ~ 25 delivered
+	// from live during replay, which overflow the live logs buffer
+	replay = newMemLogsReplay(t, logs, filterHash, nil, &token, 20)
+
+	liveCh := make(chan *types.Log, len(logs))
+	for i := 40; i < len(logs); i++ {
+		liveCh <- &logs[i]
+	}
+
+	fetch := replay.fetch
+	replay.fetch = func(cursor string, from, to uint64) (*handler.LogsPage[types.Log], error) {
+		// wait for all the live logs buffered or discarded
+		assert.Eventually(t, func() bool { return len(liveCh) == 0 }, time.Second, time.Millisecond)
+		return fetch(cursor, from, to)
+	}
+
+	err := replayWithLive(
+		replay, liveCh, func(log *types.Log) uint64 { return log.BlockNumber }, 3,
+		func(log *types.Log, _ string) { notified = append(notified, *log) },
+		func(log *types.Log) error {
+			if _, ok, err := replay.track(log.BlockNumber); err != nil || !ok {
+				return err
+			}
+
+			notified = append(notified, *log)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+
+	// all logs notified without gap or duplicate
+	assert.Equal(t, logs, notified)
+	assert.Equal(t, uint64(25), replay.toNumber)
+}
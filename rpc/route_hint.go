@@ -0,0 +1,193 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Conflux-Chain/confura/node"
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/go-rpc-provider"
+)
+
+const (
+	// session header to pin a client to a minimum block (or epoch) number, e.g., the latest block
+	// number that client has seen, in hex or decimal format
+	headerMinBlock = "X-Min-Block"
+
+	ctxKeyMinBlock = handlers.CtxKey("Infura-Min-Block")
+
+	// length of hex encoded 32 bytes hash with `0x` prefix
+	hexHashLength = 66
+)
+
+// blockParamIndexes are RPC methods mapped to the index of the block (or epoch) number, block hash
+// or transaction hash parameter, which is used to hint the router to prefer full nodes that have
+// reached the block height.
+var blockParamIndexes = map[string]int{
+	// core space
+	"cfx_epochNumber":                       0,
+	"cfx_getBalance":                        1,
+	"cfx_getNextNonce":                      1,
+	"cfx_getCode":                           1,
+	"cfx_getStorageAt":                      2,
+	"cfx_getAccount":                        1,
+	"cfx_getAdmin":                          1,
+	"cfx_getSponsorInfo":                    1,
+	"cfx_getStakingBalance":                 1,
+	"cfx_getCollateralForStorage":           1,
+	"cfx_call":                              1,
+	"cfx_estimateGasAndCollateral":          1,
+	"cfx_getBlockByHash":                    0,
+	"cfx_getBlockByEpochNumber":             0,
+	"cfx_getBlocksByEpoch":                  0,
+	"cfx_getEpochReceipts":                  0,
+	"cfx_getTransactionByHash":              0,
+	"cfx_getTransactionReceipt":             0,
+	"cfx_getLogs":                           0,
+	"cfx_getBlockByHashWithPivotAssumption": 2,
+	// evm space
+	"eth_getBalance":                          1,
+	"eth_getTransactionCount":                 1,
+	"eth_getCode":                             1,
+	"eth_getStorageAt":                        2,
+	"eth_call":                                1,
+	"eth_estimateGas":                         1,
+	"eth_feeHistory":                          1,
+	"eth_getBlockByHash":                      0,
+	"eth_getBlockByNumber":                    0,
+	"eth_getBlockReceipts":                    0,
+	"eth_getBlockTransactionCountByHash":      0,
+	"eth_getBlockTransactionCountByNumber":    0,
+	"eth_getTransactionByBlockHashAndIndex":   0,
+	"eth_getTransactionByBlockNumberAndIndex": 0,
+	"eth_getTransactionByHash":                0,
+	"eth_getTransactionReceipt":               0,
+	"eth_getLogs":                             0,
+}
+
+// stateMethods are RPC methods that query state at the block (or epoch) parameter, which may require
+// archive full nodes for deep history.
+var stateMethods = map[string]bool{
+	// core space
+	"cfx_getBalance":               true,
+	"cfx_getNextNonce":             true,
+	"cfx_getCode":                  true,
+	"cfx_getStorageAt":             true,
+	"cfx_getAccount":               true,
+	"cfx_getAdmin":                 true,
+	"cfx_getSponsorInfo":           true,
+	"cfx_getStakingBalance":        true,
+	"cfx_getCollateralForStorage":  true,
+	"cfx_call":                     true,
+	"cfx_estimateGasAndCollateral": true,
+	// evm space
+	"eth_getBalance":          true,
+	"eth_getTransactionCount": true,
+	"eth_getCode":             true,
+	"eth_getStorageAt":        true,
+	"eth_call":                true,
+	"eth_estimateGas":         true,
+}
+
+// capableNamespaces are optional RPC namespaces that are not supported by all full nodes.
+var capableNamespaces = map[string]bool{
+	"trace":  true,
+	"debug":  true,
+	"txpool": true,
+}
+
+// parseMinBlockHeader parses the min block number from the session header.
+func parseMinBlockHeader(r *http.Request) (uint64, bool) {
+	val := strings.TrimSpace(r.Header.Get(headerMinBlock))
+	if len(val) == 0 {
+		return 0, false
+	}
+
+	if num, err := hexutil.DecodeUint64(val); err == nil {
+		return num, true
+	}
+
+	if num, err := strconv.ParseUint(val, 10, 64); err == nil {
+		return num, true
+	}
+
+	return 0, false
+}
+
+// newRouteHint creates the route hint by the RPC method, block parameter of RPC call and the session
+// min block.
+func newRouteHint(ctx context.Context, msg *rpc.JsonRpcMessage) node.RouteHint {
+	var hint node.RouteHint
+
+	if idx, ok := blockParamIndexes[msg.Method]; ok {
+		var params []json.RawMessage
+		if err := json.Unmarshal(msg.Params, &params); err == nil && idx < len(params) {
+			hint = parseBlockParam(params[idx])
+		} else if err == nil { // omitted block parameter defaults to the latest block tag
+			hint.Synced = true
+		}
+	}
+
+	if stateMethods[msg.Method] {
+		hint.StateEpoch = hint.MinHeight
+	}
+
+	if namespace, _, ok := strings.Cut(msg.Method, "_"); ok && capableNamespaces[namespace] {
+		hint.Namespace = namespace
+	}
+
+	if minBlock, ok := ctx.Value(ctxKeyMinBlock).(uint64); ok {
+		hint.MinHeight = max(hint.MinHeight, minBlock)
+	}
+
+	return hint
+}
+
+// parseBlockParam parses the route hint from block parameter, which could be block tag, block number,
+// block hash, transaction hash, EIP-1898 style object or log filter.
+func parseBlockParam(param json.RawMessage) (hint node.RouteHint) {
+	var str string
+	if err := json.Unmarshal(param, &str); err == nil {
+		if len(str) == hexHashLength && strings.HasPrefix(str, "0x") { // block or transaction hash
+			hint.Synced = true
+		} else if num, err := hexutil.DecodeUint64(str); err == nil { // block number
+			hint.MinHeight = num
+		} else if str != "earliest" { // block tags, e.g., `latest`, `safe` or `finalized`
+			hint.Synced = true
+		}
+
+		return hint
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(param, &obj); err != nil {
+		return hint
+	}
+
+	// EIP-1898 style block hash or log filter by block hashes
+	if _, ok := obj["blockHash"]; ok {
+		hint.Synced = true
+		return hint
+	}
+
+	var hashes []string
+	if err := json.Unmarshal(obj["blockHashes"], &hashes); err == nil && len(hashes) > 0 {
+		hint.Synced = true
+		return hint
+	}
+
+	// EIP-1898 style block number or the upper bound of log filter
+	for _, field := range []string{"blockNumber", "epochNumber", "toBlock", "toEpoch"} {
+		if val, ok := obj[field]; ok {
+			return parseBlockParam(val)
+		}
+	}
+
+	// upper bound of log filter defaults to the latest block tag
+	hint.Synced = true
+	return hint
+}
@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Conflux-Chain/confura/node"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBlockParam(t *testing.T) {
+	hash := `"0x4b2e9c1d7e24a3f0c5b0e3a1f0b7c2d9e8f1a6b5c4d3e2f1a0b9c8d7e6f5a4b3"`
+
+	testCases := []struct {
+		param string
+		hint  node.RouteHint
+	}{
+		{`"latest"`, node.RouteHint{Synced: true}},
+		{`"finalized"`, node.RouteHint{Synced: true}},
+		{`"latest_state"`, node.RouteHint{Synced: true}},
+		{`"earliest"`, node.RouteHint{}},
+		{`"0x64"`, node.RouteHint{MinHeight: 100}},
+		{hash, node.RouteHint{Synced: true}},
+		{`{"blockNumber": "0x64"}`, node.RouteHint{MinHeight: 100}},
+		{`{"blockHash": ` + hash + `}`, node.RouteHint{Synced: true}},
+		{`{"fromBlock": "0x1", "toBlock": "0x64"}`, node.RouteHint{MinHeight: 100}},
+		{`{"fromEpoch": "0x1", "toEpoch": "latest_state"}`, node.RouteHint{Synced: true}},
+		{`{"blockNumber": "safe"}`, node.RouteHint{Synced: true}},
+		{`{"fromBlock": "0x1"}`, node.RouteHint{Synced: true}},
+		{`{"blockHashes": [` + hash + `]}`, node.RouteHint{Synced: true}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.hint, parseBlockParam(json.RawMessage(tc.param)), tc.param)
+	}
+}
+
+func TestNewRouteHint(t *testing.T) {
+	msg := &rpc.JsonRpcMessage{
+		Method: "eth_getBalance",
+		Params: json.RawMessage(`["0x0000000000000000000000000000000000000001", "0x64"]`),
+	}
+	assert.Equal(t, node.RouteHint{MinHeight: 100, StateEpoch: 100}, newRouteHint(context.Background(), msg))
+
+	// pinned by session min block
+	ctx := context.WithValue(context.Background(), ctxKeyMinBlock, uint64(200))
+	assert.Equal(t, node.RouteHint{MinHeight: 200, StateEpoch: 100}, newRouteHint(ctx, msg))
+
+	msg = &rpc.JsonRpcMessage{Method: "eth_blockNumber"}
+	assert.Equal(t, node.RouteHint{MinHeight: 200}, newRouteHint(ctx, msg))
+	assert.True(t, newRouteHint(context.Background(), msg).IsEmpty())
+}
+
+func TestNewRouteHintWithCapability(t *testing.T) {
+	msg := &rpc.JsonRpcMessage{
+		Method: "debug_traceTransaction",
+		Params: json.RawMessage(`["0x4b2e9c1d7e24a3f0c5b0e3a1f0b7c2d9e8f1a6b5c4d3e2f1a0b9c8d7e6f5a4b3"]`),
+	}
+	assert.Equal(t, node.RouteHint{Namespace: "debug"}, newRouteHint(context.Background(), msg))
+
+	msg = &rpc.JsonRpcMessage{
+		Method: "eth_call",
+		Params: json.RawMessage(`[{"to": "0x0000000000000000000000000000000000000001"}, "latest"]`),
+	}
+	assert.Equal(t, node.RouteHint{Synced: true}, newRouteHint(context.Background(), msg))
+
+	// omitted block parameter defaults to the latest block tag
+	msg = &rpc.JsonRpcMessage{
+		Method: "eth_getBalance",
+		Params: json.RawMessage(`["0x0000000000000000000000000000000000000001"]`),
+	}
+	assert.Equal(t, node.RouteHint{Synced: true}, newRouteHint(context.Background(), msg))
+}
@@ -3,8 +3,11 @@ package rpc
 import (
 	infuraNode "github.com/Conflux-Chain/confura/node"
 	"github.com/Conflux-Chain/confura/rpc/handler"
+	"github.com/Conflux-Chain/confura/util/accesslog"
 	"github.com/Conflux-Chain/confura/util/rate"
 	"github.com/Conflux-Chain/confura/util/rpc"
+	"github.com/Conflux-Chain/confura/util/rpc/cache"
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
 	"github.com/sirupsen/logrus"
 )
 
@@ -29,7 +32,7 @@ func MustNewNativeSpaceServer(
 	option ...CfxAPIOption,
 ) *rpc.Server {
 	// retrieve all available core space rpc apis
-	allApis := nativeSpaceApis(clientProvider, gashandler, option...)
+	allApis := nativeSpaceApis(registry, clientProvider, gashandler, option...)
 
 	exposedApis, err := filterExposedApis(allApis, exposedModules)
 	if err != nil {
@@ -38,9 +41,20 @@ func MustNewNativeSpaceServer(
 		)
 	}
 
-	middleware := httpMiddleware(registry, clientProvider)
+	var respCache *cache.ResponseCache
+	var accessLogger *accesslog.Logger
+	if len(option) > 0 {
+		respCache = option[0].ResponseCache
+		accessLogger = option[0].AccessLogger
+	}
+
+	clientProvider.SetHedgePolicy(newHedgePolicy())
+	middleware := httpMiddleware(registry, clientProvider, respCache, accessLogger)
+
+	// migrate pubsub subscriptions from draining full nodes
+	go watchDrainingNodes("cfx", clientProvider)
 
-	return rpc.MustNewServer(nativeSpaceRpcServerName, exposedApis, middleware)
+	return rpc.MustNewServer(nativeSpaceRpcServerName, exposedApis, middleware, handlers.RateLimitHeaders)
 }
 
 // MustNewEvmSpaceServer new evm space RPC server by specifying router, and exposed modules.
@@ -54,7 +68,7 @@ func MustNewEvmSpaceServer(
 	option ...EthAPIOption,
 ) *rpc.Server {
 	// retrieve all available evm space rpc apis
-	allApis, err := evmSpaceApis(clientProvider, gasHandler, option...)
+	allApis, err := evmSpaceApis(registry, clientProvider, gasHandler, option...)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to new EVM space RPC server")
 	}
@@ -66,9 +80,20 @@ func MustNewEvmSpaceServer(
 		)
 	}
 
-	middleware := httpMiddleware(registry, clientProvider)
+	var respCache *cache.ResponseCache
+	var accessLogger *accesslog.Logger
+	if len(option) > 0 {
+		respCache = option[0].ResponseCache
+		accessLogger = option[0].AccessLogger
+	}
+
+	clientProvider.SetHedgePolicy(newHedgePolicy())
+	middleware := httpMiddleware(registry, clientProvider, respCache, accessLogger)
+
+	// migrate pubsub subscriptions from draining full nodes
+	go watchDrainingNodes("eth", clientProvider)
 
-	return rpc.MustNewServer(evmSpaceRpcServerName, exposedApis, middleware)
+	return rpc.MustNewServer(evmSpaceRpcServerName, exposedApis, middleware, handlers.RateLimitHeaders)
 }
 
 type CfxBridgeServerConfig struct {
@@ -89,8 +114,8 @@ func MustNewNativeSpaceBridgeServer(registry *rate.Registry, config *CfxBridgeSe
 		logrus.WithError(err).Fatal("Failed to new CFX bridge RPC server with bad exposed modules")
 	}
 
-	middleware := httpMiddleware(registry, nil)
-	return rpc.MustNewServer(nativeSpaceBridgeRpcServerName, exposedApis, middleware)
+	middleware := httpMiddleware(registry, nil, nil, nil)
+	return rpc.MustNewServer(nativeSpaceBridgeRpcServerName, exposedApis, middleware, handlers.RateLimitHeaders)
 }
 
 // MustNewDebugServer new debug RPC server for internal debugging use.
@@ -6,11 +6,17 @@ import (
 
 	"github.com/Conflux-Chain/confura/node"
 	"github.com/Conflux-Chain/confura/rpc/handler"
+	"github.com/Conflux-Chain/confura/util/accesslog"
 	"github.com/Conflux-Chain/confura/util/rate"
+	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
+	"github.com/Conflux-Chain/confura/util/rpc/cache"
 	"github.com/Conflux-Chain/confura/util/rpc/handlers"
 	"github.com/Conflux-Chain/confura/util/rpc/middlewares"
+	"github.com/Conflux-Chain/confura/util/tracing"
 	sdk "github.com/Conflux-Chain/go-conflux-sdk"
 	"github.com/openweb3/go-rpc-provider"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 const (
@@ -26,24 +32,44 @@ func MustInit() {
 	// init metrics
 	initMetrics()
 
+	// init retry policy for idempotent RPC calls
+	mustInitRetryConfig()
+
 	// Register middlewares for go-rpc-provider, which only supports static middlewares for RPC server.
 	// The following middlewares are executed in order.
 
 	// panic recovery
 	rpc.HookHandleCallMsg(middlewares.Recover)
 
+	// tracing
+	rpc.HookHandleBatch(middlewares.TracingBatch)
+	rpc.HookHandleCallMsg(middlewares.Tracing)
+
 	// anti-injection
-	rpc.HookHandleCallMsg(middlewares.AntiInjection)
+	rpc.HookHandleCallMsg(middlewares.Traced("antiInjection", middlewares.AntiInjection))
 
 	// auth
-	rpc.HookHandleCallMsg(middlewares.Auth())
+	rpc.HookHandleCallMsg(middlewares.Traced("auth", middlewares.Auth()))
+
+	// access log, which also records requests rejected by the following middlewares
+	rpc.HookHandleCallMsg(middlewares.AccessLog)
+
+	// per-key usages, including requests rejected by the following middlewares
+	rpc.HookHandleCallMsg(middlewares.Usage)
 
 	// allow lists
-	rpc.HookHandleCallMsg(middlewares.Allowlists)
+	rpc.HookHandleCallMsg(middlewares.Traced("allowlists", middlewares.Allowlists))
+
+	// batch size, timeout and up front rate limit controls
+	rpc.HookHandleBatch(middlewares.Batch())
+	rpc.HookHandleCallMsg(middlewares.Traced("batchCall", middlewares.BatchCall))
+
+	// resource caps, e.g., getLogs bounds
+	rpc.HookHandleCallMsg(middlewares.Traced("strategyCaps", middlewares.StrategyCaps))
 
 	// rate limit
-	rpc.HookHandleCallMsg(middlewares.DailyMaxReqRateLimit)
-	rpc.HookHandleCallMsg(middlewares.QpsRateLimit)
+	rpc.HookHandleCallMsg(middlewares.Traced("dailyMaxReqRateLimit", middlewares.DailyMaxReqRateLimit))
+	rpc.HookHandleCallMsg(middlewares.Traced("qpsRateLimit", middlewares.QpsRateLimit))
 
 	// metrics
 	rpc.HookHandleBatch(middlewares.MetricsBatch)
@@ -53,6 +79,9 @@ func MustInit() {
 	rpc.HookHandleBatch(middlewares.LogBatch)
 	rpc.HookHandleCallMsg(middlewares.Log)
 
+	// response cache for immutable RPC calls
+	rpc.HookHandleCallMsg(middlewares.Traced("responseCache", middlewares.ResponseCache))
+
 	// cfx/eth client
 	rpc.HookHandleCallMsg(clientMiddleware)
 
@@ -66,10 +95,14 @@ func MustInit() {
 }
 
 // Inject values into context for static RPC call middlewares, e.g. rate limit
-func httpMiddleware(registry *rate.Registry, clientProvider interface{}) handlers.Middleware {
+func httpMiddleware(
+	registry *rate.Registry, clientProvider interface{},
+	respCache *cache.ResponseCache, accessLogger *accesslog.Logger,
+) handlers.Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
+			// continue the trace from the incoming `traceparent` header if any
+			ctx := tracing.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 
 			if token := handlers.GetAccessToken(r); len(token) > 0 { // optional
 				ctx = context.WithValue(ctx, handlers.CtxKeyAccessToken, token)
@@ -79,6 +112,10 @@ func httpMiddleware(registry *rate.Registry, clientProvider interface{}) handler
 			ctx = context.WithValue(ctx, handlers.CtxKeyUserAgent, r.Header.Get("User-Agent"))
 			ctx = context.WithValue(ctx, handlers.CtxKeyRealIP, handlers.GetIPAddress(r))
 
+			if minBlock, ok := parseMinBlockHeader(r); ok { // optional
+				ctx = context.WithValue(ctx, ctxKeyMinBlock, minBlock)
+			}
+
 			if registry != nil {
 				ctx = context.WithValue(ctx, handlers.CtxKeyRateRegistry, registry)
 			}
@@ -87,6 +124,14 @@ func httpMiddleware(registry *rate.Registry, clientProvider interface{}) handler
 				ctx = context.WithValue(ctx, ctxKeyClientProvider, clientProvider)
 			}
 
+			if respCache != nil {
+				ctx = context.WithValue(ctx, handlers.CtxKeyResponseCache, respCache)
+			}
+
+			if accessLogger != nil {
+				ctx = accesslog.NewContext(ctx, accessLogger)
+			}
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -98,23 +143,88 @@ func clientMiddleware(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
 		var grp node.Group
 		var err error
 
+		// prefer full nodes that have reached the block height, so that clients never see the chain
+		// go backwards when routed to different full nodes
+		if hint := newRouteHint(ctx, msg); !hint.IsEmpty() {
+			ctx = node.NewContextWithRouteHint(ctx, hint)
+		}
+
+		_, span := tracing.Start(ctx, "node.select")
+
 		if cfxProvider, ok := ctx.Value(ctxKeyClientProvider).(*node.CfxClientProvider); ok {
 			client, grp, err = getCfxClientFromProviderWithContext(ctx, msg.Method, cfxProvider)
 		} else if ethProvider, ok := ctx.Value(ctxKeyClientProvider).(*node.EthClientProvider); ok {
 			client, grp, err = getEthClientFromProviderWithContext(ctx, msg.Method, ethProvider)
 		} else {
+			span.End()
 			return next(ctx, msg)
 		}
 
+		if span.IsRecording() {
+			span.SetAttributes(attribute.String("node.group", string(grp)))
+			if err == nil {
+				span.SetAttributes(attribute.String("node.name", clientNodeName(client)))
+			}
+		}
+
+		tracing.End(span, err)
+
 		if err != nil { // no fullnode available to request RPC
 			return msg.ErrorResponse(err)
 		}
 
-		ctx = context.WithValue(ctx, ctxKeyClient, client)
-		ctx = context.WithValue(ctx, ctxKeyClientGroup, grp)
+		invoke := func(ctx context.Context, client interface{}) *rpc.JsonRpcMessage {
+			ctx = context.WithValue(ctx, ctxKeyClient, withCallContext(ctx, client))
+			ctx = context.WithValue(ctx, ctxKeyClientGroup, grp)
+			accesslog.SetNode(ctx, clientNodeName(client), string(grp))
+
+			return next(ctx, msg)
+		}
+
+		if !isRetryableMethod(msg.Method) {
+			return invoke(ctx, client)
+		}
+
+		// retry on other full nodes of the same group in case of transport error or timeout
+		return invokeWithRetry(ctx, msg, invoke, client, func() []interface{} {
+			return getAlternativeClients(ctx.Value(ctxKeyClientProvider), grp, client)
+		})
+	}
+}
+
+// withCallContext returns a copy of the full node client that carries the trace context and access
+// log entry of the RPC call, so that outbound full node calls are traced as children of the RPC call,
+// and the full node that served the hedged request is access logged. It returns the client as it is
+// if neither tracing nor access log enabled.
+func withCallContext(ctx context.Context, client interface{}) interface{} {
+	if _, logged := accesslog.FromContext(ctx); !logged && !tracing.Enabled() {
+		return client
+	}
 
-		return next(ctx, msg)
+	// only propagate the trace context and access log entry, full node calls are not cancelled
+	// along with RPC call
+	callCtx := accesslog.Propagate(tracing.Detach(ctx), ctx)
+
+	switch c := client.(type) {
+	case *sdk.Client:
+		return c.WithContext(callCtx)
+	case *node.Web3goClient:
+		return &node.Web3goClient{Client: c.Client.WithContext(callCtx), URL: c.URL}
 	}
+
+	return client
+}
+
+// clientNodeName returns the node name of full node client.
+func clientNodeName(client interface{}) string {
+	switch c := client.(type) {
+	case sdk.ClientOperator:
+		return rpcutil.Url2NodeName(c.GetNodeURL())
+	case *node.Web3goClient:
+		return c.NodeName()
+	}
+
+	return ""
 }
 
 func GetCfxClientFromContext(ctx context.Context) sdk.ClientOperator {
@@ -134,7 +244,7 @@ func getEthClientFromProviderWithContext(
 	grp := node.GroupEthHttp
 
 	switch {
-	case rpcMethod == rpcMethodEthGetLogs:
+	case rpcMethod == rpcMethodEthGetLogs, rpcMethod == rpcMethodEthGetLogsPaged:
 		grp = node.GroupEthLogs
 	case isEthFilterRpcMethod(rpcMethod):
 		grp = node.GroupEthFilter
@@ -142,7 +252,8 @@ func getEthClientFromProviderWithContext(
 		if authId, ok := handlers.GetAuthIdFromContext(ctx); ok {
 			grp, ok := p.GetRouteGroup(authId)
 			if ok && len(grp) > 0 {
-				client, err := p.GetClient(authId, grp)
+				hint, _ := node.RouteHintFromContext(ctx)
+				client, err := p.GetClientWithHint(authId, hint, grp)
 				return client, grp, err
 			}
 		}
@@ -157,7 +268,7 @@ func getCfxClientFromProviderWithContext(
 	grp := node.GroupCfxHttp
 
 	switch {
-	case rpcMethod == rpcMethodCfxGetLogs:
+	case rpcMethod == rpcMethodCfxGetLogs, rpcMethod == rpcMethodCfxGetLogsPaged:
 		grp = node.GroupCfxLogs
 	case isCfxFilterRpcMethod(rpcMethod):
 		grp = node.GroupCfxFilter
@@ -165,7 +276,8 @@ func getCfxClientFromProviderWithContext(
 		if authId, ok := handlers.GetAuthIdFromContext(ctx); ok {
 			grp, ok := p.GetRouteGroup(authId)
 			if ok && len(grp) > 0 {
-				client, err := p.GetClient(authId, grp)
+				hint, _ := node.RouteHintFromContext(ctx)
+				client, err := p.GetClientWithHint(authId, hint, grp)
 				return client, grp, err
 			}
 		}
@@ -0,0 +1,215 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Conflux-Chain/confura/node"
+	"github.com/Conflux-Chain/confura/util/metrics"
+	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
+	"github.com/Conflux-Chain/confura/util/rpc/middlewares"
+	sdk "github.com/Conflux-Chain/go-conflux-sdk"
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/openweb3/go-rpc-provider"
+	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
+	"github.com/openweb3/go-rpc-provider/utils"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	retryCfg retryConfig
+
+	// patterns of connection error messages in case of the error types lost when wrapped, note that
+	// general patterns like `timeout` are excluded, which also match store errors, e.g., getLogs timeout.
+	transportErrorPatterns = []string{
+		"connection refused", "connection reset", "broken pipe", "no such host", "server closed connection",
+	}
+)
+
+// retryConfig policy to retry idempotent RPC calls on another full node of the same group when the
+// routed full node returns a transport error or timeout.
+type retryConfig struct {
+	Enabled bool
+	// max number of retries on other full nodes
+	MaxRetries int `default:"1"`
+	// latency threshold to send a hedged request to another full node, 0 to disable hedging
+	HedgeDelay time.Duration
+	// namespace => allowlist of read-only methods, `*` for all methods of the namespace
+	Methods map[string][]string
+}
+
+func mustInitRetryConfig() {
+	viper.MustUnmarshalKey("requestControl.retry", &retryCfg)
+	logrus.WithField("config", retryCfg).Debug("RPC retry policy initialized")
+}
+
+// isRetryableMethod checks if the RPC method is allowed to retry on another full node, but never
+// for transactions sending or filter related methods which are not idempotent.
+func isRetryableMethod(method string) bool {
+	if !retryCfg.Enabled || isCfxFilterRpcMethod(method) || isEthFilterRpcMethod(method) {
+		return false
+	}
+
+	switch method {
+	case "cfx_sendRawTransaction", "cfx_sendTransaction", "eth_sendRawTransaction", "eth_sendTransaction":
+		return false
+	}
+
+	namespace, _, ok := strings.Cut(method, "_")
+	if !ok {
+		return false
+	}
+
+	for _, m := range retryCfg.Methods[namespace] {
+		if m == "*" || m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRetryableResponse checks if the RPC response is failed due to transport error or timeout.
+func isRetryableResponse(resp *rpc.JsonRpcMessage) bool {
+	if resp == nil || resp.Error == nil {
+		return false
+	}
+
+	if err := resp.Error.Inner(); err != nil {
+		return isTransportError(err)
+	}
+
+	return false
+}
+
+func isTransportError(err error) bool {
+	if utils.IsRPCJSONError(err) { // responded by full node
+		return false
+	}
+
+	if errors.Is(err, middlewares.ErrorServerTooBusy) ||
+		errors.Is(err, providers.ErrCircuitOpen) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, fasthttp.ErrConnectionClosed) ||
+		errors.Is(err, fasthttp.ErrNoFreeConns) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+
+	errMsg := strings.ToLower(err.Error())
+	for _, pattern := range transportErrorPatterns {
+		if strings.Contains(errMsg, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newHedgePolicy returns the policy to send hedged requests for the retryable methods, or nil if
+// hedging disabled.
+func newHedgePolicy() *rpcutil.HedgePolicy {
+	if !retryCfg.Enabled || retryCfg.HedgeDelay <= 0 {
+		return nil
+	}
+
+	return &rpcutil.HedgePolicy{
+		Delay:       retryCfg.HedgeDelay,
+		IsHedgeable: isRetryableMethod,
+	}
+}
+
+// callInvoker invokes the RPC call with the specified full node client.
+type callInvoker func(ctx context.Context, client interface{}) *rpc.JsonRpcMessage
+
+// invokeWithRetry invokes the RPC call with the routed full node client at first, and retries on
+// the alternative full node clients of the same group one by one if failed due to transport error
+// or timeout. Note, it never retries once the RPC call itself is cancelled or timed out.
+func invokeWithRetry(
+	ctx context.Context, msg *rpc.JsonRpcMessage, invoke callInvoker,
+	client interface{}, alternatives func() []interface{},
+) *rpc.JsonRpcMessage {
+	resp := invoke(ctx, client)
+
+	var others []interface{}
+	var fetched bool
+	var retries int
+	defer func() {
+		metrics.Registry.RPC.Percentage(msg.Method, "retry").Mark(retries > 0)
+	}()
+
+	for ; retries < retryCfg.MaxRetries && ctx.Err() == nil && isRetryableResponse(resp); retries++ {
+		if !fetched {
+			others, fetched = alternatives(), true
+		}
+
+		if len(others) == 0 {
+			break
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"method":  msg.Method,
+			"attempt": retries + 1,
+		}).WithError(resp.Error).Debug("Retry RPC call on another full node")
+
+		resp, others = invoke(ctx, others[0]), others[1:]
+	}
+
+	return resp
+}
+
+// getAlternativeClients returns the full node clients of the same group except the routed one.
+func getAlternativeClients(clientProvider interface{}, grp node.Group, routed interface{}) (res []interface{}) {
+	switch p := clientProvider.(type) {
+	case *node.CfxClientProvider:
+		clients, err := p.GetClientsByGroup(grp)
+		if err != nil {
+			logrus.WithField("group", grp).WithError(err).Debug("Failed to get alternative core space clients")
+			return nil
+		}
+
+		routedUrl := routed.(sdk.ClientOperator).GetNodeURL()
+		for _, c := range clients {
+			if c.GetNodeURL() != routedUrl {
+				res = append(res, c)
+			}
+		}
+	case *node.EthClientProvider:
+		clients, err := p.GetClientsByGroup(grp)
+		if err != nil {
+			logrus.WithField("group", grp).WithError(err).Debug("Failed to get alternative evm space clients")
+			return nil
+		}
+
+		routedUrl := routed.(*node.Web3goClient).URL
+		for _, c := range clients {
+			if c.URL != routedUrl {
+				res = append(res, c)
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/Conflux-Chain/confura/store"
+	"github.com/Conflux-Chain/confura/util/rpc/middlewares"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryableMethod(t *testing.T) {
+	defer func(cfg retryConfig) { retryCfg = cfg }(retryCfg)
+
+	retryCfg = retryConfig{
+		Enabled: true,
+		Methods: map[string][]string{
+			"cfx": {"cfx_getBalance"},
+			"eth": {"*"},
+		},
+	}
+
+	assert.True(t, isRetryableMethod("cfx_getBalance"))
+	assert.False(t, isRetryableMethod("cfx_call"))
+	assert.False(t, isRetryableMethod("cfx_sendRawTransaction"))
+
+	assert.True(t, isRetryableMethod("eth_call"))
+	assert.False(t, isRetryableMethod("eth_sendRawTransaction"))
+	assert.False(t, isRetryableMethod("eth_getFilterChanges"))
+
+	assert.False(t, isRetryableMethod("trace_block"))
+
+	retryCfg.Enabled = false
+	assert.False(t, isRetryableMethod("cfx_getBalance"))
+}
+
+func TestIsTransportError(t *testing.T) {
+	assert.True(t, isTransportError(errors.WithMessage(io.EOF, "failed to read response")))
+	assert.True(t, isTransportError(context.DeadlineExceeded))
+	assert.True(t, isTransportError(middlewares.ErrorServerTooBusy))
+	assert.True(t, isTransportError(errors.New("dial tcp 127.0.0.1:12537: connect: connection refused")))
+
+	assert.True(t, isTransportError(errors.WithMessage(syscall.ECONNRESET, "failed to read")))
+	assert.True(t, isTransportError(&url.Error{Op: "Post", URL: "http://127.0.0.1", Err: io.ErrUnexpectedEOF}))
+
+	assert.False(t, isTransportError(errors.New("invalid epoch number")))
+	assert.False(t, isTransportError(store.ErrGetLogsTimeout))
+	assert.False(t, isTransportError(errors.New("operation timed out")))
+	assert.False(t, isTransportError(&rpc.JsonError{Code: -32000, Message: "execution timeout"}))
+}
+
+func TestInvokeWithRetryCallerTimeout(t *testing.T) {
+	defer func(cfg retryConfig) { retryCfg = cfg }(retryCfg)
+	retryCfg = retryConfig{Enabled: true, MaxRetries: 2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	msg := &rpc.JsonRpcMessage{ID: []byte(`1`), Method: "eth_getBalance"}
+
+	var invoked int
+	invoke := func(ctx context.Context, client interface{}) *rpc.JsonRpcMessage {
+		invoked++
+		cancel() // caller gave up while calling full node
+		return msg.ErrorResponse(io.EOF)
+	}
+
+	alternatives := func() []interface{} { return []interface{}{"node2", "node3"} }
+
+	invokeWithRetry(ctx, msg, invoke, "node1", alternatives)
+	assert.Equal(t, 1, invoked)
+}
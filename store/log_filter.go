@@ -20,14 +20,14 @@ const (
 
 	// max timeout to get event logs from store
 	TimeoutGetLogs = 3 * time.Second
+
+	errFilterResultSetTooLargeFmt = "the result set exceeds the max limit of %v logs, please narrow down your filter conditions"
 )
 
 var ( // common errors
 	ErrFilterQuerySetTooLarge = errors.New("the query set is too large, please narrow down your filter condition")
 
-	ErrFilterResultSetTooLarge = errors.Errorf(
-		"the result set exceeds the max limit of %v logs, please narrow down your filter conditions", MaxLogLimit,
-	)
+	ErrFilterResultSetTooLarge = errors.Errorf(errFilterResultSetTooLargeFmt, MaxLogLimit)
 
 	ErrGetLogsTimeout = errors.Errorf(
 		"the query timed out after exceeding the maximum duration of %v seconds", TimeoutGetLogs,
@@ -59,6 +59,41 @@ func IsBoundChecksEnabled(ctx context.Context) bool {
 	return ctx.Value(boundChecksDisabledKey) == nil
 }
 
+const logLimitsKey contextKey = "Log-Limits"
+
+// LogLimits bounds for getLogs, which could be customized per request, e.g., by rate limit strategy.
+type LogLimits struct {
+	MaxLogs          uint64 // max number of event logs to return
+	MaxEpochRange    uint64 // max epoch range for fullnode delegation
+	MaxBlockRange    uint64 // max block range for fullnode delegation
+	MaxResponseBytes uint64 // max number of bytes for the response body (0 means default)
+}
+
+// NewContextWithLogLimits returns a context that overrides the default bounds for getLogs,
+// any zero value field falls back to the default one.
+func NewContextWithLogLimits(ctx context.Context, limits LogLimits) context.Context {
+	return context.WithValue(ctx, logLimitsKey, limits)
+}
+
+// GetLogLimits returns the bounds for getLogs from context, defaults to the configured ones.
+func GetLogLimits(ctx context.Context) LogLimits {
+	limits, _ := ctx.Value(logLimitsKey).(LogLimits)
+
+	if limits.MaxLogs == 0 {
+		limits.MaxLogs = MaxLogLimit
+	}
+
+	if limits.MaxEpochRange == 0 {
+		limits.MaxEpochRange = MaxLogEpochRange
+	}
+
+	if limits.MaxBlockRange == 0 {
+		limits.MaxBlockRange = MaxLogBlockRange
+	}
+
+	return limits
+}
+
 type SuggestedBlockRange struct {
 	citypes.RangeUint64
 	// the maximum possible epoch for suggesting an epoch range
@@ -103,12 +138,22 @@ func NewSuggestedFilterQuerySetTooLargeError[T SuggestedFilterRange](suggestedRa
 	return NewSuggestedFilterOversizeError(ErrFilterQuerySetTooLarge, *suggestedRange)
 }
 
-func NewSuggestedFilterResultSetTooLargeError[T SuggestedFilterRange](suggestedRange *T) error {
-	if suggestedRange == nil {
+// NewFilterResultSetTooLargeError returns an error indicating that the result set exceeds the max limit of logs.
+func NewFilterResultSetTooLargeError(maxLogs uint64) error {
+	if maxLogs == MaxLogLimit {
 		return ErrFilterResultSetTooLarge
 	}
 
-	return NewSuggestedFilterOversizeError(ErrFilterResultSetTooLarge, *suggestedRange)
+	return errors.Errorf(errFilterResultSetTooLargeFmt, maxLogs)
+}
+
+func NewSuggestedFilterResultSetTooLargeError[T SuggestedFilterRange](maxLogs uint64, suggestedRange *T) error {
+	inner := NewFilterResultSetTooLargeError(maxLogs)
+	if suggestedRange == nil {
+		return inner
+	}
+
+	return NewSuggestedFilterOversizeError(inner, *suggestedRange)
 }
 
 func NewSuggestedFilterOversizeError[T SuggestedFilterRange](inner error, suggestedRange T) *SuggestedFilterOversizedError[T] {
@@ -21,14 +21,23 @@ var allModels = []interface{}{
 	&block{},
 	&conf{},
 	&RateLimit{},
+	&RateLimitUsage{},
 	&User{},
 	&Contract{},
 	&epochBlockMap{},
 	&bnPartition{},
 	&NodeRoute{},
+	&reorgJournal{},
+	&accessLog{},
 	&dlock.Dlock{},
 }
 
+// table models introduced after the database created, which are created if absent
+var lateModels = []interface{}{
+	&reorgJournal{},
+	&accessLog{},
+}
+
 // Config represents the mysql configurations to open a database instance.
 type Config struct {
 	Enabled bool
@@ -99,6 +108,17 @@ func (config *Config) MustOpenOrCreate(option StoreOption) *MysqlStore {
 		}
 
 		newCreated = (len(tables) == 0)
+
+		// create tables introduced after the database created
+		for _, model := range lateModels {
+			if newCreated || db.Migrator().HasTable(model) {
+				continue
+			}
+
+			if err := db.Migrator().CreateTable(model); err != nil {
+				logrus.WithError(err).WithField("model", fmt.Sprintf("%T", model)).Fatal("Failed to create table")
+			}
+		}
 	}
 
 	if newCreated {
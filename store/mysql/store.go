@@ -9,7 +9,10 @@ import (
 	"github.com/Conflux-Chain/confura/store"
 	citypes "github.com/Conflux-Chain/confura/types"
 	"github.com/Conflux-Chain/confura/util/metrics"
+	"github.com/Conflux-Chain/confura/util/tracing"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -35,6 +38,8 @@ type MysqlStore struct {
 	*RateLimitStore
 	*VirtualFilterLogStore
 	*NodeRouteStore
+	*ReorgJournalStore
+	*AccessLogStore
 	ls   *logStore
 	ails *AddressIndexedLogStore
 	bcls *bigContractLogStore
@@ -64,6 +69,8 @@ func mustNewStore(db *gorm.DB, config *Config, option StoreOption) *MysqlStore {
 		RateLimitStore:        NewRateLimitStore(db),
 		VirtualFilterLogStore: NewVirtualFilterLogStore(db),
 		NodeRouteStore:        NewNodeRouteStore(db),
+		ReorgJournalStore:     NewReorgJournalStore(db),
+		AccessLogStore:        NewAccessLogStore(db),
 		ls:                    newLogStore(db, cs, ebms, pruner.newBnPartitionObsChan),
 		bcls:                  newBigContractLogStore(db, cs, ebms, ails, pruner.newBnPartitionObsChan),
 		ails:                  ails,
@@ -211,6 +218,12 @@ func (ms *MysqlStore) PopnWithFinalizer(epochUntil uint64, finalizer func(*gorm.
 	startTime := time.Now()
 	defer metrics.Registry.Store.Pop("mysql").UpdateSince(startTime)
 
+	// pivot hashes of the replaced epochs to journal reorg
+	replacedHashes, err := ms.replacedPivotHashes(epochUntil, maxEpoch)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get replaced pivot hashes")
+	}
+
 	return ms.baseStore.db.Transaction(func(dbTx *gorm.DB) error {
 		if !ms.disabler.IsChainBlockDisabled() {
 			// remove blocks
@@ -256,6 +269,11 @@ func (ms *MysqlStore) PopnWithFinalizer(epochUntil uint64, finalizer func(*gorm.
 			return errors.WithMessage(err, "failed to update reorg version")
 		}
 
+		depth := maxEpoch - epochUntil + 1
+		if err := ms.ReorgJournalStore.add(dbTx, epochUntil, depth, replacedHashes); err != nil {
+			return errors.WithMessage(err, "failed to journal reorg")
+		}
+
 		if finalizer != nil {
 			return finalizer(dbTx)
 		}
@@ -264,10 +282,45 @@ func (ms *MysqlStore) PopnWithFinalizer(epochUntil uint64, finalizer func(*gorm.
 	})
 }
 
+// replacedPivotHashes returns the pivot hashes of popped epochs in ascending order, which are
+// truncated if too many.
+func (ms *MysqlStore) replacedPivotHashes(epochFrom, epochTo uint64) ([]string, error) {
+	epochTo = min(epochTo, epochFrom+maxReorgJournalHashes-1)
+
+	pivotHashes, err := ms.PivotHashes(epochFrom, epochTo)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(pivotHashes))
+	for epoch := epochFrom; epoch <= epochTo; epoch++ {
+		if hash, ok := pivotHashes[epoch]; ok {
+			hashes = append(hashes, hash)
+		}
+	}
+
+	return hashes, nil
+}
+
 func (ms *MysqlStore) GetLogs(ctx context.Context, storeFilter store.LogFilter) ([]*store.Log, error) {
 	startTime := time.Now()
 	defer metrics.Registry.Store.GetLogs().UpdateSince(startTime)
 
+	ctx, span := tracing.Start(ctx, "store.getLogs", trace.WithAttributes(
+		attribute.Int64("store.blockFrom", int64(storeFilter.BlockFrom)),
+		attribute.Int64("store.blockTo", int64(storeFilter.BlockTo)),
+		attribute.Int("store.contracts", storeFilter.Contracts.Count()),
+	))
+
+	logs, err := ms.getLogs(ctx, storeFilter)
+
+	span.SetAttributes(attribute.Int("store.logs", len(logs)))
+	tracing.End(span, err)
+
+	return logs, err
+}
+
+func (ms *MysqlStore) getLogs(ctx context.Context, storeFilter store.LogFilter) ([]*store.Log, error) {
 	contracts := storeFilter.Contracts.ToSlice()
 
 	// if address not specified, query from universal event log table partition
@@ -282,6 +335,8 @@ func (ms *MysqlStore) GetLogs(ctx context.Context, storeFilter store.LogFilter)
 		Topics:    storeFilter.Topics,
 	}
 
+	maxLogs := store.GetLogLimits(ctx).MaxLogs
+
 	var result []*store.Log
 	for _, addr := range contracts {
 		// convert contract address to id
@@ -310,8 +365,8 @@ func (ms *MysqlStore) GetLogs(ctx context.Context, storeFilter store.LogFilter)
 			result = append(result, logs...)
 
 			// check log count
-			if store.IsBoundChecksEnabled(ctx) && len(result) > int(store.MaxLogLimit) {
-				return nil, newSuggestedFilterResultSetTooLargeError(&storeFilter, result, true)
+			if store.IsBoundChecksEnabled(ctx) && len(result) > int(maxLogs) {
+				return nil, newSuggestedFilterResultSetTooLargeError(&storeFilter, result, maxLogs, true)
 			}
 
 			continue
@@ -341,8 +396,8 @@ func (ms *MysqlStore) GetLogs(ctx context.Context, storeFilter store.LogFilter)
 		}
 
 		// check log count
-		if store.IsBoundChecksEnabled(ctx) && len(result) > int(store.MaxLogLimit) {
-			return nil, newSuggestedFilterResultSetTooLargeError(&storeFilter, result, false)
+		if store.IsBoundChecksEnabled(ctx) && len(result) > int(maxLogs) {
+			return nil, newSuggestedFilterResultSetTooLargeError(&storeFilter, result, maxLogs, false)
 		}
 	}
 
@@ -352,6 +407,19 @@ func (ms *MysqlStore) GetLogs(ctx context.Context, storeFilter store.LogFilter)
 	return result, nil
 }
 
+// GetReceiptsByEpoch returns all the executed transaction receipts of the specified epoch.
+func (ms *MysqlStore) GetReceiptsByEpoch(ctx context.Context, epochNumber uint64) ([]*store.TransactionReceipt, error) {
+	// An epoch without any transaction is indistinguishable from an unsynced one within the
+	// transaction table, so make sure the epoch is already synced into the store at first.
+	if _, existed, err := ms.BlockRange(epochNumber); err != nil {
+		return nil, err
+	} else if !existed {
+		return nil, store.ErrNotFound
+	}
+
+	return ms.txStore.loadReceiptsByEpoch(epochNumber)
+}
+
 // Prune prune data from db store.
 func (ms *MysqlStore) Prune() {
 	go ms.pruner.schedulePrune(ms.config)
@@ -362,9 +430,12 @@ func (ms *MysqlStore) Prune() {
 //
 // Parameters:
 // - filter: the log filter used for querying logs.
-// - resultLogs: the list of logs retrieved from the query, make sure it is more than `store.MaxLogLimit` long.
+// - resultLogs: the list of logs retrieved from the query, make sure it is more than `maxLogs` long.
+// - maxLogs: the max number of logs allowed for the query.
 // - sorted: whether the logs are already sorted by block number.
-func newSuggestedFilterResultSetTooLargeError(filter *store.LogFilter, resultLogs []*store.Log, sorted bool) error {
+func newSuggestedFilterResultSetTooLargeError(
+	filter *store.LogFilter, resultLogs []*store.Log, maxLogs uint64, sorted bool,
+) error {
 	// Ensure logs are sorted by block number if not already sorted.
 	if !sorted {
 		sort.Sort(store.LogSlice(resultLogs))
@@ -372,10 +443,10 @@ func newSuggestedFilterResultSetTooLargeError(filter *store.LogFilter, resultLog
 
 	// Determine if we need to suggest a narrower block range based on the exceeding log entry
 	var suggestedBlockRange *store.SuggestedBlockRange
-	if exceedingLog := resultLogs[store.MaxLogLimit]; exceedingLog.BlockNumber > filter.BlockFrom {
+	if exceedingLog := resultLogs[maxLogs]; exceedingLog.BlockNumber > filter.BlockFrom {
 		blockRange := store.NewSuggestedBlockRange(filter.BlockFrom, exceedingLog.BlockNumber-1, exceedingLog.Epoch)
 		suggestedBlockRange = &blockRange
 	}
 
-	return store.NewSuggestedFilterResultSetTooLargeError(suggestedBlockRange)
+	return store.NewSuggestedFilterResultSetTooLargeError(maxLogs, suggestedBlockRange)
 }
@@ -0,0 +1,74 @@
+package mysql
+
+import (
+	"time"
+
+	citypes "github.com/Conflux-Chain/confura/types"
+	"gorm.io/gorm"
+)
+
+// accessLog is the structured access log of RPC call.
+type accessLog struct {
+	ID           uint64
+	Time         time.Time `gorm:"index;not null"`
+	Space        string    `gorm:"size:8;not null"`
+	Method       string    `gorm:"size:64;index;not null"`
+	AuthKey      string    `gorm:"size:128;index"`
+	VipTier      int       `gorm:"not null;default:0"`
+	IP           string    `gorm:"size:64;index"`
+	Node         string    `gorm:"size:256"`
+	NodeGroup    string    `gorm:"size:64"`
+	LatencyMs    int64     `gorm:"not null;default:0"`
+	ResponseSize int       `gorm:"not null;default:0"`
+	StoreHit     *bool
+	ErrorCode    int `gorm:"not null;default:0"`
+}
+
+func (accessLog) TableName() string {
+	return "access_logs"
+}
+
+// AccessLogStore persists structured access logs of RPC calls.
+type AccessLogStore struct {
+	*baseStore
+}
+
+func NewAccessLogStore(db *gorm.DB) *AccessLogStore {
+	return &AccessLogStore{
+		baseStore: newBaseStore(db),
+	}
+}
+
+// AddAccessLogs adds access logs in batch.
+func (als *AccessLogStore) AddAccessLogs(logs []*citypes.AccessLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	records := make([]*accessLog, 0, len(logs))
+	for _, l := range logs {
+		records = append(records, &accessLog{
+			Time:         l.Time,
+			Space:        l.Space,
+			Method:       l.Method,
+			AuthKey:      l.Key,
+			VipTier:      l.VipTier,
+			IP:           l.IP,
+			Node:         l.Node,
+			NodeGroup:    l.Group,
+			LatencyMs:    l.Latency,
+			ResponseSize: l.ResponseSize,
+			StoreHit:     l.StoreHit,
+			ErrorCode:    l.ErrorCode,
+		})
+	}
+
+	return als.db.CreateInBatches(records, 200).Error
+}
+
+// PruneAccessLogs deletes at most `limit` access logs recorded before the specified time, and returns
+// the number of deleted access logs.
+func (als *AccessLogStore) PruneAccessLogs(before time.Time, limit int) (int64, error) {
+	res := als.db.Exec("DELETE FROM access_logs WHERE time < ? LIMIT ?", before, limit)
+	return res.RowsAffected, res.Error
+}
@@ -28,6 +28,10 @@ const (
 	// pre-defined node route group config key prefix
 	NodeRouteGroupConfKeyPrefix   = "noderoute.group."
 	nodeRouteGroupSqlMatchPattern = NodeRouteGroupConfKeyPrefix + "%"
+
+	// pre-defined node maintenance config key prefix
+	NodeMaintenanceConfKeyPrefix   = "node.maintenance."
+	nodeMaintenanceSqlMatchPattern = NodeMaintenanceConfKeyPrefix + "%"
 )
 
 // configuration tables
@@ -334,3 +338,46 @@ func (cs *confStore) decodeNodeRouteGroup(cfg conf) (*NodeRouteGroup, error) {
 
 	return &grp, nil
 }
+
+// node maintenance config
+
+type NodeMaintenance struct {
+	Name  string    `json:"-"`     // node name
+	Url   string    `json:"url"`   // node url
+	Since time.Time `json:"since"` // when node started to drain
+}
+
+func (cs *confStore) StoreNodeMaintenance(maint *NodeMaintenance) error {
+	cfgVal, err := json.Marshal(maint)
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal node maintenance")
+	}
+
+	cfgKey := NodeMaintenanceConfKeyPrefix + maint.Name
+	return cs.StoreConfig(cfgKey, string(cfgVal))
+}
+
+func (cs *confStore) DelNodeMaintenance(name string) error {
+	cfgKey := NodeMaintenanceConfKeyPrefix + name
+	_, err := cs.DeleteConfig(cfgKey)
+	return err
+}
+
+func (cs *confStore) LoadNodeMaintenances() (res []*NodeMaintenance, err error) {
+	var cfgs []conf
+	if err := cs.db.Where("name LIKE ?", nodeMaintenanceSqlMatchPattern).Find(&cfgs).Error; err != nil {
+		return nil, err
+	}
+
+	for _, v := range cfgs {
+		maint := NodeMaintenance{Name: v.Name[len(NodeMaintenanceConfKeyPrefix):]}
+		if err := json.Unmarshal([]byte(v.Value), &maint); err != nil {
+			logrus.WithField("cfg", v).WithError(err).Warn("Invalid node maintenance config")
+			continue
+		}
+
+		res = append(res, &maint)
+	}
+
+	return res, nil
+}
@@ -206,8 +206,8 @@ func (ls *logStore) GetLogs(ctx context.Context, storeFilter store.LogFilter) ([
 		}
 
 		// check log count
-		if store.IsBoundChecksEnabled(ctx) && len(result) > int(store.MaxLogLimit) {
-			return nil, newSuggestedFilterResultSetTooLargeError(&storeFilter, result, true)
+		if maxLogs := store.GetLogLimits(ctx).MaxLogs; store.IsBoundChecksEnabled(ctx) && len(result) > int(maxLogs) {
+			return nil, newSuggestedFilterResultSetTooLargeError(&storeFilter, result, maxLogs, true)
 		}
 	}
 
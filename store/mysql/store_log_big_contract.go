@@ -409,8 +409,8 @@ func (bcls *bigContractLogStore) GetContractLogs(
 		}
 
 		// check log count
-		if store.IsBoundChecksEnabled(ctx) && len(result) > int(store.MaxLogLimit) {
-			return nil, newSuggestedFilterResultSetTooLargeError(&storeFilter, result, true)
+		if maxLogs := store.GetLogLimits(ctx).MaxLogs; store.IsBoundChecksEnabled(ctx) && len(result) > int(maxLogs) {
+			return nil, newSuggestedFilterResultSetTooLargeError(&storeFilter, result, maxLogs, true)
 		}
 	}
 
@@ -149,7 +149,7 @@ func (filter *LogFilter) suggestBlockRange(db *gorm.DB, queryRange types.RangeUi
 }
 
 // validateQuerySetSize checks if the query set size exceeds limits, suggesting a narrower range if necessary.
-func (filter *LogFilter) validateQuerySetSize(db *gorm.DB) error {
+func (filter *LogFilter) validateQuerySetSize(db *gorm.DB, maxLogs uint64) error {
 	// estimate the query range and log count in the dataset
 	queryRange, numLogs, err := filter.calculateQuerySetSize(db)
 	if err != nil {
@@ -170,33 +170,33 @@ func (filter *LogFilter) validateQuerySetSize(db *gorm.DB) error {
 	}
 
 	// check if result set exceeds limit
-	if numLogs > store.MaxLogLimit {
+	if numLogs > maxLogs {
 		// suggest a narrower range if no topics filter is applied
 		if !filter.hasTopicsFilter() {
-			suggestedRange, err := filter.suggestBlockRange(db, queryRange, store.MaxLogLimit)
+			suggestedRange, err := filter.suggestBlockRange(db, queryRange, maxLogs)
 			if err != nil {
 				return err
 			}
-			return store.NewSuggestedFilterResultSetTooLargeError(suggestedRange)
+			return store.NewSuggestedFilterResultSetTooLargeError(maxLogs, suggestedRange)
 		}
 
 		// otherwise validate the count directly
-		return filter.validateCount(db)
+		return filter.validateCount(db, maxLogs)
 	}
 
 	return nil
 }
 
-// validateCount validates the result set count against the configured max limit.
-func (filter *LogFilter) validateCount(db *gorm.DB) error {
+// validateCount validates the result set count against the specified max limit.
+func (filter *LogFilter) validateCount(db *gorm.DB, maxLogs uint64) error {
 	db = db.Select("bn, epoch").
 		Table(filter.TableName).
 		Where("bn BETWEEN ? AND ?", filter.BlockFrom, filter.BlockTo).
 		Order("bn ASC").
-		Offset(int(store.MaxLogLimit))
+		Offset(int(maxLogs))
 	db = applyTopicsFilter(db, filter.Topics)
 
-	// fetch info on the first block exceeding `store.MaxLogLimit`
+	// fetch info on the first block exceeding `maxLogs`
 	var exceedingBlock struct{ Bn, Epoch uint64 }
 	err := db.Take(&exceedingBlock).Error
 	if err != nil {
@@ -211,10 +211,10 @@ func (filter *LogFilter) validateCount(db *gorm.DB) error {
 		blockRange := store.NewSuggestedBlockRange(
 			filter.BlockFrom, exceedingBlock.Bn-1, exceedingBlock.Epoch,
 		)
-		return store.NewSuggestedFilterResultSetTooLargeError(&blockRange)
+		return store.NewSuggestedFilterResultSetTooLargeError(maxLogs, &blockRange)
 	}
 
-	return store.ErrFilterResultSetTooLarge
+	return store.NewFilterResultSetTooLargeError(maxLogs)
 }
 
 func (filter *LogFilter) hasTopicsFilter() bool {
@@ -228,8 +228,10 @@ func (filter *LogFilter) hasTopicsFilter() bool {
 }
 
 func (filter *LogFilter) find(ctx context.Context, db *gorm.DB, destSlicePtr interface{}) error {
+	maxLogs := store.GetLogLimits(ctx).MaxLogs
+
 	if store.IsBoundChecksEnabled(ctx) {
-		if err := filter.validateQuerySetSize(db); err != nil {
+		if err := filter.validateQuerySetSize(db, maxLogs); err != nil {
 			return err
 		}
 	}
@@ -238,7 +240,7 @@ func (filter *LogFilter) find(ctx context.Context, db *gorm.DB, destSlicePtr int
 	db = db.Where("bn BETWEEN ? AND ?", filter.BlockFrom, filter.BlockTo)
 	db = applyTopicsFilter(db, filter.Topics)
 	db = db.Order("bn ASC")
-	db = db.Limit(int(store.MaxLogLimit) + 1)
+	db = db.Limit(int(maxLogs) + 1)
 
 	return db.Find(destSlicePtr).Error
 }
@@ -250,14 +252,16 @@ type AddressIndexedLogFilter struct {
 	ContractId uint64
 }
 
-func (filter *AddressIndexedLogFilter) validateCount(db *gorm.DB) error {
+func (filter *AddressIndexedLogFilter) validateCount(db *gorm.DB, maxLogs uint64) error {
 	db = db.Where("cid = ?", filter.ContractId)
-	return filter.LogFilter.validateCount(db)
+	return filter.LogFilter.validateCount(db, maxLogs)
 }
 
 func (filter *AddressIndexedLogFilter) Find(ctx context.Context, db *gorm.DB) ([]*AddressIndexedLog, error) {
+	maxLogs := store.GetLogLimits(ctx).MaxLogs
+
 	if store.IsBoundChecksEnabled(ctx) {
-		if err := filter.validateCount(db); err != nil {
+		if err := filter.validateCount(db, maxLogs); err != nil {
 			return nil, err
 		}
 	}
@@ -266,7 +270,7 @@ func (filter *AddressIndexedLogFilter) Find(ctx context.Context, db *gorm.DB) ([
 		Where("cid = ?", filter.ContractId).
 		Where("bn BETWEEN ? AND ?", filter.BlockFrom, filter.BlockTo).
 		Order("bn ASC").
-		Limit(int(store.MaxLogLimit) + 1)
+		Limit(int(maxLogs) + 1)
 	db = applyTopicsFilter(db, filter.Topics)
 
 	var result []*AddressIndexedLog
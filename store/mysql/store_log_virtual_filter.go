@@ -60,7 +60,7 @@ func (filter *vfLogFilter) validateCount(db *gorm.DB) error {
 		db = db.Where("bh IN (?)", filter.BlockHashes)
 	}
 
-	return filter.LogFilter.validateCount(db)
+	return filter.LogFilter.validateCount(db, store.MaxLogLimit)
 }
 
 func (filter *vfLogFilter) Find(db *gorm.DB) ([]VirtualFilterLog, error) {
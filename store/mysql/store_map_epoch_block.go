@@ -161,6 +161,25 @@ func (e2bms *epochBlockMapStore) PivotHash(epoch uint64) (string, bool, error) {
 	return e2bmap.PivotHash, existed, nil
 }
 
+// PivotHashes returns the pivot hashes of epochs within the given range, keyed by epoch number.
+func (e2bms *epochBlockMapStore) PivotHashes(epochFrom, epochTo uint64) (map[uint64]string, error) {
+	var e2bmaps []epochBlockMap
+
+	err := e2bms.db.Select("epoch", "pivot_hash").
+		Where("epoch >= ? AND epoch <= ?", epochFrom, epochTo).
+		Find(&e2bmaps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	pivotHashes := make(map[uint64]string, len(e2bmaps))
+	for _, e2bmap := range e2bmaps {
+		pivotHashes[e2bmap.Epoch] = e2bmap.PivotHash
+	}
+
+	return pivotHashes, nil
+}
+
 // Add batch saves epoch to block mapping data to db store.
 func (e2bms *epochBlockMapStore) Add(dbTx *gorm.DB, dataSlice []*store.EpochData) error {
 	var mappings []*epochBlockMap
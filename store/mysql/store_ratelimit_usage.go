@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/Conflux-Chain/confura/util/rate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateLimitUsage hourly aggregated usage of rate limit key per RPC method
+type RateLimitUsage struct {
+	ID             uint64
+	LimitKey       string    `gorm:"size:128;not null;uniqueIndex:uidx_key_hour_method,priority:1"` // limit key
+	Hour           time.Time `gorm:"not null;uniqueIndex:uidx_key_hour_method,priority:2"`          // start of hourly bucket
+	Method         string    `gorm:"size:64;not null;uniqueIndex:uidx_key_hour_method,priority:3"`  // RPC method
+	Requests       uint64    `gorm:"not null;default:0"`                                            // total requests
+	Errors         uint64    `gorm:"not null;default:0"`                                            // total errors
+	TotalLatencyMs uint64    `gorm:"not null;default:0"`                                            // total latency (ms)
+	MaxLatencyMs   uint64    `gorm:"not null;default:0"`                                            // max latency (ms)
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (RateLimitUsage) TableName() string {
+	return "ratelimit_usages"
+}
+
+// AddRateLimitUsages accumulates the hourly usages of rate limit keys.
+func (rls *RateLimitStore) AddRateLimitUsages(usages []*rate.Usage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+
+	records := make([]*RateLimitUsage, 0, len(usages))
+	for _, u := range usages {
+		records = append(records, &RateLimitUsage{
+			LimitKey:       u.LimitKey,
+			Hour:           u.Hour,
+			Method:         u.Method,
+			Requests:       u.Requests,
+			Errors:         u.Errors,
+			TotalLatencyMs: u.TotalLatencyMs,
+			MaxLatencyMs:   u.MaxLatencyMs,
+		})
+	}
+
+	return rls.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":         gorm.Expr("requests + VALUES(requests)"),
+			"errors":           gorm.Expr("errors + VALUES(errors)"),
+			"total_latency_ms": gorm.Expr("total_latency_ms + VALUES(total_latency_ms)"),
+			"max_latency_ms":   gorm.Expr("GREATEST(max_latency_ms, VALUES(max_latency_ms))"),
+			"updated_at":       gorm.Expr("CURRENT_TIMESTAMP"),
+		}),
+	}).CreateInBatches(records, 200).Error
+}
+
+// LoadRateLimitUsages loads hourly usages of rate limit key with the specified filter.
+func (rls *RateLimitStore) LoadRateLimitUsages(filter *rate.UsageFilter) ([]*rate.Usage, error) {
+	db := rls.db.Where("limit_key = ?", filter.LimitKey)
+
+	if len(filter.Methods) > 0 {
+		db = db.Where("method IN (?)", filter.Methods)
+	}
+
+	if !filter.From.IsZero() {
+		db = db.Where("hour >= ?", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		db = db.Where("hour <= ?", filter.To)
+	}
+
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+
+	var records []*RateLimitUsage
+	if err := db.Order("hour ASC, method ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	usages := make([]*rate.Usage, 0, len(records))
+	for _, r := range records {
+		usages = append(usages, &rate.Usage{
+			LimitKey:       r.LimitKey,
+			Method:         r.Method,
+			Hour:           r.Hour,
+			Requests:       r.Requests,
+			Errors:         r.Errors,
+			TotalLatencyMs: r.TotalLatencyMs,
+			MaxLatencyMs:   r.MaxLatencyMs,
+		})
+	}
+
+	return usages, nil
+}
@@ -0,0 +1,113 @@
+package mysql
+
+import (
+	"encoding/json"
+	"time"
+
+	citypes "github.com/Conflux-Chain/confura/types"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	// max number of replaced hashes recorded in a reorg journal entry
+	maxReorgJournalHashes = 1000
+)
+
+// reorgJournal records chain reorg whenever the synced epochs are popped due to pivot chain switch.
+type reorgJournal struct {
+	ID uint64
+	// the first replaced epoch
+	ForkPoint uint64 `gorm:"index;not null"`
+	// number of replaced epochs
+	Depth uint64 `gorm:"not null"`
+	// JSON array of replaced pivot block hashes, truncated if too many
+	ReplacedHashes string `gorm:"type:mediumtext;not null"`
+	CreatedAt      time.Time
+}
+
+func (reorgJournal) TableName() string {
+	return "reorg_journals"
+}
+
+func (rj *reorgJournal) toReorg() (*citypes.Reorg, error) {
+	var hashes []string
+	if err := json.Unmarshal([]byte(rj.ReplacedHashes), &hashes); err != nil {
+		return nil, errors.WithMessage(err, "invalid replaced hashes json")
+	}
+
+	return &citypes.Reorg{
+		ID:             rj.ID,
+		ForkPoint:      rj.ForkPoint,
+		Depth:          rj.Depth,
+		ReplacedHashes: hashes,
+		Timestamp:      rj.CreatedAt,
+	}, nil
+}
+
+// ReorgJournalStore journals chain reorgs so that clients could be notified.
+type ReorgJournalStore struct {
+	*baseStore
+}
+
+func NewReorgJournalStore(db *gorm.DB) *ReorgJournalStore {
+	return &ReorgJournalStore{
+		baseStore: newBaseStore(db),
+	}
+}
+
+// add journals the chain reorg which replaced epochs since the fork point.
+func (rjs *ReorgJournalStore) add(dbTx *gorm.DB, forkPoint, depth uint64, hashes []string) error {
+	if len(hashes) > maxReorgJournalHashes {
+		hashes = hashes[:maxReorgJournalHashes]
+	}
+
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal replaced hashes")
+	}
+
+	return dbTx.Create(&reorgJournal{
+		ForkPoint:      forkPoint,
+		Depth:          depth,
+		ReplacedHashes: string(data),
+	}).Error
+}
+
+// GetReorgs returns at most `limit` reorgs in ascending order, which replaced any epoch since
+// the specified epoch.
+func (rjs *ReorgJournalStore) GetReorgs(fromEpoch uint64, limit int) ([]*citypes.Reorg, error) {
+	return rjs.find(rjs.db.Where("fork_point + depth > ?", fromEpoch), limit)
+}
+
+// GetReorgsAfter returns at most `limit` reorgs in ascending order, which journaled after the
+// specified journal ID.
+func (rjs *ReorgJournalStore) GetReorgsAfter(id uint64, limit int) ([]*citypes.Reorg, error) {
+	return rjs.find(rjs.db.Where("id > ?", id), limit)
+}
+
+// LatestReorgID returns the ID of the latest journaled reorg, or 0 if no reorg journaled.
+func (rjs *ReorgJournalStore) LatestReorgID() (uint64, error) {
+	var id uint64
+	err := rjs.db.Model(&reorgJournal{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+func (rjs *ReorgJournalStore) find(db *gorm.DB, limit int) ([]*citypes.Reorg, error) {
+	var journals []reorgJournal
+	if err := db.Order("id ASC").Limit(limit).Find(&journals).Error; err != nil {
+		return nil, err
+	}
+
+	reorgs := make([]*citypes.Reorg, 0, len(journals))
+	for i := range journals {
+		reorg, err := journals[i].toReorg()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid reorg journal #%v", journals[i].ID)
+		}
+
+		reorgs = append(reorgs, reorg)
+	}
+
+	return reorgs, nil
+}
@@ -142,6 +142,64 @@ func (ts *txStore) GetReceipt(ctx context.Context, txHash types.Hash) (*store.Tr
 	}, nil
 }
 
+// GetReceipts returns the persisted receipts of the specified transactions by hash, and those not
+// persisted yet are absent from the result.
+func (ts *txStore) GetReceipts(ctx context.Context, txHashes []types.Hash) (map[types.Hash]*store.TransactionReceipt, error) {
+	hashIds := make([]uint64, len(txHashes))
+	hashes := make([]string, len(txHashes))
+
+	for i := range txHashes {
+		hashes[i] = txHashes[i].String()
+		hashIds[i] = util.GetShortIdOfHash(hashes[i])
+	}
+
+	var txs []*transaction
+	if err := ts.db.Where("hash_id IN ? AND hash IN ?", hashIds, hashes).Find(&txs).Error; err != nil {
+		return nil, err
+	}
+
+	receipts := make(map[types.Hash]*store.TransactionReceipt, len(txs))
+	for _, tx := range txs {
+		if len(tx.ReceiptRawData) == 0 { // receipt not persisted
+			continue
+		}
+
+		var receipt types.TransactionReceipt
+		util.MustUnmarshalRLP(tx.ReceiptRawData, &receipt)
+
+		receipts[types.Hash(tx.Hash)] = &store.TransactionReceipt{
+			CfxReceipt: &receipt, Extra: tx.parseTxReceiptExtra(),
+		}
+	}
+
+	return receipts, nil
+}
+
+// loadReceiptsByEpoch loads all the executed transaction receipts of the specified epoch
+// in the order of execution.
+func (ts *txStore) loadReceiptsByEpoch(epochNumber uint64) ([]*store.TransactionReceipt, error) {
+	var txs []*transaction
+	if err := ts.db.Where("epoch = ?", epochNumber).Order("id ASC").Find(&txs).Error; err != nil {
+		return nil, err
+	}
+
+	receipts := make([]*store.TransactionReceipt, 0, len(txs))
+	for _, tx := range txs {
+		if len(tx.ReceiptRawData) == 0 { // receipt not persisted
+			return nil, store.ErrNotFound
+		}
+
+		var receipt types.TransactionReceipt
+		util.MustUnmarshalRLP(tx.ReceiptRawData, &receipt)
+
+		receipts = append(receipts, &store.TransactionReceipt{
+			CfxReceipt: &receipt, Extra: tx.parseTxReceiptExtra(),
+		})
+	}
+
+	return receipts, nil
+}
+
 // Add batch save epoch transactions into db store.
 func (ts *txStore) Add(dbTx *gorm.DB, dataSlice []*store.EpochData, skipTx, skipRcpt bool) error {
 	if skipTx && skipRcpt {
@@ -140,6 +140,11 @@ func (rs *RedisStore) GetReceipt(ctx context.Context, txHash types.Hash) (*store
 	return &store.TransactionReceipt{CfxReceipt: receipt}, nil
 }
 
+func (rs *RedisStore) GetReceiptsByEpoch(ctx context.Context, epochNumber uint64) ([]*store.TransactionReceipt, error) {
+	// Receipts are not indexed by epoch in redis.
+	return nil, store.ErrUnsupported
+}
+
 func (rs *RedisStore) GetBlocksByEpoch(ctx context.Context, epochNumber uint64) ([]types.Hash, error) {
 	return loadEpochBlocks(rs.ctx, rs.rdb, epochNumber)
 }
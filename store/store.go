@@ -42,6 +42,7 @@ type Readable interface {
 
 	GetTransaction(ctx context.Context, txHash types.Hash) (*Transaction, error)
 	GetReceipt(ctx context.Context, txHash types.Hash) (*TransactionReceipt, error)
+	GetReceiptsByEpoch(ctx context.Context, epochNumber uint64) ([]*TransactionReceipt, error)
 
 	GetBlocksByEpoch(ctx context.Context, epochNumber uint64) ([]types.Hash, error)
 	GetBlockByEpoch(ctx context.Context, epochNumber uint64) (*Block, error)
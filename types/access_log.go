@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// AccessLog is a structured access log entry of RPC call, e.g., for billing reconciliation and
+// abuse investigation.
+type AccessLog struct {
+	// time when RPC call received
+	Time time.Time `json:"time"`
+	// space of RPC server, e.g., `cfx` or `eth`
+	Space string `json:"space"`
+	// requested RPC method
+	Method string `json:"method"`
+	// auth ID of the access key, e.g., VIP ID or SVIP key
+	Key string `json:"key,omitempty"`
+	// VIP tier if accessed by VIP user
+	VipTier int `json:"vipTier,omitempty"`
+	// real IP address of client
+	IP string `json:"ip"`
+	// name of the routed full node if any
+	Node string `json:"node,omitempty"`
+	// group of the routed full node if any
+	Group string `json:"group,omitempty"`
+	// elapsed time to handle RPC call in milliseconds
+	Latency int64 `json:"latency"`
+	// size of the JSON encoded result in bytes
+	ResponseSize int `json:"responseSize"`
+	// whether served from store, nil if not applicable
+	StoreHit *bool `json:"storeHit,omitempty"`
+	// JSON-RPC error code if failed
+	ErrorCode int `json:"errorCode,omitempty"`
+}
@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// Reorg is a journal entry of chain reorg, which replaced the synced epochs (or blocks in evm space)
+// since the fork point.
+type Reorg struct {
+	// auto-increment journal ID
+	ID uint64 `json:"id"`
+	// the first epoch (or block) replaced by reorg
+	ForkPoint uint64 `json:"forkPoint"`
+	// number of replaced epochs (or blocks)
+	Depth uint64 `json:"depth"`
+	// pivot block hashes of the replaced epochs (or block hashes in evm space) in ascending order
+	ReplacedHashes []string `json:"replacedHashes"`
+	// time when reorg detected
+	Timestamp time.Time `json:"timestamp"`
+}
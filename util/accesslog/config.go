@@ -0,0 +1,112 @@
+package accesslog
+
+import (
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/sirupsen/logrus"
+)
+
+// Config is the configuration of structured access logs.
+type Config struct {
+	// Whether to record access logs
+	Enabled bool
+	// Max number of access logs buffered to write, which are dropped if buffer is full
+	BufferSize int `default:"10000"`
+	// Max number of access logs to write in batch
+	BatchSize int `default:"200"`
+	// Interval to flush buffered access logs
+	FlushInterval time.Duration `default:"1s"`
+	// Sampling rules of access logs
+	Sampling SamplingConfig
+	// Sink to write access logs into rotating JSONL files
+	File FileSinkConfig
+	// Sink to produce access logs to Kafka compatible topic
+	Kafka KafkaSinkConfig
+	// Sink to write access logs into MySQL database
+	Mysql MysqlSinkConfig
+}
+
+// SamplingConfig is the configuration to sample access logs per RPC method.
+type SamplingConfig struct {
+	// Default sampling rate in range [0, 1], which is low by default to bound the volume of access
+	// logs for high traffic RPC methods, e.g., `eth_blockNumber`
+	DefaultRate float64 `default:"0.1"`
+	// Sampling rates per RPC method, which supports trailing wildcard, e.g., `eth_*`
+	Methods map[string]float64
+	// Whether to record all failed RPC calls regardless of sampling rate
+	AlwaysLogErrors bool `default:"true"`
+}
+
+// FileSinkConfig is the configuration to write access logs into rotating JSONL files.
+type FileSinkConfig struct {
+	Enabled bool
+	// Directory of access log files, which are named as `access_{space}.jsonl`
+	Dir string `default:"logs"`
+	// Max size in megabytes before rotated
+	MaxSize int `default:"100"`
+	// Max number of rotated files to retain, zero to retain all
+	MaxBackups int `default:"10"`
+	// Max days to retain rotated files, zero to retain all
+	MaxAge int `default:"7"`
+	// Whether to compress rotated files
+	Compress bool
+}
+
+// KafkaSinkConfig is the configuration to produce access logs to Kafka compatible topic.
+type KafkaSinkConfig struct {
+	Enabled bool
+	// Topic to produce access logs
+	Topic string `default:"confura_access_logs"`
+	// Number of topic partitions, which are chosen by hash of access key or client IP
+	Partitions int `default:"8"`
+	// Directory of the local stand-in producer to append records into partition files
+	Dir string `default:"logs/kafka"`
+}
+
+// MysqlSinkConfig is the configuration to write access logs into MySQL database.
+type MysqlSinkConfig struct {
+	Enabled bool
+	// Max duration to retain access logs in database, zero to retain all
+	Retention time.Duration `default:"168h"`
+}
+
+// MustNewLoggerFromViper creates access logger of the specified space from viper settings, and
+// returns nil if not enabled. Note, store is required if MySQL sink enabled.
+func MustNewLoggerFromViper(space string, store Store) *Logger {
+	var conf Config
+	viper.MustUnmarshalKey("accesslog", &conf)
+
+	if !conf.Enabled {
+		return nil
+	}
+
+	var sinks []Sink
+
+	if conf.File.Enabled {
+		sinks = append(sinks, newFileSink(space, conf.File))
+	}
+
+	if conf.Kafka.Enabled {
+		producer := NewLocalProducer(space, conf.Kafka.Dir, conf.Kafka.Partitions)
+		sinks = append(sinks, NewKafkaSink(producer, conf.Kafka.Topic))
+	}
+
+	if conf.Mysql.Enabled {
+		if store == nil {
+			logrus.WithField("space", space).Fatal("Store unavailable for MySQL access log sink")
+		}
+
+		sinks = append(sinks, NewMysqlSink(store, conf.Mysql.Retention))
+	}
+
+	if len(sinks) == 0 {
+		logrus.WithField("space", space).Fatal("No sink enabled for access logs")
+	}
+
+	logger := NewLogger(space, conf, sinks...)
+
+	logrus.WithField("space", space).WithField("sinks", len(sinks)).Info("Access log enabled")
+
+	return logger
+}
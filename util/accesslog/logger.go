@@ -0,0 +1,258 @@
+package accesslog
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Conflux-Chain/confura/types"
+	"github.com/Conflux-Chain/confura/util/metrics"
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ctxKeyLogger = handlers.CtxKey("Infura-Access-Logger")
+	ctxKeyEntry  = handlers.CtxKey("Infura-Access-Log-Entry")
+)
+
+// Logger records structured access logs asynchronously into sinks in batch.
+type Logger struct {
+	space   string
+	conf    Config
+	sampler *sampler
+	sinks   []Sink
+	entries chan *types.AccessLog
+
+	closeOnce sync.Once
+	quit      chan struct{} // closed to stop accepting access logs
+	done      chan struct{} // closed once buffered access logs flushed and sinks closed
+}
+
+// NewLogger creates access logger, which writes access logs into sinks in background until closed.
+func NewLogger(space string, conf Config, sinks ...Sink) *Logger {
+	l := &Logger{
+		space:   space,
+		conf:    conf,
+		sampler: newSampler(conf.Sampling),
+		sinks:   sinks,
+		entries: make(chan *types.AccessLog, max(conf.BufferSize, 1)),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go l.run()
+
+	return l
+}
+
+// Close stops accepting access logs, and closes all the sinks once the buffered access logs
+// flushed. It blocks until closed, and is safe to call multiple times.
+func (l *Logger) Close() {
+	l.closeOnce.Do(func() { close(l.quit) })
+	<-l.done
+}
+
+// Begin creates an access log entry for the RPC call.
+func (l *Logger) Begin(method string) *types.AccessLog {
+	return &types.AccessLog{
+		Time:   time.Now(),
+		Space:  l.space,
+		Method: method,
+	}
+}
+
+// End records the access log entry if sampled, which is dropped if buffer is full.
+func (l *Logger) End(entry *types.AccessLog) {
+	entry.Latency = time.Since(entry.Time).Milliseconds()
+
+	if !l.sampler.sampled(entry.Method, entry.ErrorCode != 0) {
+		return
+	}
+
+	select {
+	case <-l.quit: // already closed
+		return
+	default:
+	}
+
+	select {
+	case l.entries <- entry:
+	default:
+		metrics.Registry.RPC.AccessLogDropped(l.space).Mark(1)
+	}
+}
+
+// run writes the buffered access logs into sinks in batch.
+func (l *Logger) run() {
+	ticker := time.NewTicker(l.conf.FlushInterval)
+	defer ticker.Stop()
+
+	batchSize := max(l.conf.BatchSize, 1)
+	batch := make([]*types.AccessLog, 0, batchSize)
+
+	for {
+		select {
+		case entry := <-l.entries:
+			if batch = append(batch, entry); len(batch) >= batchSize {
+				l.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				l.flush(batch)
+				batch = batch[:0]
+			}
+		case <-l.quit:
+			l.drain(batch, batchSize)
+			return
+		}
+	}
+}
+
+// drain flushes all the buffered access logs, and then closes the sinks.
+func (l *Logger) drain(batch []*types.AccessLog, batchSize int) {
+	defer close(l.done)
+
+	for len(l.entries) > 0 {
+		if batch = append(batch, <-l.entries); len(batch) >= batchSize {
+			l.flush(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		l.flush(batch)
+	}
+
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil {
+			logrus.WithError(err).WithField("space", l.space).Info("Failed to close access log sink")
+		}
+	}
+}
+
+func (l *Logger) flush(batch []*types.AccessLog) {
+	for _, sink := range l.sinks {
+		if err := sink.Write(batch); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"space": l.space,
+				"size":  len(batch),
+			}).Info("Failed to write access logs into sink")
+		}
+	}
+}
+
+// sampler samples access logs by RPC method.
+type sampler struct {
+	defaultRate     float64
+	rates           map[string]float64 // lower case method => rate
+	prefixRates     map[string]float64 // lower case method prefix => rate
+	alwaysLogErrors bool
+}
+
+func newSampler(conf SamplingConfig) *sampler {
+	s := &sampler{
+		defaultRate:     conf.DefaultRate,
+		rates:           make(map[string]float64),
+		prefixRates:     make(map[string]float64),
+		alwaysLogErrors: conf.AlwaysLogErrors,
+	}
+
+	// method keys are case insensitive, since viper lowercases all keys
+	for method, rate := range conf.Methods {
+		method = strings.ToLower(method)
+
+		if prefix, ok := strings.CutSuffix(method, "*"); ok {
+			s.prefixRates[prefix] = rate
+		} else {
+			s.rates[method] = rate
+		}
+	}
+
+	return s
+}
+
+// rate returns the sampling rate of RPC method, which prefers the exact match and then the longest
+// matched wildcard prefix.
+func (s *sampler) rate(method string) float64 {
+	method = strings.ToLower(method)
+	if rate, ok := s.rates[method]; ok {
+		return rate
+	}
+
+	rate, matched := s.defaultRate, -1
+	for prefix, r := range s.prefixRates {
+		if strings.HasPrefix(method, prefix) && len(prefix) > matched {
+			rate, matched = r, len(prefix)
+		}
+	}
+
+	return rate
+}
+
+func (s *sampler) sampled(method string, failed bool) bool {
+	if failed && s.alwaysLogErrors {
+		return true
+	}
+
+	switch rate := s.rate(method); {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	default:
+		return rand.Float64() < rate
+	}
+}
+
+// NewContext returns a new context with the access logger, which is used by RPC middleware.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, ctxKeyLogger, logger)
+}
+
+// FromContext returns the access logger from context if any.
+func FromContext(ctx context.Context) (*Logger, bool) {
+	logger, ok := ctx.Value(ctxKeyLogger).(*Logger)
+	return logger, ok && logger != nil
+}
+
+// NewContextWithEntry returns a new context with the access log entry of RPC call, which could be
+// updated by the inner handlers, e.g., routed full node and store hit.
+func NewContextWithEntry(ctx context.Context, entry *types.AccessLog) context.Context {
+	return context.WithValue(ctx, ctxKeyEntry, entry)
+}
+
+// Propagate returns a copy of the context that carries the access log entry of the RPC call context
+// if any, e.g., to the detached context of full node calls.
+func Propagate(ctx, rpcCtx context.Context) context.Context {
+	if entry, ok := rpcCtx.Value(ctxKeyEntry).(*types.AccessLog); ok {
+		return NewContextWithEntry(ctx, entry)
+	}
+
+	return ctx
+}
+
+// SetNode sets the routed full node of RPC call if access logged.
+func SetNode(ctx context.Context, node, group string) {
+	if entry, ok := ctx.Value(ctxKeyEntry).(*types.AccessLog); ok {
+		entry.Node, entry.Group = node, group
+	}
+}
+
+// SetHedgedNode sets the full node of the same group that served the RPC call instead of the routed
+// one due to hedged request if access logged.
+func SetHedgedNode(ctx context.Context, node string) {
+	if entry, ok := ctx.Value(ctxKeyEntry).(*types.AccessLog); ok {
+		entry.Node = node
+	}
+}
+
+// SetStoreHit sets whether RPC call is served from store if access logged.
+func SetStoreHit(ctx context.Context, hit bool) {
+	if entry, ok := ctx.Value(ctxKeyEntry).(*types.AccessLog); ok {
+		entry.StoreHit = &hit
+	}
+}
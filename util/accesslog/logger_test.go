@@ -0,0 +1,171 @@
+package accesslog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Conflux-Chain/confura/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplerRate(t *testing.T) {
+	s := newSampler(SamplingConfig{
+		DefaultRate: 0.5,
+		Methods: map[string]float64{
+			"eth_blocknumber": 0,
+			"eth_get*":        0.1,
+			"eth_getlogs":     1,
+			"eth_*":           0.2,
+		},
+		AlwaysLogErrors: true,
+	})
+
+	assert.Equal(t, float64(0), s.rate("eth_blockNumber"))
+	assert.Equal(t, float64(1), s.rate("eth_getLogs"))
+	assert.Equal(t, 0.1, s.rate("eth_getBlockByHash"))
+	assert.Equal(t, 0.2, s.rate("eth_call"))
+	assert.Equal(t, 0.5, s.rate("cfx_call"))
+
+	assert.False(t, s.sampled("eth_blockNumber", false))
+	assert.True(t, s.sampled("eth_blockNumber", true))
+	assert.True(t, s.sampled("eth_getLogs", false))
+}
+
+func TestContextEntry(t *testing.T) {
+	entry := &types.AccessLog{Method: "eth_getLogs"}
+
+	// noop if not access logged
+	SetNode(context.Background(), "node1", "logs")
+	SetStoreHit(context.Background(), true)
+
+	ctx := NewContextWithEntry(context.Background(), entry)
+	SetNode(ctx, "node1", "logs")
+	SetStoreHit(ctx, true)
+
+	assert.Equal(t, "node1", entry.Node)
+	assert.Equal(t, "logs", entry.Group)
+	assert.True(t, *entry.StoreHit)
+}
+
+func TestLocalProducer(t *testing.T) {
+	dir := t.TempDir()
+
+	producer := NewLocalProducer("eth", dir, 1)
+	sink := NewKafkaSink(producer, "access")
+
+	logs := []*types.AccessLog{
+		{Space: "eth", Method: "eth_call", IP: "127.0.0.1"},
+		{Space: "eth", Method: "eth_getLogs", Key: "key1", ErrorCode: -32000},
+	}
+	assert.NoError(t, sink.Write(logs))
+	assert.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "eth", "access-0.jsonl"))
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, len(logs), len(lines))
+
+	for i, line := range lines {
+		var log types.AccessLog
+		assert.NoError(t, json.Unmarshal([]byte(line), &log))
+		assert.Equal(t, *logs[i], log)
+	}
+}
+
+type memSink struct {
+	mu     sync.Mutex
+	logs   []*types.AccessLog
+	closed bool
+}
+
+func (sink *memSink) Write(logs []*types.AccessLog) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.logs = append(sink.logs, logs...)
+	return nil
+}
+
+func (sink *memSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.closed = true
+	return nil
+}
+
+func TestLoggerClose(t *testing.T) {
+	conf := Config{
+		BufferSize:    100,
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		Sampling:      SamplingConfig{DefaultRate: 1},
+	}
+
+	sink := &memSink{}
+	logger := NewLogger("eth", conf, sink)
+
+	for i := 0; i < 5; i++ {
+		logger.End(logger.Begin("eth_call"))
+	}
+
+	// buffered access logs flushed before sinks closed
+	logger.Close()
+	assert.Len(t, sink.logs, 5)
+	assert.True(t, sink.closed)
+
+	// dropped once closed
+	logger.End(logger.Begin("eth_call"))
+	logger.Close()
+	assert.Len(t, sink.logs, 5)
+}
+
+type memStore struct {
+	logs []*types.AccessLog
+}
+
+func (s *memStore) AddAccessLogs(logs []*types.AccessLog) error {
+	s.logs = append(s.logs, logs...)
+	return nil
+}
+
+func (s *memStore) PruneAccessLogs(before time.Time, limit int) (int64, error) {
+	var deleted int64
+	var kept []*types.AccessLog
+
+	for _, log := range s.logs {
+		if log.Time.Before(before) && deleted < int64(limit) {
+			deleted++
+		} else {
+			kept = append(kept, log)
+		}
+	}
+
+	s.logs = kept
+	return deleted, nil
+}
+
+func TestMysqlSinkPrune(t *testing.T) {
+	now := time.Now()
+
+	store := &memStore{}
+	for i := 0; i < mysqlPruneBatchSize+10; i++ {
+		store.logs = append(store.logs, &types.AccessLog{Time: now.Add(-time.Hour)})
+	}
+	store.logs = append(store.logs, &types.AccessLog{Time: now})
+
+	sink := NewMysqlSink(store, 0)
+	defer sink.Close()
+
+	// expired access logs pruned in batches
+	assert.NoError(t, sink.prune(now.Add(-time.Minute)))
+	assert.Len(t, store.logs, 1)
+	assert.Equal(t, now, store.logs[0].Time)
+}
@@ -0,0 +1,141 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Conflux-Chain/confura/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// interval to prune expired access logs from database
+	mysqlPruneInterval = 10 * time.Minute
+	// max number of expired access logs to delete at a time
+	mysqlPruneBatchSize = 10000
+)
+
+// Sink is implemented by any backend to write access logs into.
+type Sink interface {
+	// Write writes access logs in batch.
+	Write(logs []*types.AccessLog) error
+	// Close closes the sink to release resources.
+	Close() error
+}
+
+// Store is implemented by any store to persist access logs, e.g., MySQL.
+type Store interface {
+	AddAccessLogs(logs []*types.AccessLog) error
+	// PruneAccessLogs deletes at most `limit` access logs recorded before the specified time.
+	PruneAccessLogs(before time.Time, limit int) (int64, error)
+}
+
+// FileSink writes access logs as JSON lines into the underlying writer, e.g., rotating file.
+type FileSink struct {
+	w io.WriteCloser
+}
+
+func NewFileSink(w io.WriteCloser) *FileSink {
+	return &FileSink{w: w}
+}
+
+// newFileSink creates a file sink that writes into rotating file `access_{space}.jsonl`.
+func newFileSink(space string, conf FileSinkConfig) *FileSink {
+	return NewFileSink(&lumberjack.Logger{
+		Filename:   filepath.Join(conf.Dir, fmt.Sprintf("access_%v.jsonl", space)),
+		MaxSize:    conf.MaxSize,
+		MaxBackups: conf.MaxBackups,
+		MaxAge:     conf.MaxAge,
+		Compress:   conf.Compress,
+	})
+}
+
+func (sink *FileSink) Write(logs []*types.AccessLog) error {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	for _, log := range logs {
+		if err := encoder.Encode(log); err != nil {
+			return errors.WithMessage(err, "failed to encode access log")
+		}
+	}
+
+	_, err := sink.w.Write(buf.Bytes())
+	return errors.WithMessage(err, "failed to write access logs")
+}
+
+func (sink *FileSink) Close() error {
+	return sink.w.Close()
+}
+
+// MysqlSink writes access logs into MySQL database, and periodically prunes the expired ones.
+type MysqlSink struct {
+	store     Store
+	retention time.Duration // zero to retain all
+
+	closeOnce sync.Once
+	quit      chan struct{}
+}
+
+func NewMysqlSink(store Store, retention time.Duration) *MysqlSink {
+	sink := &MysqlSink{
+		store:     store,
+		retention: retention,
+		quit:      make(chan struct{}),
+	}
+
+	if retention > 0 {
+		go sink.schedulePrune()
+	}
+
+	return sink
+}
+
+func (sink *MysqlSink) Write(logs []*types.AccessLog) error {
+	return sink.store.AddAccessLogs(logs)
+}
+
+func (sink *MysqlSink) Close() error {
+	sink.closeOnce.Do(func() { close(sink.quit) })
+	return nil
+}
+
+func (sink *MysqlSink) schedulePrune() {
+	ticker := time.NewTicker(mysqlPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := sink.prune(time.Now().Add(-sink.retention)); err != nil {
+				logrus.WithError(err).Info("Failed to prune expired access logs from database")
+			}
+		case <-sink.quit:
+			return
+		}
+	}
+}
+
+// prune deletes access logs recorded before the specified time in batches, so as not to lock the
+// table for long.
+func (sink *MysqlSink) prune(before time.Time) error {
+	for {
+		deleted, err := sink.store.PruneAccessLogs(before, mysqlPruneBatchSize)
+		if err != nil || deleted < mysqlPruneBatchSize {
+			return err
+		}
+
+		select {
+		case <-sink.quit:
+			return nil
+		default:
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Conflux-Chain/confura/types"
+	"github.com/pkg/errors"
+)
+
+// Producer is a Kafka compatible producer to publish records to topic, so that any Kafka client
+// could be plugged in.
+type Producer interface {
+	// Produce publishes a record to topic, where key is used to choose partition.
+	Produce(topic string, key, value []byte) error
+	// Close flushes the pending records and closes the producer.
+	Close() error
+}
+
+// KafkaSink produces access logs as JSON records to a Kafka compatible topic.
+type KafkaSink struct {
+	producer Producer
+	topic    string
+}
+
+func NewKafkaSink(producer Producer, topic string) *KafkaSink {
+	return &KafkaSink{producer: producer, topic: topic}
+}
+
+func (sink *KafkaSink) Write(logs []*types.AccessLog) error {
+	for _, log := range logs {
+		value, err := json.Marshal(log)
+		if err != nil {
+			return errors.WithMessage(err, "failed to encode access log")
+		}
+
+		// partition by access key, or client IP for anonymous access
+		key := log.Key
+		if len(key) == 0 {
+			key = log.IP
+		}
+
+		if err := sink.producer.Produce(sink.topic, []byte(key), value); err != nil {
+			return errors.WithMessage(err, "failed to produce access log")
+		}
+	}
+
+	return nil
+}
+
+func (sink *KafkaSink) Close() error {
+	return sink.producer.Close()
+}
+
+// LocalProducer is a local stand-in of Kafka producer, which appends records as lines into
+// partition files `{dir}/{space}/{topic}-{partition}.jsonl`.
+type LocalProducer struct {
+	dir        string
+	partitions int
+
+	mu    sync.Mutex
+	files map[string]*os.File // file name => file
+}
+
+func NewLocalProducer(space, dir string, partitions int) *LocalProducer {
+	return &LocalProducer{
+		dir:        filepath.Join(dir, space),
+		partitions: max(partitions, 1),
+		files:      make(map[string]*os.File),
+	}
+}
+
+func (p *LocalProducer) Produce(topic string, key, value []byte) error {
+	h := fnv.New32a()
+	h.Write(key)
+	partition := h.Sum32() % uint32(p.partitions)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.partitionFile(fmt.Sprintf("%v-%v.jsonl", topic, partition))
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(value, '\n'))
+	return errors.WithMessage(err, "failed to append record")
+}
+
+func (p *LocalProducer) partitionFile(name string) (*os.File, error) {
+	if f, ok := p.files[name]; ok {
+		return f, nil
+	}
+
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return nil, errors.WithMessage(err, "failed to create directory")
+	}
+
+	f, err := os.OpenFile(filepath.Join(p.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to open partition file")
+	}
+
+	p.files[name] = f
+
+	return f, nil
+}
+
+func (p *LocalProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	for name, f := range p.files {
+		if e := f.Close(); e != nil && err == nil {
+			err = errors.WithMessagef(e, "failed to close partition file %v", name)
+		}
+	}
+
+	p.files = make(map[string]*os.File)
+
+	return err
+}
@@ -104,6 +104,12 @@ func (*RpcMetrics) StoreHit(method, storeName string) metricUtil.Percentage {
 	return metricUtil.GetOrRegisterTimeWindowPercentageDefault(0, "infura/rpc/store/hit/%v/%v", storeName, method)
 }
 
+// RPC metrics - access log
+
+func (*RpcMetrics) AccessLogDropped(space string) metrics.Meter {
+	return metricUtil.GetOrRegisterMeter("infura/rpc/accesslog/%v/dropped", space)
+}
+
 // RPC metrics - fullnode
 
 func (*RpcMetrics) FullnodeQps(node, space, method string, err error) metrics.Timer {
@@ -233,3 +239,11 @@ type ClientMetrics struct{}
 func (*ClientMetrics) CacheHit(method string) metricUtil.Percentage {
 	return metricUtil.GetOrRegisterTimeWindowPercentageDefault(0, "infura/client/cache/hit/%v", method)
 }
+
+func (*ClientMetrics) Coalesced(space, group, method string) metricUtil.Percentage {
+	return metricUtil.GetOrRegisterTimeWindowPercentageDefault(0, "infura/client/coalesce/%v/%v/%v", space, group, method)
+}
+
+func (*ClientMetrics) Hedged(method string) metricUtil.Percentage {
+	return metricUtil.GetOrRegisterTimeWindowPercentageDefault(0, "infura/client/hedge/%v", method)
+}
@@ -0,0 +1,306 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	metricUtil "github.com/Conflux-Chain/go-conflux-util/metrics"
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// PrometheusConfig is the configuration to expose metrics in Prometheus exposition format.
+type PrometheusConfig struct {
+	// Whether to expose metrics to Prometheus
+	Enabled bool
+	// The endpoint to start a http server for Prometheus to scrape
+	HttpEndpoint string `default:":9095"`
+	// The http path to expose metrics
+	Path string `default:"/metrics"`
+}
+
+// MustInitPrometheusFromViper starts a http server to expose metrics of the default registry
+// in Prometheus exposition format if configured, which works alongside or instead of InfluxDB.
+//
+// Note, this should be called after metrics initialized.
+func MustInitPrometheusFromViper() {
+	var config PrometheusConfig
+	viper.MustUnmarshalKey("metrics.prometheus", &config)
+
+	if !config.Enabled {
+		return
+	}
+
+	if !metrics.Enabled {
+		logrus.Warn("Prometheus exporter disabled since metrics not enabled")
+		return
+	}
+
+	l, err := net.Listen("tcp", config.HttpEndpoint)
+	if err != nil {
+		logrus.WithError(err).
+			WithField("endpoint", config.HttpEndpoint).
+			Fatal("Failed to listen http endpoint for Prometheus")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(config.Path, PrometheusHandler(metricUtil.DefaultRegistry))
+
+	go func() {
+		logrus.WithField("config", fmt.Sprintf("%+v", config)).
+			Info("Start to expose metrics for Prometheus...")
+
+		defer l.Close()
+		http.Serve(l, mux)
+	}()
+}
+
+// PrometheusHandler returns a http handler to dump metrics of the registry in Prometheus exposition
+// format, where the known metrics are labeled by method, group, node and space etc.
+func PrometheusHandler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		writePrometheusMetrics(&buf, reg)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+		w.Write(buf.Bytes())
+	})
+}
+
+// promLabelRule extracts labels from the metric name that matches the pattern, e.g.,
+// pattern `infura/rpc/duration/{method}` labels the metric `infura/rpc/duration/eth_call`
+// as `infura_rpc_duration{method="eth_call"}`.
+type promLabelRule struct {
+	segments []string          // pattern segments, `{label}` as label placeholder
+	consts   map[string]string // constant labels to keep label set consistent within family
+}
+
+func newPromLabelRule(pattern string, consts ...string) promLabelRule {
+	rule := promLabelRule{segments: strings.Split(pattern, "/")}
+
+	for i := 0; i+1 < len(consts); i += 2 {
+		if rule.consts == nil {
+			rule.consts = make(map[string]string)
+		}
+
+		rule.consts[consts[i]] = consts[i+1]
+	}
+
+	return rule
+}
+
+// match returns the metric family name along with labels if matched.
+func (rule promLabelRule) match(segments []string) (string, map[string]string, bool) {
+	if len(segments) != len(rule.segments) {
+		return "", nil, false
+	}
+
+	var names []string
+	labels := make(map[string]string, len(segments)+len(rule.consts))
+
+	for i, seg := range rule.segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			labels[seg[1:len(seg)-1]] = segments[i]
+			continue
+		}
+
+		if seg != segments[i] {
+			return "", nil, false
+		}
+
+		names = append(names, seg)
+	}
+
+	for k, v := range rule.consts {
+		labels[k] = v
+	}
+
+	return strings.Join(names, "_"), labels, true
+}
+
+// promLabelRules are rules to label the known metrics, which are matched in order.
+var promLabelRules = []promLabelRule{
+	// rpc server metrics from go-rpc-provider
+	newPromLabelRule("rpc/duration/{method}/{result}"),
+
+	// proxy RPC metrics
+	newPromLabelRule("infura/rpc/duration/{method}"),
+	newPromLabelRule("infura/rpc/rate/{result}", "method", "all"),
+	newPromLabelRule("infura/rpc/rate/{result}/{method}"),
+	newPromLabelRule("infura/rpc/input/epoch/gap/{method}"),
+	newPromLabelRule("infura/rpc/input/epoch/{method}/{epoch}"),
+	newPromLabelRule("infura/rpc/input/block/gap/{method}"),
+	newPromLabelRule("infura/rpc/input/block/{method}/{block}"),
+	newPromLabelRule("infura/rpc/input/blockHash/{method}"),
+	newPromLabelRule("infura/rpc/handler/{method}/filter/split/{name}"),
+	newPromLabelRule("infura/rpc/percentage/{method}/{name}"),
+	newPromLabelRule("infura/rpc/store/hit/{store}/{method}"),
+	newPromLabelRule("infura/rpc/accesslog/{space}/dropped"),
+	newPromLabelRule("infura/rpc/fullnode/rate/{result}", "node", "all"),
+	newPromLabelRule("infura/rpc/fullnode/rate/{result}/{node}"),
+	newPromLabelRule("infura/rpc/fullnode/{node}/{space}/{method}/{result}"),
+
+	// store metrics
+	newPromLabelRule("infura/store/{store}/{op}"),
+
+	// sync metrics
+	newPromLabelRule("infura/sync/{space}/fullnode"),
+	newPromLabelRule("infura/sync/{space}/fullnode/availability"),
+	newPromLabelRule("infura/sync/{space}/{store}/once/size"),
+	newPromLabelRule("infura/sync/{space}/{store}/once/{result}"),
+
+	// node manager metrics
+	newPromLabelRule("infura/nodes/{space}/routes/{group}/{node}"),
+	newPromLabelRule("infura/nodes/{space}/latency/{group}/{node}"),
+	newPromLabelRule("infura/nodes/{space}/availability/{group}/{node}"),
+
+	// pubsub metrics
+	newPromLabelRule("infura/pubsub/{space}/sessions/{topic}/{node}"),
+	newPromLabelRule("infura/pubsub/{space}/input/logFilter"),
+
+	// virtual filter metrics
+	newPromLabelRule("infura/virtualFilter/{space}/poll/{node}/once/size"),
+	newPromLabelRule("infura/virtualFilter/{space}/poll/{node}/once/{result}"),
+	newPromLabelRule("infura/virtualFilter/{space}/percentage/query/{node}/filterChanges/{store}"),
+	newPromLabelRule("infura/virtualFilter/{space}/{op}/{node}/filterChanges/{store}"),
+	newPromLabelRule("infura/virtualFilter/{space}/{type}/sessions/{node}"),
+
+	// client metrics
+	newPromLabelRule("infura/client/cache/hit/{method}"),
+	newPromLabelRule("infura/client/coalesce/{space}/{group}/{method}"),
+	newPromLabelRule("infura/client/hedge/{method}"),
+}
+
+var promInvalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// parsePromMetric parses the metric name into Prometheus metric family name along with labels.
+// Unknown metrics are not labeled, and only slashes are replaced with underscores.
+func parsePromMetric(name string) (string, map[string]string) {
+	segments := strings.Split(name, "/")
+
+	for _, rule := range promLabelRules {
+		if family, labels, ok := rule.match(segments); ok {
+			return promInvalidNameChars.ReplaceAllString(family, "_"), labels
+		}
+	}
+
+	return promInvalidNameChars.ReplaceAllString(name, "_"), nil
+}
+
+// quantiles to report for timers and histograms
+var promQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+type promSample struct {
+	suffix string
+	labels string
+	value  any
+}
+
+type promFamily struct {
+	typ     string
+	samples []promSample
+}
+
+// writePrometheusMetrics writes all metrics of the registry in Prometheus exposition format,
+// where samples are grouped by metric family.
+func writePrometheusMetrics(buf *bytes.Buffer, reg metrics.Registry) {
+	families := make(map[string]*promFamily)
+
+	add := func(family, typ string, sample promSample) {
+		f, ok := families[family]
+		if !ok {
+			f = &promFamily{typ: typ}
+			families[family] = f
+		}
+
+		f.samples = append(f.samples, sample)
+	}
+
+	reg.Each(func(name string, i interface{}) {
+		family, labels := parsePromMetric(name)
+
+		switch m := i.(type) {
+		case metrics.Counter:
+			add(family, "counter", promSample{labels: formatPromLabels(labels), value: m.Snapshot().Count()})
+		case metrics.Meter:
+			add(family, "counter", promSample{labels: formatPromLabels(labels), value: m.Snapshot().Count()})
+		case metrics.Gauge:
+			add(family, "gauge", promSample{labels: formatPromLabels(labels), value: m.Snapshot().Value()})
+		case metrics.GaugeFloat64:
+			add(family, "gauge", promSample{labels: formatPromLabels(labels), value: m.Snapshot().Value()})
+		case metrics.Histogram:
+			addPromSummary(add, family, labels, m.Snapshot())
+		case metrics.Timer:
+			addPromSummary(add, family, labels, m.Snapshot())
+		default:
+			logrus.WithFields(logrus.Fields{
+				"name": name, "type": fmt.Sprintf("%T", i),
+			}).Debug("Unsupported metric type for Prometheus")
+		}
+	})
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		f := families[name]
+		slices.SortFunc(f.samples, func(a, b promSample) int {
+			return strings.Compare(a.suffix+a.labels, b.suffix+b.labels)
+		})
+
+		fmt.Fprintf(buf, "# TYPE %s %s\n", name, f.typ)
+		for _, s := range f.samples {
+			fmt.Fprintf(buf, "%s%s%s %v\n", name, s.suffix, s.labels, s.value)
+		}
+	}
+}
+
+func addPromSummary(
+	add func(family, typ string, sample promSample),
+	family string, labels map[string]string, snapshot metrics.HistogramSnapshot,
+) {
+	percentiles := snapshot.Percentiles(promQuantiles)
+	for i, q := range promQuantiles {
+		qlabels := map[string]string{"quantile": strconv.FormatFloat(q, 'f', -1, 64)}
+		for k, v := range labels {
+			qlabels[k] = v
+		}
+
+		add(family, "summary", promSample{labels: formatPromLabels(qlabels), value: percentiles[i]})
+	}
+
+	add(family, "summary", promSample{suffix: "_count", labels: formatPromLabels(labels), value: snapshot.Count()})
+}
+
+var promLabelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatPromLabels formats labels in order of label names, e.g., `{method="eth_call",node="n1"}`.
+func formatPromLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, k, promLabelValueEscaper.Replace(labels[k]))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePromMetric(t *testing.T) {
+	testCases := []struct {
+		name   string
+		family string
+		labels map[string]string
+	}{
+		{"infura/rpc/duration/eth_call", "infura_rpc_duration", map[string]string{"method": "eth_call"}},
+		{"infura/rpc/rate/success", "infura_rpc_rate", map[string]string{"result": "success", "method": "all"}},
+		{"infura/rpc/input/block/gap/eth_call", "infura_rpc_input_block_gap", map[string]string{"method": "eth_call"}},
+		{
+			"infura/nodes/eth/routes/ethhttp/node1.com:8545",
+			"infura_nodes_routes",
+			map[string]string{"space": "eth", "group": "ethhttp", "node": "node1.com:8545"},
+		},
+		{
+			"infura/rpc/fullnode/node1.com/cfx/cfx_getLogs/failure",
+			"infura_rpc_fullnode",
+			map[string]string{"node": "node1.com", "space": "cfx", "method": "cfx_getLogs", "result": "failure"},
+		},
+		{"rpc/requests", "rpc_requests", nil},
+		{"infura/unknown/some-metric", "infura_unknown_some_metric", nil},
+	}
+
+	for _, tc := range testCases {
+		family, labels := parsePromMetric(tc.name)
+		assert.Equal(t, tc.family, family, tc.name)
+		assert.Equal(t, tc.labels, labels, tc.name)
+	}
+}
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	for name, value := range map[string]int64{
+		"infura/pubsub/cfx/sessions/logs/node1": 3,
+		"infura/pubsub/cfx/sessions/logs/node2": 5,
+	} {
+		gauge := &metrics.StandardGauge{}
+		gauge.Update(value)
+		reg.Register(name, gauge)
+	}
+
+	var buf bytes.Buffer
+	writePrometheusMetrics(&buf, reg)
+
+	expected := "# TYPE infura_pubsub_sessions gauge\n" +
+		`infura_pubsub_sessions{node="node1",space="cfx",topic="logs"} 3` + "\n" +
+		`infura_pubsub_sessions{node="node2",space="cfx",topic="logs"} 5` + "\n"
+	assert.Equal(t, expected, buf.String())
+}
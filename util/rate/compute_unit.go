@@ -0,0 +1,55 @@
+package rate
+
+import "math"
+
+const (
+	// default compute unit cost for RPC methods not listed in the cost table
+	defaultComputeUnitCost = 1
+)
+
+// CallShape describes an RPC call to evaluate its compute unit cost.
+type CallShape struct {
+	Method     string // RPC method name
+	RangeWidth uint64 // width of the block or epoch range for log filter, 0 if not applicable
+	FullTx     bool   // whether full transactions are requested
+}
+
+// MethodCost compute unit cost of an RPC method
+type MethodCost struct {
+	Base     int // base cost
+	PerBlock int // extra cost per block (or epoch) of the log filter range
+	FullTx   int // extra cost if full transactions are requested
+}
+
+// CostTable compute unit costs of RPC methods
+type CostTable struct {
+	Default int                   // cost for methods not listed, which defaults to 1
+	Methods map[string]MethodCost // RPC method => cost
+}
+
+// Cost returns the compute unit cost of the RPC call.
+func (t *CostTable) Cost(call *CallShape) int {
+	mc, ok := t.Methods[call.Method]
+	if !ok {
+		if t.Default > 0 {
+			return t.Default
+		}
+
+		return defaultComputeUnitCost
+	}
+
+	cost := uint64(mc.Base)
+	if mc.PerBlock > 0 {
+		cost += uint64(mc.PerBlock) * call.RangeWidth
+	}
+
+	if call.FullTx {
+		cost += uint64(mc.FullTx)
+	}
+
+	if cost > math.MaxInt32 { // avoid overflow for extremely large range
+		return math.MaxInt32
+	}
+
+	return int(cost)
+}
@@ -0,0 +1,320 @@
+package rate
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+// LimitError is the rate limit error along with the limiter status, which could be used by
+// clients to back off accordingly.
+type LimitError struct {
+	Limit      int           // max requests (or compute units) allowed
+	Remaining  int           // remaining requests (or compute units) currently available
+	Reset      time.Time     // time when enough quota is available for the request
+	RetryAfter time.Duration // duration to wait before retry
+
+	msg string
+}
+
+func (e *LimitError) Error() string {
+	return e.msg
+}
+
+// Quota is the quota status of a limiter after request permitted, which is exposed to clients so
+// that they could throttle before rate limited.
+type Quota struct {
+	Limit     int       // max requests (or compute units) allowed
+	Remaining int       // remaining requests (or compute units) currently available
+	Reset     time.Time // time when the quota is fully restored
+}
+
+// quotaLimiter is the limiter that reports the quota status once request permitted.
+type quotaLimiter interface {
+	// limitAt limits n requests (or compute units) at the specified time, and returns the quota
+	// status if permitted.
+	limitAt(now time.Time, n int) (Quota, error)
+	// Expired returns whether the limiter is idle for long, which could be garbage collected.
+	Expired() bool
+}
+
+func errMaxExceeded(max int) error {
+	return errors.Errorf("Too many requests, exceeds %v at a time", max)
+}
+
+// errCostExceedsQuota is returned if the compute unit cost of a single request exceeds the whole
+// quota, which could never be satisfied no matter how long to wait.
+func errCostExceedsQuota(cost, quota int) error {
+	return errors.Errorf(
+		"Request too expensive (cost %v compute units, exceeds the whole quota %v), "+
+			"please narrow the request, e.g., with a smaller block range", cost, quota,
+	)
+}
+
+func newRateLimitedError(limit, remaining int, now time.Time, waitTime time.Duration) *LimitError {
+	waitTime = waitTime.Round(time.Millisecond)
+
+	return &LimitError{
+		Limit:      limit,
+		Remaining:  remaining,
+		Reset:      now.Add(waitTime),
+		RetryAfter: waitTime,
+		msg:        fmt.Sprintf("Too many requests (exceeds %v), try again after %v", limit, waitTime),
+	}
+}
+
+func newComputeUnitsExceededError(cost, remaining, quota int, now time.Time, waitTime time.Duration) *LimitError {
+	waitTime = waitTime.Round(time.Millisecond)
+
+	return &LimitError{
+		Limit:      quota,
+		Remaining:  remaining,
+		Reset:      now.Add(waitTime),
+		RetryAfter: waitTime,
+		msg: fmt.Sprintf(
+			"Compute units exceeded (cost %v, remaining %v of %v), try again after %v",
+			cost, remaining, quota, waitTime,
+		),
+	}
+}
+
+// newFixedWindowError creates rate limit error for fixed window limiter, which is billed either
+// in requests or in compute units.
+func newFixedWindowError(
+	computeUnits bool, n, used, quota int, now, windowStart time.Time, interval time.Duration,
+) *LimitError {
+	remaining := quota - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	waitTime := windowStart.Add(interval).Sub(now)
+	if computeUnits {
+		return newComputeUnitsExceededError(n, remaining, quota, now, waitTime)
+	}
+
+	return newRateLimitedError(quota, remaining, now, waitTime)
+}
+
+// fixedWindow limits requests (or compute units) within a fixed window.
+type fixedWindow struct {
+	mu sync.Mutex
+
+	interval     time.Duration
+	quota        int
+	computeUnits bool // whether billed in compute units
+
+	startTime time.Time // start time of the current window
+	used      int       // quota used within the current window
+}
+
+func newFixedWindow(interval time.Duration, quota int, computeUnits bool) *fixedWindow {
+	return &fixedWindow{
+		interval:     interval,
+		quota:        quota,
+		computeUnits: computeUnits,
+		startTime:    time.Now().Truncate(interval),
+	}
+}
+
+func (w *fixedWindow) Limit() error {
+	return w.LimitAt(time.Now(), 1)
+}
+
+func (w *fixedWindow) LimitN(n int) error {
+	return w.LimitAt(time.Now(), n)
+}
+
+func (w *fixedWindow) LimitAt(now time.Time, n int) error {
+	_, err := w.limitAt(now, n)
+	return err
+}
+
+func (w *fixedWindow) limitAt(now time.Time, n int) (Quota, error) {
+	if n > w.quota {
+		if w.computeUnits {
+			return Quota{}, errCostExceedsQuota(n, w.quota)
+		}
+
+		return Quota{}, errMaxExceeded(w.quota)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if truncated := now.Truncate(w.interval); w.startTime.Before(truncated) {
+		// reset
+		w.startTime, w.used = truncated, 0
+	}
+
+	if w.used+n <= w.quota {
+		w.used += n
+		return newFixedWindowQuota(w.used, w.quota, w.startTime, w.interval), nil
+	}
+
+	return Quota{}, newFixedWindowError(w.computeUnits, n, w.used, w.quota, now, w.startTime, w.interval)
+}
+
+func newFixedWindowQuota(used, quota int, windowStart time.Time, interval time.Duration) Quota {
+	return Quota{
+		Limit:     quota,
+		Remaining: max(0, quota-used),
+		Reset:     windowStart.Add(interval),
+	}
+}
+
+func (w *fixedWindow) Expired() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return time.Since(w.startTime) > w.interval
+}
+
+// tokenBucket limits requests with the token bucket algorithm.
+type tokenBucket struct {
+	inner       *rate.Limiter
+	lastSeen    int64 // last active unix timestamp
+	timeoutSecs int64 // idle timeout in seconds for garbage collection
+}
+
+func newTokenBucket(r rate.Limit, burst int) *tokenBucket {
+	return &tokenBucket{
+		inner:       rate.NewLimiter(r, burst),
+		lastSeen:    time.Now().Unix(),
+		timeoutSecs: int64(float64(burst)/float64(r)) + 1,
+	}
+}
+
+func (b *tokenBucket) Limit() error {
+	return b.LimitAt(time.Now(), 1)
+}
+
+func (b *tokenBucket) LimitN(n int) error {
+	return b.LimitAt(time.Now(), n)
+}
+
+func (b *tokenBucket) LimitAt(now time.Time, n int) error {
+	_, err := b.limitAt(now, n)
+	return err
+}
+
+func (b *tokenBucket) limitAt(now time.Time, n int) (Quota, error) {
+	rsv := b.inner.ReserveN(now, n)
+	if !rsv.OK() {
+		return Quota{}, errMaxExceeded(b.inner.Burst())
+	}
+
+	if waitTime := rsv.DelayFrom(now); waitTime > 0 {
+		rsv.CancelAt(now)
+
+		remaining := int(math.Max(0, b.inner.TokensAt(now)))
+		return Quota{}, newRateLimitedError(int(b.inner.Limit()), remaining, now, waitTime)
+	}
+
+	atomic.StoreInt64(&b.lastSeen, now.Unix())
+
+	return newTokenBucketQuota(b.inner.Limit(), b.inner.Burst(), b.inner.TokensAt(now), now), nil
+}
+
+// newTokenBucketQuota creates the quota status of token bucket, which is reset once the bucket
+// is refilled to the burst.
+func newTokenBucketQuota(r rate.Limit, burst int, tokens float64, now time.Time) Quota {
+	tokens = math.Max(0, tokens)
+	refill := time.Duration((float64(burst) - tokens) / float64(r) * float64(time.Second))
+
+	return Quota{
+		Limit:     int(r),
+		Remaining: int(tokens),
+		Reset:     now.Add(max(0, refill)),
+	}
+}
+
+func (b *tokenBucket) Expired() bool {
+	lastSeen := atomic.LoadInt64(&b.lastSeen)
+	return time.Now().Unix() > lastSeen+b.timeoutSecs
+}
+
+// limiterSet holds limiters by resource, group and key.
+type limiterSet struct {
+	factory func(resource, group, key string, option interface{}) (quotaLimiter, error)
+
+	mu sync.Mutex
+	// resource => group => key => limiter
+	limiters map[string]map[string]map[string]quotaLimiter
+}
+
+func newLimiterSet(
+	factory func(resource, group, key string, option interface{}) (quotaLimiter, error),
+) *limiterSet {
+	return &limiterSet{
+		factory:  factory,
+		limiters: make(map[string]map[string]map[string]quotaLimiter),
+	}
+}
+
+func (ls *limiterSet) getOrCreate(resource, group, key string, option interface{}) (quotaLimiter, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	limitersByGroup, ok := ls.limiters[resource]
+	if !ok {
+		limitersByGroup = make(map[string]map[string]quotaLimiter)
+		ls.limiters[resource] = limitersByGroup
+	}
+
+	limitersByKey, ok := limitersByGroup[group]
+	if !ok {
+		limitersByKey = make(map[string]quotaLimiter)
+		limitersByGroup[group] = limitersByKey
+	}
+
+	if limiter, ok := limitersByKey[key]; ok {
+		return limiter, nil
+	}
+
+	limiter, err := ls.factory(resource, group, key, option)
+	if err != nil {
+		return nil, err
+	}
+
+	limitersByKey[key] = limiter
+	return limiter, nil
+}
+
+func (ls *limiterSet) remove(resource, group string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if limiters, ok := ls.limiters[resource]; ok {
+		delete(limiters, group)
+	}
+}
+
+func (ls *limiterSet) gc() {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	for _, limitersByGroup := range ls.limiters {
+		for _, limitersByKey := range limitersByGroup {
+			for key, limiter := range limitersByKey {
+				if limiter.Expired() {
+					delete(limitersByKey, key)
+				}
+			}
+		}
+	}
+}
+
+func (ls *limiterSet) scheduleGC(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ls.gc()
+	}
+}
@@ -0,0 +1,260 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	logutil "github.com/Conflux-Chain/go-conflux-util/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// key prefix of the limiter state within Redis
+	redisLimiterKeyPrefix = "ratelimit"
+	// timeout for each Redis operation, after which the local limiter is used instead
+	redisLimiterOpTimeout = 100 * time.Millisecond
+)
+
+var (
+	// Note, the current time is read from Redis rather than passed in, so that the limiter state
+	// shared by all the RPC instances is not messed up by clock skew between them.
+
+	// fixed window script:
+	// KEYS[1] - window key
+	// ARGV[1] - requested count, ARGV[2] - window interval (ms), ARGV[3] - window quota
+	// returns {allowed (1 or 0), used count, window start (ms), now (ms)}.
+	redisFixedWindowScript = redis.NewScript(`
+redis.replicate_commands()
+local n = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local quota = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local start = now - now % interval
+local state = redis.call('HMGET', KEYS[1], 'start', 'count')
+local count = 0
+if tonumber(state[1]) == start then
+	count = tonumber(state[2]) or 0
+end
+if count + n > quota then
+	return {0, count, start, now}
+end
+count = count + n
+redis.call('HMSET', KEYS[1], 'start', start, 'count', count)
+redis.call('PEXPIRE', KEYS[1], start + interval - now)
+return {1, count, start, now}
+`)
+
+	// token bucket script:
+	// KEYS[1] - bucket key
+	// ARGV[1] - rate (tokens/s), ARGV[2] - burst, ARGV[3] - requested tokens
+	// returns {1, 0, remaining tokens} if allowed, otherwise {0, wait time in ms, remaining tokens}.
+	redisTokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+local wait = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+else
+	wait = math.ceil((n - tokens) * 1000 / rate)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait, math.floor(tokens)}
+`)
+)
+
+// redisLimiter is a limiter whose state is shared through Redis by all the RPC instances, and
+// the in-process local limiter is used as fallback whenever Redis is unreachable.
+type redisLimiter struct {
+	client   *redis.Client
+	key      string       // state key within Redis
+	option   interface{}  // `FixedWindowOption`, `TokenBucketOption` or `ComputeUnitOption`
+	local    quotaLimiter // fallback local limiter
+	lastSeen int64        // last active unix timestamp
+	ttlSecs  int64        // idle timeout in seconds for garbage collection
+}
+
+func newRedisLimiter(client *redis.Client, key string, option interface{}) (*redisLimiter, error) {
+	l := &redisLimiter{
+		client:   client,
+		key:      key,
+		option:   option,
+		lastSeen: time.Now().Unix(),
+	}
+
+	switch opt := option.(type) {
+	case FixedWindowOption:
+		if opt.Interval <= 0 {
+			return nil, errors.New("invalid fixed window interval")
+		}
+
+		l.local = newFixedWindow(opt.Interval, opt.Quota, false)
+		l.ttlSecs = int64(opt.Interval.Seconds()) + 1
+	case TokenBucketOption:
+		if opt.Rate <= 0 {
+			return nil, errors.New("invalid token bucket rate")
+		}
+
+		l.local = newTokenBucket(opt.Rate, opt.Burst)
+		l.ttlSecs = int64(float64(opt.Burst)/float64(opt.Rate)) + 1
+	case ComputeUnitOption:
+		l.local = newFixedWindow(opt.Interval, opt.Quota, true)
+		l.ttlSecs = int64(opt.Interval.Seconds()) + 1
+	default:
+		return nil, errors.New("invalid limit option")
+	}
+
+	return l, nil
+}
+
+func (l *redisLimiter) Limit() error {
+	return l.LimitAt(time.Now(), 1)
+}
+
+func (l *redisLimiter) LimitN(n int) error {
+	return l.LimitAt(time.Now(), n)
+}
+
+func (l *redisLimiter) LimitAt(now time.Time, n int) error {
+	_, err := l.limitAt(now, n)
+	return err
+}
+
+func (l *redisLimiter) limitAt(now time.Time, n int) (quota Quota, err error) {
+	atomic.StoreInt64(&l.lastSeen, now.Unix())
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisLimiterOpTimeout)
+	defer cancel()
+
+	var redisErr error
+	switch opt := l.option.(type) {
+	case FixedWindowOption:
+		if n > opt.Quota {
+			return Quota{}, errMaxExceeded(opt.Quota)
+		}
+
+		quota, err, redisErr = l.limitFixedWindow(ctx, n, opt, false)
+	case ComputeUnitOption:
+		if n > opt.Quota {
+			return Quota{}, errCostExceedsQuota(n, opt.Quota)
+		}
+
+		fwopt := FixedWindowOption{Interval: opt.Interval, Quota: opt.Quota}
+		quota, err, redisErr = l.limitFixedWindow(ctx, n, fwopt, true)
+	case TokenBucketOption:
+		quota, err, redisErr = l.limitTokenBucket(ctx, now, n, opt)
+	}
+
+	redisLimiterLogger.Log(
+		logrus.WithField("key", l.key), redisErr,
+		"Failed to limit rate with Redis, fall back to local limiter",
+	)
+
+	if redisErr != nil {
+		return l.local.limitAt(now, n)
+	}
+
+	return quota, err
+}
+
+// limitFixedWindow limits rate with the fixed window algorithm, it returns the quota status if
+// permitted, the rate limit error and Redis error if any.
+func (l *redisLimiter) limitFixedWindow(
+	ctx context.Context, n int, opt FixedWindowOption, computeUnits bool,
+) (Quota, error, error) {
+	val, err := redisFixedWindowScript.Run(
+		ctx, l.client, []string{l.key}, n, opt.Interval.Milliseconds(), opt.Quota,
+	).Result()
+	if err != nil {
+		return Quota{}, nil, err
+	}
+
+	res, ok := val.([]interface{})
+	if !ok || len(res) != 4 {
+		return Quota{}, nil, errors.Errorf("unexpected fixed window script result %v", val)
+	}
+
+	allowed, _ := res[0].(int64)
+	used, _ := res[1].(int64)
+	windowStartMs, _ := res[2].(int64)
+	nowMs, _ := res[3].(int64)
+
+	// window is determined by Redis time
+	now, windowStart := time.UnixMilli(nowMs), time.UnixMilli(windowStartMs)
+	if allowed == 0 {
+		return Quota{}, newFixedWindowError(
+			computeUnits, n, int(used), opt.Quota, now, windowStart, opt.Interval,
+		), nil
+	}
+
+	return newFixedWindowQuota(int(used), opt.Quota, windowStart, opt.Interval), nil, nil
+}
+
+// limitTokenBucket limits rate with the token bucket algorithm, it returns the quota status if
+// permitted, the rate limit error and Redis error if any.
+func (l *redisLimiter) limitTokenBucket(
+	ctx context.Context, now time.Time, n int, opt TokenBucketOption,
+) (Quota, error, error) {
+	if n > opt.Burst {
+		return Quota{}, errMaxExceeded(opt.Burst), nil
+	}
+
+	val, err := redisTokenBucketScript.Run(
+		ctx, l.client, []string{l.key}, float64(opt.Rate), opt.Burst, n,
+	).Result()
+	if err != nil {
+		return Quota{}, nil, err
+	}
+
+	res, ok := val.([]interface{})
+	if !ok || len(res) != 3 {
+		return Quota{}, nil, errors.Errorf("unexpected token bucket script result %v", val)
+	}
+
+	allowed, _ := res[0].(int64)
+	waitMs, _ := res[1].(int64)
+	remaining, _ := res[2].(int64)
+
+	if allowed == 0 {
+		waitTime := time.Duration(waitMs) * time.Millisecond
+		return Quota{}, newRateLimitedError(int(opt.Rate), int(remaining), now, waitTime), nil
+	}
+
+	return newTokenBucketQuota(opt.Rate, opt.Burst, float64(remaining), now), nil, nil
+}
+
+func (l *redisLimiter) Expired() bool {
+	lastSeen := atomic.LoadInt64(&l.lastSeen)
+	return time.Now().Unix() > lastSeen+l.ttlSecs
+}
+
+var redisLimiterLogger = logutil.NewErrorTolerantLogger(logutil.DefaultETConfig)
+
+// newDistributedLimiters creates Redis backed limiters, which will not be blocked by each other
+// while waiting for the Redis response.
+func newDistributedLimiters(client *redis.Client) *limiterSet {
+	return newLimiterSet(func(resource, group, key string, option interface{}) (quotaLimiter, error) {
+		// eg., ratelimit:rpc_all_qps:vip1:key:xxx
+		redisKey := fmt.Sprintf("%v:%v:%v:%v", redisLimiterKeyPrefix, resource, group, key)
+		return newRedisLimiter(client, redisKey, option)
+	})
+}
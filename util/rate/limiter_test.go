@@ -0,0 +1,72 @@
+package rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedWindowLimitError(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	w := newFixedWindow(time.Minute, 10, true)
+
+	assert.NoError(t, w.LimitAt(now, 8))
+
+	err := w.LimitAt(now.Add(15*time.Second), 3)
+	limitErr, ok := err.(*LimitError)
+	assert.True(t, ok)
+	assert.Equal(t, 10, limitErr.Limit)
+	assert.Equal(t, 2, limitErr.Remaining)
+	assert.Equal(t, 45*time.Second, limitErr.RetryAfter)
+	assert.Equal(t, now.Add(time.Minute), limitErr.Reset)
+
+	// quota reset in the next window
+	assert.NoError(t, w.LimitAt(now.Add(time.Minute), 10))
+}
+
+func TestTokenBucketLimitError(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(10, 5)
+
+	assert.NoError(t, b.LimitAt(now, 5))
+
+	err := b.LimitAt(now, 2)
+	limitErr, ok := err.(*LimitError)
+	assert.True(t, ok)
+	assert.Equal(t, 10, limitErr.Limit)
+	assert.Equal(t, 0, limitErr.Remaining)
+	assert.Equal(t, 200*time.Millisecond, limitErr.RetryAfter)
+
+	assert.NoError(t, b.LimitAt(now.Add(limitErr.RetryAfter), 2))
+}
+
+func TestFixedWindowCostExceedsQuota(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	w := newFixedWindow(time.Minute, 10, true)
+
+	// rejected at once without waiting for the next window
+	err := w.LimitAt(now, 11)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the whole quota")
+
+	_, ok := err.(*LimitError)
+	assert.False(t, ok)
+
+	// quota not consumed by the rejected request
+	assert.NoError(t, w.LimitAt(now, 10))
+}
+
+func TestLimiterQuota(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+
+	w := newFixedWindow(time.Minute, 10, false)
+	quota, err := w.limitAt(now.Add(15*time.Second), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 10, Remaining: 7, Reset: now.Add(time.Minute)}, quota)
+
+	b := newTokenBucket(10, 5)
+	quota, err = b.limitAt(now, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Limit: 10, Remaining: 3, Reset: now.Add(200 * time.Millisecond)}, quota)
+}
@@ -8,8 +8,7 @@ import (
 
 	"github.com/Conflux-Chain/confura/util/acl"
 	"github.com/Conflux-Chain/confura/util/rpc/handlers"
-	"github.com/Conflux-Chain/go-conflux-util/rate"
-	"github.com/Conflux-Chain/go-conflux-util/rate/http"
+	"github.com/go-redis/redis/v8"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -37,18 +36,36 @@ func SVipStatusFromContext(ctx context.Context) (*SVipStatus, bool) {
 }
 
 type Registry struct {
-	*http.Registry
 	*aclRegistry
 
 	mu      sync.Mutex
 	kloader *KeyLoader
 
+	// in-process limiters
+	localLimiters *limiterSet
+	// Redis backed limiters shared across the fleet, nil if Redis not configured
+	distLimiters *limiterSet
+	// per-key usage accounting, nil if usage store not configured
+	usage *usageCollector
+
 	// all available strategies
 	strategies    map[string]*Strategy // strategy name => *Strategy
 	id2Strategies map[uint32]*Strategy // strategy id => *Strategy
 }
 
-func NewRegistry(kloader *KeyLoader, valFactory acl.ValidatorFactory) *Registry {
+// RegistryOption optional settings for rate limit registry
+type RegistryOption struct {
+	// Redis client to share limiters across the fleet for limit rules with `redis` backend,
+	// which fall back to local limiters if not provided.
+	RedisClient *redis.Client
+
+	// Store to persist per-key usages, which disables usage accounting if not provided.
+	UsageStore UsageStore
+	// Interval to flush collected usages into the usage store.
+	UsageFlushInterval time.Duration
+}
+
+func NewRegistry(kloader *KeyLoader, valFactory acl.ValidatorFactory, option ...RegistryOption) *Registry {
 	m := &Registry{
 		kloader:       kloader,
 		aclRegistry:   newAclRegistry(kloader, valFactory),
@@ -56,14 +73,192 @@ func NewRegistry(kloader *KeyLoader, valFactory acl.ValidatorFactory) *Registry
 		id2Strategies: make(map[uint32]*Strategy),
 	}
 
-	m.Registry = http.NewRegistry(m)
-	go m.ScheduleGC(GCScheduleInterval)
+	m.localLimiters = newLimiterSet(func(resource, group, key string, option interface{}) (quotaLimiter, error) {
+		return m.createWithOption(option)
+	})
+	go m.localLimiters.scheduleGC(GCScheduleInterval)
+
+	if len(option) > 0 && option[0].RedisClient != nil {
+		m.distLimiters = newDistributedLimiters(option[0].RedisClient)
+		go m.distLimiters.scheduleGC(GCScheduleInterval)
+	}
+
+	if len(option) > 0 && option[0].UsageStore != nil {
+		interval := option[0].UsageFlushInterval
+		if interval <= 0 {
+			interval = DefaultUsageFlushInterval
+		}
+
+		m.usage = newUsageCollector(option[0].UsageStore)
+		go m.usage.scheduleFlush(interval)
+	}
 
 	return m
 }
 
-// implements `http.LimiterFactory`
+// Limit limits request rate according to the request context.
+func (r *Registry) Limit(ctx context.Context, resource string) error {
+	return r.LimitN(ctx, resource, 1)
+}
+
+// LimitN limits request rate according to the request context, and exposes the quota status to
+// clients through the rate limit status of the request context if permitted. Note, limiters are
+// not guarded by the registry lock, so as not to serialize Redis round trips.
+func (r *Registry) LimitN(ctx context.Context, resource string, n int) error {
+	group, key, err := r.GetGroupAndKey(ctx, resource)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to get group and key from visit context")
+	}
+
+	if len(resource) == 0 || len(group) == 0 || len(key) == 0 {
+		// skip empty resource, group or key
+		return nil
+	}
+
+	limiters, option := r.localLimiters, r.getLimitOption(resource, group)
+	if distOpt, ok := option.(DistributedOption); ok && r.distLimiters != nil {
+		limiters, option = r.distLimiters, distOpt.Option
+	}
+
+	limiter, err := limiters.getOrCreate(resource, group, key, option)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to create limiter")
+	}
+
+	quota, err := limiter.limitAt(time.Now(), n)
+	if err != nil {
+		return err
+	}
+
+	if status, ok := handlers.GetRateLimitStatusFromContext(ctx); ok {
+		status.Permit(quota.Limit, quota.Remaining, quota.Reset)
+	}
+
+	return nil
+}
+
+// Remove removes all limiters of the specified resource and group.
+func (r *Registry) Remove(resource, group string) {
+	r.localLimiters.remove(resource, group)
+
+	if r.distLimiters != nil {
+		r.distLimiters.remove(resource, group)
+	}
+}
+
+// LimitComputeUnits limits compute units according to the request context, where the RPC call
+// is billed with the cost defined by the compute unit limit rule of the resource.
+func (r *Registry) LimitComputeUnits(ctx context.Context, resource string, call *CallShape) error {
+	group, _, err := r.GetGroupAndKey(ctx, resource)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to get group and key from visit context")
+	}
+
+	if len(group) == 0 {
+		// limit rule not defined
+		return nil
+	}
+
+	option := r.getLimitOption(resource, group)
+	if distOpt, ok := option.(DistributedOption); ok {
+		option = distOpt.Option
+	}
+
+	cost := 1 // charge per request for other limit algorithms
+	if cuopt, ok := option.(ComputeUnitOption); ok {
+		cost = cuopt.Costs.Cost(call)
+	}
+
+	return r.LimitN(ctx, resource, cost)
+}
+
+// CollectUsage accounts the usage of the RPC call for the limit key from the request context.
+func (r *Registry) CollectUsage(ctx context.Context, method string, failed bool, start time.Time) {
+	if r.usage == nil {
+		return
+	}
+
+	authId, ok := handlers.GetAuthIdFromContext(ctx)
+	if !ok || len(authId) == 0 {
+		return
+	}
+
+	// only account for the registered keys to prevent from abuse of random keys
+	if ki, ok := r.kloader.Load(authId); !ok || ki == nil {
+		return
+	}
 
+	r.usage.collect(authId, method, failed, start, time.Since(start))
+}
+
+// LoadUsages loads the persisted usages with the specified filter.
+func (r *Registry) LoadUsages(filter *UsageFilter) ([]*Usage, error) {
+	if r.usage == nil {
+		return nil, errors.New("usage accounting not enabled")
+	}
+
+	return r.usage.store.LoadRateLimitUsages(filter)
+}
+
+// GetStrategyCaps returns the resource caps of the strategy applied to the request context.
+//
+// Note, authentication is applied per call message, so batch requests are resolved by the
+// access token only.
+func (r *Registry) GetStrategyCaps(ctx context.Context) (*StrategyCaps, bool) {
+	stg, ok := r.getStrategy(ctx)
+	if !ok || stg.Caps == nil {
+		return nil, false
+	}
+
+	return stg.Caps, true
+}
+
+func (r *Registry) getStrategy(ctx context.Context) (*Strategy, bool) {
+	if vip, ok := handlers.VipStatusFromContext(ctx); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// use vip strategy with corresponding tier
+		return r.getVipStrategy(vip.Tier)
+	}
+
+	limitKey, ok := handlers.GetAuthIdFromContext(ctx)
+	if !ok && handlers.IsAccessTokenValid(ctx) {
+		limitKey, _ = handlers.GetAccessTokenFromContext(ctx)
+	}
+
+	var ki *KeyInfo
+	if len(limitKey) > 0 {
+		ki, _ = r.kloader.Load(limitKey)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ki != nil {
+		// use strategy with corresponding key info
+		if stg, ok := r.id2Strategies[ki.SID]; ok {
+			return stg, true
+		}
+	}
+
+	// use default strategy as fallback
+	stg, ok := r.strategies[DefaultStrategy]
+	return stg, ok
+}
+
+func (r *Registry) getLimitOption(resource, group string) interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stg, ok := r.strategies[group]; ok {
+		return stg.LimitOptions[resource]
+	}
+
+	return nil
+}
+
+// GetGroupAndKey generates limiter group and key from the request context.
 func (r *Registry) GetGroupAndKey(
 	ctx context.Context,
 	resource string,
@@ -88,23 +283,6 @@ func (r *Registry) GetGroupAndKey(
 	return r.genDefaultGroupAndKey(ctx, resource)
 }
 
-func (r *Registry) Create(ctx context.Context, resource, group string) (rate.Limiter, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	stg, ok := r.strategies[group]
-	if !ok {
-		return nil, errors.New("strategy not found")
-	}
-
-	opt, ok := stg.LimitOptions[resource]
-	if !ok {
-		return nil, errors.New("limit rule not found")
-	}
-
-	return r.createWithOption(opt)
-}
-
 func (r *Registry) genDefaultGroupAndKey(
 	ctx context.Context,
 	resource string,
@@ -196,12 +374,17 @@ func (r *Registry) genKeyInfoGroupAndKey(
 	return group, key, err
 }
 
-func (r *Registry) createWithOption(option interface{}) (l rate.Limiter, err error) {
+func (r *Registry) createWithOption(option interface{}) (l quotaLimiter, err error) {
 	switch opt := option.(type) {
 	case FixedWindowOption:
-		l = rate.NewFixedWindow(opt.Interval, opt.Quota)
+		l = newFixedWindow(opt.Interval, opt.Quota, false)
 	case TokenBucketOption:
-		l = rate.NewTokenBucket(int(opt.Rate), opt.Burst)
+		l = newTokenBucket(opt.Rate, opt.Burst)
+	case ComputeUnitOption:
+		l = newFixedWindow(opt.Interval, opt.Quota, true)
+	case DistributedOption:
+		// Redis not configured, fall back to local limiter
+		return r.createWithOption(opt.Option)
 	default:
 		err = errors.New("invalid limit option")
 	}
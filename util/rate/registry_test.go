@@ -0,0 +1,60 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryRateLimitHeaders(t *testing.T) {
+	r := NewRegistry(nil, nil)
+
+	stg := NewStrategy(1, DefaultStrategy)
+	stg.LimitOptions["rpc_all_qps"] = FixedWindowOption{Interval: time.Hour, Quota: 2}
+	r.strategies[DefaultStrategy] = stg
+
+	handler := handlers.RateLimitHeaders(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), handlers.CtxKeyRealIP, "127.0.0.1")
+		if err := r.Limit(ctx, "rpc_all_qps"); err != nil {
+			if limitErr, ok := err.(*LimitError); ok {
+				status, _ := handlers.GetRateLimitStatusFromContext(ctx)
+				status.Update(limitErr.Limit, limitErr.Remaining, limitErr.Reset, limitErr.RetryAfter)
+			}
+		}
+
+		w.Write([]byte("ok"))
+	}))
+
+	serve := func() http.Header {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		return rec.Header()
+	}
+
+	reset := strconv.FormatInt(time.Now().Truncate(time.Hour).Add(time.Hour).Unix(), 10)
+
+	// quota exposed even if not rate limited
+	for _, remaining := range []string{"1", "0"} {
+		h := serve()
+		assert.Equal(t, "2", h.Get("X-RateLimit-Limit"))
+		assert.Equal(t, remaining, h.Get("X-RateLimit-Remaining"))
+		assert.Equal(t, reset, h.Get("X-RateLimit-Reset"))
+		assert.Empty(t, h.Get("Retry-After"))
+	}
+
+	// retry hint if rate limited
+	h := serve()
+	assert.Equal(t, "0", h.Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, h.Get("Retry-After"))
+
+	// no headers if not subject to any rate limit rule
+	delete(stg.LimitOptions, "rpc_all_qps")
+	h = serve()
+	assert.Empty(t, h.Get("X-RateLimit-Limit"))
+}
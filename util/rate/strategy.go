@@ -11,9 +11,20 @@ import (
 type LimitAlgoType string
 
 const (
-	// rate limit algorithms, only `fixed_window` and `token bucket` are supported for now.
+	// rate limit algorithms, `fixed_window`, `token bucket` and `compute_unit` are supported for now.
 	LimitAlgoFixedWindow LimitAlgoType = "fixed_window"
 	LimitAlgoTokenBucket LimitAlgoType = "token_bucket"
+	// compute unit algorithm bills each request with the cost of the RPC method in a fixed window.
+	LimitAlgoComputeUnit LimitAlgoType = "compute_unit"
+)
+
+type LimitBackendType string
+
+const (
+	// rate limit backends, limiters are kept in process by default, or shared across the fleet
+	// through Redis.
+	LimitBackendLocal LimitBackendType = "local"
+	LimitBackendRedis LimitBackendType = "redis"
 )
 
 type LimitType int
@@ -27,6 +38,9 @@ const (
 const (
 	// pre-defined default strategy name
 	DefaultStrategy = "default"
+
+	// reserved key of strategy rules for resource caps
+	strategyCapsKey = "caps"
 )
 
 // Strategy rate limit strategy
@@ -35,6 +49,16 @@ type Strategy struct {
 	Name string // strategy name
 
 	LimitOptions map[string]interface{} // resource => limit option
+	Caps         *StrategyCaps          // resource caps, nil means defaults
+}
+
+// StrategyCaps resource caps of a strategy, which overrides the defaults if non-zero.
+type StrategyCaps struct {
+	MaxLogsEpochRange    uint64 // max epoch span of `getLogs` delegated to fullnode
+	MaxLogsBlockRange    uint64 // max block span of `getLogs` delegated to fullnode
+	MaxLogs              uint64 // max number of logs returned by `getLogs`
+	MaxLogsResponseBytes uint64 // max response body bytes of `getLogs`
+	MaxBatchSize         int    // max number of requests within a batch
 }
 
 func NewStrategy(id uint32, name string) *Strategy {
@@ -47,12 +71,27 @@ func NewStrategy(id uint32, name string) *Strategy {
 
 // UnmarshalJSON implements `json.Unmarshaler`
 func (s *Strategy) UnmarshalJSON(data []byte) error {
-	tmpRules := make(map[string]*LimitRule)
+	tmpRules := make(map[string]json.RawMessage)
 	if err := json.Unmarshal(data, &tmpRules); err != nil {
 		return errors.WithMessage(err, "malformed json format")
 	}
 
-	for resource, rule := range tmpRules {
+	if capsJson, ok := tmpRules[strategyCapsKey]; ok {
+		var caps StrategyCaps
+		if err := json.Unmarshal(capsJson, &caps); err != nil {
+			return errors.WithMessage(err, "malformed caps json format")
+		}
+
+		s.Caps = &caps
+		delete(tmpRules, strategyCapsKey)
+	}
+
+	for resource, ruleJson := range tmpRules {
+		var rule LimitRule
+		if err := json.Unmarshal(ruleJson, &rule); err != nil {
+			return errors.WithMessagef(err, "malformed limit rule for resource %v", resource)
+		}
+
 		s.LimitOptions[resource] = rule.Option
 	}
 
@@ -99,16 +138,56 @@ func NewTokenBucketOption(r, b int) TokenBucketOption {
 	}
 }
 
+// ComputeUnitOption limit option for compute units billed in a fixed window
+type ComputeUnitOption struct {
+	Interval time.Duration // billing interval, e.g., 1s or 24h
+	Quota    int           // max compute units within the interval
+	Costs    CostTable     // compute unit costs of RPC methods
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`
+func (cuo *ComputeUnitOption) UnmarshalJSON(data []byte) error {
+	var tmp struct {
+		Interval string
+		Quota    int
+		Costs    CostTable
+	}
+
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	interval, err := time.ParseDuration(tmp.Interval)
+	if err != nil {
+		return err
+	}
+
+	if interval <= 0 || tmp.Quota <= 0 {
+		return errors.New("interval and quota must be positive")
+	}
+
+	cuo.Interval, cuo.Quota, cuo.Costs = interval, tmp.Quota, tmp.Costs
+	return nil
+}
+
+// DistributedOption limit option whose quota is shared across the fleet through Redis.
+type DistributedOption struct {
+	// inner limit option, `FixedWindowOption`, `TokenBucketOption` or `ComputeUnitOption`
+	Option interface{}
+}
+
 // LimitRule resource limit rule
 type LimitRule struct {
-	Algo   LimitAlgoType
-	Option interface{}
+	Algo    LimitAlgoType
+	Backend LimitBackendType
+	Option  interface{}
 }
 
 func (r *LimitRule) UnmarshalJSON(data []byte) (err error) {
 	var tmp struct {
-		Algo   LimitAlgoType
-		Option json.RawMessage
+		Algo    LimitAlgoType
+		Backend LimitBackendType
+		Option  json.RawMessage
 	}
 
 	if err := json.Unmarshal(data, &tmp); err != nil {
@@ -117,6 +196,15 @@ func (r *LimitRule) UnmarshalJSON(data []byte) (err error) {
 
 	r.Algo = tmp.Algo
 
+	switch tmp.Backend {
+	case "":
+		r.Backend = LimitBackendLocal
+	case LimitBackendLocal, LimitBackendRedis:
+		r.Backend = tmp.Backend
+	default:
+		return errors.New("invalid rate limit backend")
+	}
+
 	switch tmp.Algo {
 	case LimitAlgoFixedWindow:
 		var fwopt FixedWindowOption
@@ -128,9 +216,18 @@ func (r *LimitRule) UnmarshalJSON(data []byte) (err error) {
 		if err = json.Unmarshal(tmp.Option, &tbopt); err == nil {
 			r.Option = tbopt
 		}
+	case LimitAlgoComputeUnit:
+		var cuopt ComputeUnitOption
+		if err = json.Unmarshal(tmp.Option, &cuopt); err == nil {
+			r.Option = cuopt
+		}
 	default:
 		return errors.New("invalid rate limit algorithm")
 	}
 
+	if err == nil && r.Backend == LimitBackendRedis {
+		r.Option = DistributedOption{Option: r.Option}
+	}
+
 	return err
 }
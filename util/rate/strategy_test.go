@@ -31,3 +31,99 @@ func TestUnmarshalStrategy(t *testing.T) {
 	fwopt := FixedWindowOption{Interval: 24 * time.Hour, Quota: 100000}
 	assert.Equal(t, fwopt, stg.LimitOptions["rpc_all_daily"])
 }
+
+func TestUnmarshalStrategyWithBackend(t *testing.T) {
+	stgJsonStr := `{
+		"rpc_all_qps": {
+			"algo": "token_bucket",
+			"backend": "redis",
+			"option": {"rate": 100, "burst":1000}
+		},
+		"rpc_all_daily": {
+			"algo": "fixed_window",
+			"backend": "local",
+			"option": {"interval": "24h", "quota":100000}
+		}
+	}`
+
+	stg := NewStrategy(1, "default")
+
+	err := json.Unmarshal(([]byte)(stgJsonStr), &stg)
+	assert.NoError(t, err)
+
+	distOpt := DistributedOption{Option: NewTokenBucketOption(100, 1000)}
+	assert.Equal(t, distOpt, stg.LimitOptions["rpc_all_qps"])
+
+	fwopt := FixedWindowOption{Interval: 24 * time.Hour, Quota: 100000}
+	assert.Equal(t, fwopt, stg.LimitOptions["rpc_all_daily"])
+
+	err = json.Unmarshal([]byte(`{"rpc_all_qps": {"algo": "token_bucket", "backend": "memcached"}}`), &stg)
+	assert.Error(t, err)
+}
+
+func TestUnmarshalComputeUnitStrategy(t *testing.T) {
+	stgJsonStr := `{
+		"rpc_all_cups": {
+			"algo": "compute_unit",
+			"option": {
+				"interval": "1s",
+				"quota": 1000,
+				"costs": {
+					"default": 2,
+					"methods": {
+						"eth_getLogs": {"base": 10, "perBlock": 1},
+						"eth_getBlockByNumber": {"base": 1, "fullTx": 4}
+					}
+				}
+			}
+		}
+	}`
+
+	stg := NewStrategy(1, "default")
+
+	err := json.Unmarshal(([]byte)(stgJsonStr), &stg)
+	assert.NoError(t, err)
+
+	cuopt, ok := stg.LimitOptions["rpc_all_cups"].(ComputeUnitOption)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, cuopt.Interval)
+	assert.Equal(t, 1000, cuopt.Quota)
+
+	assert.Equal(t, 2, cuopt.Costs.Cost(&CallShape{Method: "eth_chainId"}))
+	assert.Equal(t, 110, cuopt.Costs.Cost(&CallShape{Method: "eth_getLogs", RangeWidth: 100}))
+	assert.Equal(t, 1, cuopt.Costs.Cost(&CallShape{Method: "eth_getBlockByNumber"}))
+	assert.Equal(t, 5, cuopt.Costs.Cost(&CallShape{Method: "eth_getBlockByNumber", FullTx: true}))
+}
+
+func TestUnmarshalStrategyWithCaps(t *testing.T) {
+	stgJsonStr := `{
+		"rpc_all_qps": {
+			"algo": "token_bucket",
+			"option": {"rate": 100, "burst":1000}
+		},
+		"caps": {
+			"maxLogsBlockRange": 5000,
+			"maxLogs": 50000,
+			"maxLogsResponseBytes": 52428800,
+			"maxBatchSize": 200
+		}
+	}`
+
+	stg := NewStrategy(1, "premium")
+
+	err := json.Unmarshal(([]byte)(stgJsonStr), &stg)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(stg.LimitOptions))
+
+	caps := &StrategyCaps{
+		MaxLogsBlockRange:    5000,
+		MaxLogs:              50000,
+		MaxLogsResponseBytes: 52428800,
+		MaxBatchSize:         200,
+	}
+	assert.Equal(t, caps, stg.Caps)
+
+	stg = NewStrategy(2, "default")
+	err = json.Unmarshal([]byte(`{"caps": {"maxBatchSize": "unlimited"}}`), &stg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package rate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// default interval to flush the collected usages into store
+	DefaultUsageFlushInterval = time.Minute
+	// max number of pending usage records kept in memory if failed to flush
+	maxPendingUsages = 100_000
+	// max length of RPC method name to account
+	maxUsageMethodLen = 64
+)
+
+// Usage aggregated usage of a limit key for an RPC method within an hourly bucket.
+type Usage struct {
+	LimitKey       string    `json:"limitKey"`
+	Method         string    `json:"method"`
+	Hour           time.Time `json:"hour"`           // start time of the hourly bucket
+	Requests       uint64    `json:"requests"`       // total requests
+	Errors         uint64    `json:"errors"`         // total error responses
+	TotalLatencyMs uint64    `json:"totalLatencyMs"` // total latency in milliseconds
+	MaxLatencyMs   uint64    `json:"maxLatencyMs"`   // max latency in milliseconds
+}
+
+// merge merges the other usage of the same bucket.
+func (u *Usage) merge(other *Usage) {
+	u.Requests += other.Requests
+	u.Errors += other.Errors
+	u.TotalLatencyMs += other.TotalLatencyMs
+
+	if other.MaxLatencyMs > u.MaxLatencyMs {
+		u.MaxLatencyMs = other.MaxLatencyMs
+	}
+}
+
+type UsageFilter struct {
+	LimitKey string    // limit key
+	Methods  []string  // RPC methods (empty means all)
+	From, To time.Time // hourly bucket time range (zero means unbounded)
+	Limit    int       // result limit size (<= 0 means none)
+}
+
+// UsageStore persists the aggregated usages, e.g., into database.
+type UsageStore interface {
+	AddRateLimitUsages(usages []*Usage) error
+	LoadRateLimitUsages(filter *UsageFilter) ([]*Usage, error)
+}
+
+type usageBucketKey struct {
+	limitKey string
+	method   string
+	hour     int64
+}
+
+// usageCollector aggregates usages of limit keys in memory, and periodically flushes them
+// into the usage store.
+type usageCollector struct {
+	mu      sync.Mutex
+	store   UsageStore
+	buckets map[usageBucketKey]*Usage
+}
+
+func newUsageCollector(store UsageStore) *usageCollector {
+	return &usageCollector{
+		store:   store,
+		buckets: make(map[usageBucketKey]*Usage),
+	}
+}
+
+func (c *usageCollector) collect(limitKey, method string, failed bool, start time.Time, latency time.Duration) {
+	if len(method) > maxUsageMethodLen {
+		method = method[:maxUsageMethodLen]
+	}
+
+	hour := start.Truncate(time.Hour)
+	latencyMs := uint64(latency.Milliseconds())
+
+	usage := &Usage{Requests: 1, TotalLatencyMs: latencyMs, MaxLatencyMs: latencyMs}
+	if failed {
+		usage.Errors = 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := usageBucketKey{limitKey: limitKey, method: method, hour: hour.Unix()}
+	if bucket, ok := c.buckets[key]; ok {
+		bucket.merge(usage)
+		return
+	}
+
+	if len(c.buckets) >= maxPendingUsages {
+		// too many pending usages, maybe the store is unavailable for a long time
+		return
+	}
+
+	usage.LimitKey, usage.Method, usage.Hour = limitKey, method, hour
+	c.buckets[key] = usage
+}
+
+func (c *usageCollector) flush() error {
+	c.mu.Lock()
+	buckets := c.buckets
+	c.buckets = make(map[usageBucketKey]*Usage)
+	c.mu.Unlock()
+
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	usages := make([]*Usage, 0, len(buckets))
+	for _, usage := range buckets {
+		usages = append(usages, usage)
+	}
+
+	if err := c.store.AddRateLimitUsages(usages); err != nil {
+		c.restore(buckets)
+		return err
+	}
+
+	return nil
+}
+
+// restore merges the usages back if failed to flush, so as to retry in the next round.
+func (c *usageCollector) restore(buckets map[usageBucketKey]*Usage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, usage := range buckets {
+		if bucket, ok := c.buckets[key]; ok {
+			usage.merge(bucket)
+		}
+
+		c.buckets[key] = usage
+	}
+}
+
+func (c *usageCollector) scheduleFlush(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := c.flush(); err != nil {
+			logrus.WithError(err).Warn("Failed to flush rate limit usages")
+		}
+	}
+}
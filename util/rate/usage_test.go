@@ -0,0 +1,57 @@
+package rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockUsageStore struct {
+	usages []*Usage
+	err    error
+}
+
+func (s *mockUsageStore) AddRateLimitUsages(usages []*Usage) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.usages = append(s.usages, usages...)
+	return nil
+}
+
+func (s *mockUsageStore) LoadRateLimitUsages(filter *UsageFilter) ([]*Usage, error) {
+	return s.usages, nil
+}
+
+func TestUsageCollector(t *testing.T) {
+	store := &mockUsageStore{err: errors.New("db unavailable")}
+	c := newUsageCollector(store)
+
+	start := time.Now().Truncate(time.Hour)
+	c.collect("key", "eth_call", false, start, 10*time.Millisecond)
+	c.collect("key", "eth_call", true, start.Add(time.Minute), 30*time.Millisecond)
+	c.collect("key", "eth_getLogs", false, start, 20*time.Millisecond)
+
+	// usages are kept if failed to flush
+	assert.Error(t, c.flush())
+	assert.Equal(t, 2, len(c.buckets))
+
+	c.collect("key", "eth_call", false, start.Add(time.Hour), 5*time.Millisecond)
+
+	store.err = nil
+	assert.NoError(t, c.flush())
+	assert.Empty(t, c.buckets)
+	assert.Equal(t, 3, len(store.usages))
+
+	for _, u := range store.usages {
+		if u.Method == "eth_call" && u.Hour.Equal(start) {
+			assert.Equal(t, uint64(2), u.Requests)
+			assert.Equal(t, uint64(1), u.Errors)
+			assert.Equal(t, uint64(40), u.TotalLatencyMs)
+			assert.Equal(t, uint64(30), u.MaxLatencyMs)
+		}
+	}
+}
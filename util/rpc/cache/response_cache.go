@@ -0,0 +1,368 @@
+package cache
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Conflux-Chain/confura/util"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-redis/redis/v8"
+	"github.com/mcuadros/go-defaults"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// timeout to read or write the Redis tier
+	redisResponseCacheTimeout = 100 * time.Millisecond
+)
+
+// ResponseCacheConfig response cache configurations
+type ResponseCacheConfig struct {
+	Enabled         bool
+	Size            int           `default:"10000"`   // max number of responses cached in memory
+	Expiration      time.Duration `default:"1h"`      // expiration duration of cached responses
+	MaxResultBytes  int           `default:"1048576"` // max number of bytes of a cacheable result
+	RefreshInterval time.Duration `default:"1s"`      // interval to poll the chain reorg status
+	RedisUrl        string        // optional Redis tier shared across RPC instances
+}
+
+// NewResponseCacheConfig returns a ResponseCacheConfig with default values.
+func NewResponseCacheConfig() ResponseCacheConfig {
+	var cfg ResponseCacheConfig
+	defaults.SetDefaults(&cfg)
+	return cfg
+}
+
+// ChainStatusProvider provides the chain status synchronized into store, which is used to determine
+// whether RPC responses are immutable or not.
+type ChainStatusProvider interface {
+	// GetReorgVersion returns the version that increases whenever chain reorg happens.
+	GetReorgVersion() (int, error)
+	// MaxEpoch returns the max epoch number synchronized.
+	MaxEpoch() (uint64, bool, error)
+}
+
+// FinalizedEpochProvider provides the latest finalized epoch (or block) number of the chain.
+type FinalizedEpochProvider interface {
+	FinalizedEpoch() (uint64, error)
+}
+
+// ResponseCache caches the responses of RPC calls whose results are immutable unless chain reorg
+// happens, with an in-memory LRU tier and an optional Redis tier.
+//
+// Only results at or below the epoch both synchronized and finalized are cached, since results of
+// the epochs not finalized yet could be reverted by chain reorg without being noticed in time.
+//
+// Cache keys are versioned by the chain reorg version polled from the sync layer, so that all the
+// cached responses will be invalidated once chain reorg happens.
+type ResponseCache struct {
+	space  string // RPC space, e.g., `cfx` or `eth`
+	config ResponseCacheConfig
+
+	lru   *util.ExpirableLruCache
+	redis *redis.Client // optional
+
+	provider  ChainStatusProvider
+	finalizer FinalizedEpochProvider
+
+	mu         sync.RWMutex
+	version    int    // chain reorg version
+	maxEpoch   uint64 // max epoch number both synchronized and finalized
+	refreshed  bool   // whether the chain status ever refreshed
+	lastLogged time.Time
+}
+
+func NewResponseCache(
+	space string, provider ChainStatusProvider, finalizer FinalizedEpochProvider,
+	config ResponseCacheConfig, redisClient ...*redis.Client,
+) *ResponseCache {
+	c := &ResponseCache{
+		space:     space,
+		config:    config,
+		lru:       util.NewExpirableLruCache(config.Size, config.Expiration),
+		provider:  provider,
+		finalizer: finalizer,
+	}
+
+	if len(redisClient) > 0 {
+		c.redis = redisClient[0]
+	}
+
+	if err := c.refresh(); err != nil {
+		logrus.WithError(err).WithField("space", space).Warn("Failed to refresh chain status for response cache")
+	}
+
+	go c.scheduleRefresh()
+
+	return c
+}
+
+// Get gets the cached result by the cache key.
+func (c *ResponseCache) Get(key string) (json.RawMessage, bool) {
+	if val, ok := c.lru.Get(key); ok {
+		return val.(json.RawMessage), true
+	}
+
+	if c.redis == nil {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisResponseCacheTimeout)
+	defer cancel()
+
+	data, err := c.redis.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			logrus.WithError(err).WithField("key", key).Debug("Failed to get response from Redis cache")
+		}
+
+		return nil, false
+	}
+
+	result := json.RawMessage(data)
+	c.lru.Add(key, result)
+
+	return result, true
+}
+
+// Add caches the result of the RPC call by the cache key if the result is immutable.
+//
+// Note, the cache key shall be retrieved before the RPC call is executed, so that the result will
+// not be cached with the new reorg version if chain reorg happens during execution.
+func (c *ResponseCache) Add(key, method string, result json.RawMessage) {
+	if len(result) == 0 || len(result) > c.config.MaxResultBytes || string(result) == "null" {
+		return
+	}
+
+	if !c.isResultImmutable(method, result) {
+		return
+	}
+
+	c.lru.Add(key, result)
+
+	if c.redis == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisResponseCacheTimeout)
+	defer cancel()
+
+	if err := c.redis.Set(ctx, key, []byte(result), c.config.Expiration).Err(); err != nil {
+		logrus.WithError(err).WithField("key", key).Debug("Failed to add response into Redis cache")
+	}
+}
+
+// Key returns the versioned cache key for the RPC call, or false if the call is not cacheable.
+func (c *ResponseCache) Key(method string, params json.RawMessage) (string, bool) {
+	c.mu.RLock()
+	version, maxEpoch, refreshed := c.version, c.maxEpoch, c.refreshed
+	c.mu.RUnlock()
+
+	if !refreshed || !isCallCacheable(method, params, maxEpoch) {
+		return "", false
+	}
+
+	canonicalParams, ok := canonicalizeParams(params)
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("resp:%v:%d:%v:%x", c.space, version, method, md5.Sum(canonicalParams)), true
+}
+
+// isResultImmutable checks if the result is already finalized and synchronized into store, so that
+// it will be invalidated by the reorg version if chain reorg happens.
+func (c *ResponseCache) isResultImmutable(method string, result json.RawMessage) bool {
+	fields, ok := cacheableMethods[method]
+	if !ok {
+		return false
+	}
+
+	if len(fields) == 0 { // immutable by call parameters
+		return true
+	}
+
+	epoch, ok := parseResultEpoch(result, fields)
+	if !ok {
+		return false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return epoch <= c.maxEpoch
+}
+
+func (c *ResponseCache) refresh() error {
+	version, err := c.provider.GetReorgVersion()
+	if err != nil {
+		return err
+	}
+
+	maxEpoch, ok, err := c.provider.MaxEpoch()
+	if err != nil {
+		return err
+	}
+
+	finalizedEpoch, err := c.finalizer.FinalizedEpoch()
+	if err != nil {
+		return errors.WithMessage(err, "failed to get finalized epoch")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.refreshed && version != c.version {
+		logrus.WithFields(logrus.Fields{
+			"space":      c.space,
+			"oldVersion": c.version,
+			"newVersion": version,
+		}).Info("Response cache invalidated due to chain reorg")
+	}
+
+	c.version, c.refreshed = version, ok
+	c.maxEpoch = min(maxEpoch, finalizedEpoch)
+
+	return nil
+}
+
+func (c *ResponseCache) scheduleRefresh() {
+	ticker := time.NewTicker(c.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := c.refresh()
+		if err == nil || time.Since(c.lastLogged) < time.Minute {
+			continue
+		}
+
+		c.lastLogged = time.Now()
+		logrus.WithError(err).WithField("space", c.space).Warn("Failed to refresh chain status for response cache")
+	}
+}
+
+// cacheableMethods are RPC methods whose results are immutable unless chain reorg happens, mapped
+// to the candidate epoch (or block) number fields of results to check if already synchronized into
+// store. Empty fields means the result is immutable by call parameters, e.g., `eth_call` at a
+// synchronized block number.
+var cacheableMethods = map[string][]string{
+	"eth_getBlockByHash":        {"number"},
+	"eth_getTransactionReceipt": {"blockNumber"},
+	"eth_call":                  nil,
+	"cfx_getBlockByHash":        {"epochNumber"},
+	"trace_transaction":         {"blockNumber", "epochNumber"}, // evm space or core space
+}
+
+// isCallCacheable checks if the RPC call is cacheable by method and parameters.
+func isCallCacheable(method string, params json.RawMessage, maxEpoch uint64) bool {
+	if _, ok := cacheableMethods[method]; !ok {
+		return false
+	}
+
+	if method != "eth_call" {
+		return true
+	}
+
+	// `eth_call` is only cacheable at an explicit block number that already synchronized
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil || len(args) != 2 {
+		return false
+	}
+
+	var blockNum hexutil.Uint64
+	if err := json.Unmarshal(args[1], &blockNum); err != nil {
+		return false
+	}
+
+	return uint64(blockNum) <= maxEpoch
+}
+
+// canonicalizeParams canonicalizes the JSON params, e.g., sorts object keys, trims whitespaces and
+// lowercases hex addresses and hashes, while other strings are kept as they are.
+func canonicalizeParams(params json.RawMessage) ([]byte, bool) {
+	if len(params) == 0 {
+		return []byte("[]"), true
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(params, &v); err != nil {
+		return nil, false
+	}
+
+	data, err := json.Marshal(lowercaseHexIdentifiers(v))
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// hexIdentifierPattern matches hex encoded addresses or hashes, which are case insensitive.
+var hexIdentifierPattern = regexp.MustCompile(`^0[xX]([0-9a-fA-F]{40}|[0-9a-fA-F]{64})$`)
+
+// lowercaseHexIdentifiers lowercases the hex encoded addresses and hashes in the decoded JSON value.
+func lowercaseHexIdentifiers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		if hexIdentifierPattern.MatchString(val) {
+			return strings.ToLower(val)
+		}
+	case []interface{}:
+		for i := range val {
+			val[i] = lowercaseHexIdentifiers(val[i])
+		}
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = lowercaseHexIdentifiers(val[k])
+		}
+	}
+
+	return v
+}
+
+// parseResultEpoch parses the first present epoch (or block) number field from the result object,
+// or the first element if result is an array, e.g., traces.
+func parseResultEpoch(result json.RawMessage, fields []string) (uint64, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(result, &obj); err != nil {
+		var arr []map[string]json.RawMessage
+		if err := json.Unmarshal(result, &arr); err != nil || len(arr) == 0 {
+			return 0, false
+		}
+
+		obj = arr[0]
+	}
+
+	var raw json.RawMessage
+	for _, field := range fields {
+		if v, ok := obj[field]; ok {
+			raw = v
+			break
+		}
+	}
+
+	if len(raw) == 0 || string(raw) == "null" { // e.g., block not executed yet
+		return 0, false
+	}
+
+	// hex encoded number
+	var hexNum hexutil.Uint64
+	if err := json.Unmarshal(raw, &hexNum); err == nil {
+		return uint64(hexNum), true
+	}
+
+	// decimal number
+	var num uint64
+	if err := json.Unmarshal(raw, &num); err == nil {
+		return num, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockChainStatusProvider struct {
+	version  int
+	maxEpoch uint64
+}
+
+func (p *mockChainStatusProvider) GetReorgVersion() (int, error) {
+	return p.version, nil
+}
+
+func (p *mockChainStatusProvider) MaxEpoch() (uint64, bool, error) {
+	return p.maxEpoch, true, nil
+}
+
+type mockFinalizedEpochProvider uint64
+
+func (p *mockFinalizedEpochProvider) FinalizedEpoch() (uint64, error) {
+	return uint64(*p), nil
+}
+
+func TestCanonicalizeParams(t *testing.T) {
+	addr := "0x52908400098527886E0F7030069857D2E4169EE7"
+	hash := "0xA5D9A6A3E5D9A6A3E5D9A6A3E5D9A6A3E5D9A6A3E5D9A6A3E5D9A6A3E5D9A6A3"
+
+	p1, ok := canonicalizeParams(json.RawMessage(`[ "` + hash + `", {"to": "` + addr + `", "data": "0xFF"} ]`))
+	assert.True(t, ok)
+
+	p2, ok := canonicalizeParams(json.RawMessage(
+		`["` + strings.ToLower(hash) + `",{"data":"0xFF","to":"` + strings.ToLower(addr) + `"}]`,
+	))
+	assert.True(t, ok)
+	assert.Equal(t, p1, p2)
+
+	// other strings are case sensitive
+	p3, ok := canonicalizeParams(json.RawMessage(`[{"to": "` + addr + `", "data": "0xff"}]`))
+	assert.True(t, ok)
+	p4, ok := canonicalizeParams(json.RawMessage(`[{"to": "` + addr + `", "data": "0xFF"}]`))
+	assert.True(t, ok)
+	assert.NotEqual(t, p3, p4)
+
+	_, ok = canonicalizeParams(json.RawMessage(`[`))
+	assert.False(t, ok)
+}
+
+func TestIsCallCacheable(t *testing.T) {
+	assert.True(t, isCallCacheable("eth_getBlockByHash", json.RawMessage(`["0x01", false]`), 100))
+	assert.False(t, isCallCacheable("eth_getBlockByNumber", json.RawMessage(`["0x01", false]`), 100))
+
+	callArgs := `{"to": "0x01", "data": "0x02"}`
+	assert.True(t, isCallCacheable("eth_call", json.RawMessage(`[`+callArgs+`, "0x64"]`), 100))
+	assert.False(t, isCallCacheable("eth_call", json.RawMessage(`[`+callArgs+`, "0x65"]`), 100))
+	assert.False(t, isCallCacheable("eth_call", json.RawMessage(`[`+callArgs+`, "latest"]`), 100))
+	assert.False(t, isCallCacheable("eth_call", json.RawMessage(`[`+callArgs+`]`), 100))
+}
+
+func TestParseResultEpoch(t *testing.T) {
+	epoch, ok := parseResultEpoch(json.RawMessage(`{"blockNumber": "0x10"}`), []string{"blockNumber"})
+	assert.True(t, ok)
+	assert.Equal(t, uint64(16), epoch)
+
+	traces := json.RawMessage(`[{"blockNumber": 16}, {"blockNumber": 16}]`)
+	epoch, ok = parseResultEpoch(traces, []string{"blockNumber", "epochNumber"})
+	assert.True(t, ok)
+	assert.Equal(t, uint64(16), epoch)
+
+	traces = json.RawMessage(`[{"epochNumber": "0x11"}]`)
+	epoch, ok = parseResultEpoch(traces, []string{"blockNumber", "epochNumber"})
+	assert.True(t, ok)
+	assert.Equal(t, uint64(17), epoch)
+
+	_, ok = parseResultEpoch(json.RawMessage(`{"epochNumber": null}`), []string{"epochNumber"})
+	assert.False(t, ok)
+}
+
+func TestResponseCacheInvalidatedByReorg(t *testing.T) {
+	config := NewResponseCacheConfig()
+	config.RefreshInterval = time.Hour // refresh manually
+
+	provider := &mockChainStatusProvider{version: 1, maxEpoch: 100}
+	finalized := mockFinalizedEpochProvider(200)
+	cache := NewResponseCache("eth", provider, &finalized, config)
+
+	method, params := "eth_getTransactionReceipt", json.RawMessage(`["0x01"]`)
+
+	key, ok := cache.Key(method, params)
+	assert.True(t, ok)
+
+	// result not synchronized yet
+	cache.Add(key, method, json.RawMessage(`{"blockNumber": "0x65"}`))
+	_, ok = cache.Get(key)
+	assert.False(t, ok)
+
+	result := json.RawMessage(`{"blockNumber": "0x64"}`)
+	cache.Add(key, method, result)
+	cached, ok := cache.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, result, cached)
+
+	// chain reorg happened
+	provider.version = 2
+	assert.NoError(t, cache.refresh())
+
+	newKey, ok := cache.Key(method, params)
+	assert.True(t, ok)
+	assert.NotEqual(t, key, newKey)
+
+	_, ok = cache.Get(newKey)
+	assert.False(t, ok)
+}
+
+func TestResponseCacheOnlyFinalized(t *testing.T) {
+	config := NewResponseCacheConfig()
+	config.RefreshInterval = time.Hour // refresh manually
+
+	provider := &mockChainStatusProvider{version: 1, maxEpoch: 100}
+	finalized := mockFinalizedEpochProvider(90)
+	cache := NewResponseCache("eth", provider, &finalized, config)
+
+	method, params := "eth_getTransactionReceipt", json.RawMessage(`["0x01"]`)
+	key, ok := cache.Key(method, params)
+	assert.True(t, ok)
+
+	// synchronized but not finalized yet
+	cache.Add(key, method, json.RawMessage(`{"blockNumber": "0x5b"}`))
+	_, ok = cache.Get(key)
+	assert.False(t, ok)
+
+	// finalized
+	cache.Add(key, method, json.RawMessage(`{"blockNumber": "0x5a"}`))
+	_, ok = cache.Get(key)
+	assert.True(t, ok)
+
+	// eth_call at block not finalized yet
+	callArgs := `{"to": "0x01", "data": "0x02"}`
+	_, ok = cache.Key("eth_call", json.RawMessage(`[`+callArgs+`, "0x5b"]`))
+	assert.False(t, ok)
+}
@@ -73,6 +73,11 @@ func NewCfxClient(url string, options ...ClientOption) (*sdk.Client, error) {
 		return cfx, err
 	}
 
+	if len(opt.coalesceGroup) > 0 && cfxClientCfg.Coalesce {
+		// hooked in advance so that coalesced requests will not be counted as fullnode requests
+		cfx.Provider().HookCallContext(middlewareCoalesce("cfx", opt.coalesceGroup))
+	}
+
 	hookFlag := MiddlewareHookAll
 	if !opt.hookMetrics {
 		hookFlag ^= MiddlewareHookLogMetrics
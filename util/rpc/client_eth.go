@@ -75,6 +75,11 @@ func NewEthClient(url string, options ...ClientOption) (*web3go.Client, error) {
 		return eth, err
 	}
 
+	if len(opt.coalesceGroup) > 0 && ethClientCfg.Coalesce {
+		// hooked in advance so that coalesced requests will not be counted as fullnode requests
+		eth.Provider().HookCallContext(middlewareCoalesce("eth", opt.coalesceGroup))
+	}
+
 	hookFlag := MiddlewareHookAll
 	if !opt.hookMetrics {
 		hookFlag ^= MiddlewareHookLogMetrics
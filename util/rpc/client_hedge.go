@@ -0,0 +1,156 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Conflux-Chain/confura/util/accesslog"
+	"github.com/Conflux-Chain/confura/util/metrics"
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
+	"github.com/openweb3/go-rpc-provider/utils"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ctxKeyHedged = handlers.CtxKey("Infura-RPC-Hedged")
+)
+
+// HedgePolicy is the policy to send hedged requests to alternative full nodes.
+type HedgePolicy struct {
+	// latency threshold to send a hedged request
+	Delay time.Duration
+	// checks whether the RPC method is idempotent to send hedged requests
+	IsHedgeable func(method string) bool
+}
+
+// HedgeAlternative returns the node name and `CallContext` function of an alternative full node to send
+// hedged requests, or nil function if not available.
+type HedgeAlternative func() (nodeName string, fn providers.CallContextFunc)
+
+// MiddlewareHedge sends a hedged request to an alternative full node if the RPC call is not responded
+// within the latency threshold, and returns the first response that is not failed due to non-RPC error,
+// e.g., io error. The alternative full node is access logged if it served the RPC call.
+//
+// Note, hedged requests are sent at the client level rather than RPC server middlewares, because RPC
+// server middlewares could not be executed concurrently for the same call message.
+func MiddlewareHedge(
+	policy func() *HedgePolicy, alternative HedgeAlternative,
+) providers.CallContextMiddleware {
+	return func(handler providers.CallContextFunc) providers.CallContextFunc {
+		return func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			p := policy()
+			if p == nil || p.Delay <= 0 || ctx.Value(ctxKeyHedged) != nil || !p.IsHedgeable(method) {
+				return handler(ctx, result, method, args...)
+			}
+
+			return callHedged(ctx, p.Delay, handler, alternative, result, method, args...)
+		}
+	}
+}
+
+type hedgedCallResult struct {
+	result interface{}
+	err    error
+	hedged bool // whether responded by the hedged request
+}
+
+func callHedged(
+	ctx context.Context, delay time.Duration,
+	primary providers.CallContextFunc, alternative HedgeAlternative,
+	result interface{}, method string, args ...interface{},
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// results are decoded separately to avoid data race between concurrent calls
+	resultCh := make(chan hedgedCallResult, 2)
+	call := func(ctx context.Context, fn providers.CallContextFunc, hedged bool) {
+		res := hedgedCallResult{result: newResultOf(result), hedged: hedged}
+
+		defer func() {
+			if err := recover(); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"method":   method,
+					"panicErr": err,
+				}).Error("Hedged RPC call panic recovered")
+
+				res.err = errors.Errorf("hedged RPC call crashed: %v", err)
+			}
+
+			resultCh <- res
+		}()
+
+		res.err = fn(ctx, res.result, method, args...)
+	}
+
+	go call(ctx, primary, false)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case res := <-resultCh:
+		metrics.Registry.Client.Hedged(method).Mark(false)
+		return setHedgedResult(result, res)
+	case <-timer.C:
+	}
+
+	// never hedge once the RPC call itself is cancelled or timed out
+	if ctx.Err() != nil {
+		metrics.Registry.Client.Hedged(method).Mark(false)
+		return setHedgedResult(result, <-resultCh)
+	}
+
+	secondaryName, secondary := alternative()
+	metrics.Registry.Client.Hedged(method).Mark(secondary != nil)
+
+	if secondary == nil { // no alternative full node available
+		return setHedgedResult(result, <-resultCh)
+	}
+
+	go call(context.WithValue(ctx, ctxKeyHedged, true), secondary, true)
+
+	var res hedgedCallResult
+	for i := 0; i < 2; i++ {
+		if res = <-resultCh; res.err == nil || utils.IsRPCJSONError(res.err) {
+			break
+		}
+	}
+
+	if res.hedged {
+		accesslog.SetHedgedNode(ctx, secondaryName)
+	}
+
+	return setHedgedResult(result, res)
+}
+
+// newResultOf creates a new result pointer of the same type.
+func newResultOf(result interface{}) interface{} {
+	if result == nil {
+		return nil
+	}
+
+	if rv := reflect.ValueOf(result); rv.Kind() == reflect.Ptr {
+		return reflect.New(rv.Type().Elem()).Interface()
+	}
+
+	return result
+}
+
+func setHedgedResult(result interface{}, res hedgedCallResult) error {
+	if res.err != nil || result == nil {
+		return res.err
+	}
+
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("result must be a non-nil pointer, got: %T", result)
+	}
+
+	rv.Elem().Set(reflect.ValueOf(res.result).Elem())
+	return nil
+}
@@ -0,0 +1,224 @@
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Conflux-Chain/confura/types"
+	"github.com/Conflux-Chain/confura/util/accesslog"
+	"github.com/openweb3/go-rpc-provider"
+	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockCallContext(delay time.Duration, val string) providers.CallContextFunc {
+	return func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+		time.Sleep(delay)
+		*(result.(*string)) = val
+		return nil
+	}
+}
+
+func TestCallHedged(t *testing.T) {
+	primary := newMockCallContext(100*time.Millisecond, "primary")
+	alternative := func() (string, providers.CallContextFunc) {
+		return "node2", newMockCallContext(0, "secondary")
+	}
+
+	// primary responded within the latency threshold
+	var result string
+	err := callHedged(context.Background(), time.Second, primary, alternative, &result, "eth_call")
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", result)
+
+	// hedged request responded at first
+	err = callHedged(context.Background(), 10*time.Millisecond, primary, alternative, &result, "eth_call")
+	assert.NoError(t, err)
+	assert.Equal(t, "secondary", result)
+
+	// no alternative full node available
+	err = callHedged(context.Background(), 10*time.Millisecond, primary, func() (string, providers.CallContextFunc) {
+		return "", nil
+	}, &result, "eth_call")
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", result)
+}
+
+func TestCallHedgedAccessLog(t *testing.T) {
+	primary := newMockCallContext(100*time.Millisecond, "primary")
+	alternative := func() (string, providers.CallContextFunc) {
+		return "node2", newMockCallContext(0, "secondary")
+	}
+
+	entry := &types.AccessLog{Node: "node1"}
+	ctx := accesslog.NewContextWithEntry(context.Background(), entry)
+
+	// routed node access logged if responded in time
+	var result string
+	assert.NoError(t, callHedged(ctx, time.Second, primary, alternative, &result, "eth_call"))
+	assert.Equal(t, "node1", entry.Node)
+
+	// hedged node access logged if it won
+	assert.NoError(t, callHedged(ctx, 10*time.Millisecond, primary, alternative, &result, "eth_call"))
+	assert.Equal(t, "secondary", result)
+	assert.Equal(t, "node2", entry.Node)
+}
+
+// stubCall is a stub full node that responds after the delay unless the call is cancelled.
+type stubCall struct {
+	delay time.Duration
+	val   string
+	err   error
+
+	calls     int32
+	hedged    int32 // number of calls marked as hedged
+	cancelled int32
+}
+
+func (s *stubCall) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	atomic.AddInt32(&s.calls, 1)
+	if ctx.Value(ctxKeyHedged) != nil {
+		atomic.AddInt32(&s.hedged, 1)
+	}
+
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		atomic.AddInt32(&s.cancelled, 1)
+		return ctx.Err()
+	}
+
+	if s.err != nil {
+		return s.err
+	}
+
+	*(result.(*string)) = s.val
+	return nil
+}
+
+func TestMiddlewareHedge(t *testing.T) {
+	rpcErr := &rpc.JsonError{Code: -32000, Message: "execution reverted"}
+	ioErr := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		method    string
+		hedged    bool // whether already a hedged call
+		primary   stubCall
+		secondary stubCall
+
+		expectedResult     string
+		expectedErr        error
+		expectedSecondary  int32 // number of calls to the alternative full node
+		primaryCancelled   bool
+		secondaryCancelled bool
+	}{
+		{
+			name:           "primary responded in time",
+			method:         "eth_call",
+			primary:        stubCall{delay: 10 * time.Millisecond, val: "primary"},
+			secondary:      stubCall{val: "secondary"},
+			expectedResult: "primary",
+		},
+		{
+			name:              "loser primary cancelled",
+			method:            "eth_call",
+			primary:           stubCall{delay: time.Minute, val: "primary"},
+			secondary:         stubCall{val: "secondary"},
+			expectedResult:    "secondary",
+			expectedSecondary: 1,
+			primaryCancelled:  true,
+		},
+		{
+			name:               "loser secondary cancelled",
+			method:             "eth_call",
+			primary:            stubCall{delay: 100 * time.Millisecond, val: "primary"},
+			secondary:          stubCall{delay: time.Minute, val: "secondary"},
+			expectedResult:     "primary",
+			expectedSecondary:  1,
+			secondaryCancelled: true,
+		},
+		{
+			name:           "non-hedgeable method",
+			method:         "eth_sendRawTransaction",
+			primary:        stubCall{delay: 100 * time.Millisecond, val: "primary"},
+			secondary:      stubCall{val: "secondary"},
+			expectedResult: "primary",
+		},
+		{
+			name:           "already hedged",
+			method:         "eth_call",
+			hedged:         true,
+			primary:        stubCall{delay: 100 * time.Millisecond, val: "primary"},
+			secondary:      stubCall{val: "secondary"},
+			expectedResult: "primary",
+		},
+		{
+			name:        "primary RPC error returned in time",
+			method:      "eth_call",
+			primary:     stubCall{err: rpcErr},
+			secondary:   stubCall{val: "secondary"},
+			expectedErr: rpcErr,
+		},
+		{
+			name:               "primary RPC error returned after hedged",
+			method:             "eth_call",
+			primary:            stubCall{delay: 100 * time.Millisecond, err: rpcErr},
+			secondary:          stubCall{delay: time.Minute, val: "secondary"},
+			expectedErr:        rpcErr,
+			expectedSecondary:  1,
+			secondaryCancelled: true,
+		},
+		{
+			name:              "primary io error hedged",
+			method:            "eth_call",
+			primary:           stubCall{delay: 100 * time.Millisecond, err: ioErr},
+			secondary:         stubCall{delay: 200 * time.Millisecond, val: "secondary"},
+			expectedResult:    "secondary",
+			expectedSecondary: 1,
+		},
+	}
+
+	policy := &HedgePolicy{
+		Delay:       50 * time.Millisecond,
+		IsHedgeable: func(method string) bool { return method == "eth_call" },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, secondary := tt.primary, tt.secondary
+			alternative := func() (string, providers.CallContextFunc) {
+				return "node2", secondary.call
+			}
+
+			handler := MiddlewareHedge(func() *HedgePolicy { return policy }, alternative)(primary.call)
+
+			ctx := context.Background()
+			if tt.hedged {
+				ctx = context.WithValue(ctx, ctxKeyHedged, true)
+			}
+
+			var result string
+			err := handler(ctx, &result, tt.method)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			assert.Equal(t, int32(1), atomic.LoadInt32(&primary.calls))
+			assert.Equal(t, tt.expectedSecondary, atomic.LoadInt32(&secondary.calls))
+			assert.Equal(t, tt.expectedSecondary, atomic.LoadInt32(&secondary.hedged))
+
+			// losers are cancelled asynchronously once the winner responded
+			assert.Eventually(t, func() bool {
+				return (atomic.LoadInt32(&primary.cancelled) == 1) == tt.primaryCancelled &&
+					(atomic.LoadInt32(&secondary.cancelled) == 1) == tt.secondaryCancelled
+			}, time.Second, time.Millisecond)
+		})
+	}
+}
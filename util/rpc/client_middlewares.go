@@ -2,15 +2,18 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Conflux-Chain/confura/util"
 	"github.com/Conflux-Chain/confura/util/metrics"
 	"github.com/Conflux-Chain/confura/util/rpc/cache"
 	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/Conflux-Chain/confura/util/tracing"
 	sdk "github.com/Conflux-Chain/go-conflux-sdk"
 	"github.com/Conflux-Chain/go-conflux-sdk/types"
 	"github.com/openweb3/go-rpc-provider"
@@ -21,12 +24,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CacheHandlerFunc[T any] func(client T, nodeName string, args ...interface{}) (interface{}, bool, error)
 
 var (
 	cacheFnClients   util.ConcurrentMap
+	coalesceGroups   util.ConcurrentMap                              // space/group => *callCoalescer
 	cfxCacheHandlers map[string]CacheHandlerFunc[sdk.ClientOperator] // method => cache handler
 	ethCacheHandlers map[string]CacheHandlerFunc[*web3go.Client]     // method => cache handler
 
@@ -127,6 +133,9 @@ const (
 
 	// MiddlewareHookCache enables cache middleware hook.
 	MiddlewareHookCache
+
+	// MiddlewareHookTrace enables tracing middleware hook.
+	MiddlewareHookTrace
 )
 
 func HookMiddlewares(provider *providers.MiddlewarableProvider, url, space string, flags ...MiddlewareHookFlag) {
@@ -137,6 +146,10 @@ func HookMiddlewares(provider *providers.MiddlewarableProvider, url, space strin
 		flag = flags[0]
 	}
 
+	if flag&MiddlewareHookTrace != 0 && tracing.Enabled() {
+		provider.HookCallContext(middlewareTrace(nodeName, space))
+	}
+
 	if flag&MiddlewareHookLog != 0 {
 		provider.HookCallContext(middlewareLog(nodeName, space))
 	}
@@ -152,11 +165,15 @@ func HookMiddlewares(provider *providers.MiddlewarableProvider, url, space strin
 
 func middlewareMetrics(fullnode, space string) providers.CallContextMiddleware {
 	return func(handler providers.CallContextFunc) providers.CallContextFunc {
+		stats := GetNodeStats(fullnode)
+
 		return func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
 			start := time.Now()
+			stats.begin()
 
 			err := handler(ctx, result, method, args...)
 
+			stats.end(time.Since(start))
 			metrics.Registry.RPC.FullnodeQps(fullnode, space, method, err).UpdateSince(start)
 
 			// overall error rate for each full node
@@ -171,6 +188,58 @@ func middlewareMetrics(fullnode, space string) providers.CallContextMiddleware {
 	}
 }
 
+// middlewareTrace starts a client span for each outbound full node call, whose trace context is
+// propagated to full node in `traceparent` header.
+func middlewareTrace(fullnode, space string) providers.CallContextMiddleware {
+	return func(handler providers.CallContextFunc) providers.CallContextFunc {
+		return func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			ctx, span := tracing.Start(ctx, "fullnode."+method,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("rpc.system", "jsonrpc"),
+					attribute.String("rpc.method", method),
+					attribute.String("node.name", fullnode),
+					attribute.String("node.space", space),
+				),
+			)
+
+			err := handler(ctx, result, method, args...)
+			tracing.End(span, err)
+
+			return err
+		}
+	}
+}
+
+// hookTraceHeader registers an event handler before sending client HTTP request, which will
+// propagate trace context in `traceparent` header to the requested RPC service.
+func hookTraceHeader() {
+	rpc.RegisterBeforeSendHttp(func(ctx context.Context, req *fasthttp.Request) error {
+		tracing.Inject(ctx, (*fasthttpHeaderCarrier)(&req.Header))
+		return nil
+	})
+}
+
+// fasthttpHeaderCarrier adapts fasthttp request header to propagate trace context.
+type fasthttpHeaderCarrier fasthttp.RequestHeader
+
+func (c *fasthttpHeaderCarrier) Get(key string) string {
+	return string((*fasthttp.RequestHeader)(c).Peek(key))
+}
+
+func (c *fasthttpHeaderCarrier) Set(key, value string) {
+	(*fasthttp.RequestHeader)(c).Set(key, value)
+}
+
+func (c *fasthttpHeaderCarrier) Keys() []string {
+	var keys []string
+	(*fasthttp.RequestHeader)(c).VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+
+	return keys
+}
+
 func middlewareLog(fullnode, space string) providers.CallContextMiddleware {
 	return func(handler providers.CallContextFunc) providers.CallContextFunc {
 		return func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
@@ -204,6 +273,199 @@ func middlewareLog(fullnode, space string) providers.CallContextMiddleware {
 	}
 }
 
+// middlewareCoalesce deduplicates identical in-flight RPC calls among all clients of the same node group,
+// so that only one request is sent to full node and the response is fanned out to all waiters.
+func middlewareCoalesce(space, group string) providers.CallContextMiddleware {
+	key := fmt.Sprintf("%v/%v", space, group)
+	val, _ := coalesceGroups.LoadOrStore(key, newCallCoalescer())
+	coalescer := val.(*callCoalescer)
+
+	return func(handler providers.CallContextFunc) providers.CallContextFunc {
+		return func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			if !isCoalescableMethod(method) {
+				return handler(ctx, result, method, args...)
+			}
+
+			callKey, err := coalesceCallKey(method, args...)
+			if err != nil { // not coalescable, e.g., unmarshallable arguments
+				return handler(ctx, result, method, args...)
+			}
+
+			coalesced, err := coalescer.do(ctx, callKey, result, func() error {
+				return handler(ctx, result, method, args...)
+			})
+			metrics.Registry.Client.Coalesced(space, group, method).Mark(coalesced)
+
+			// in case of the leading call cancelled, fall back to request full node on its own
+			if coalesced && isContextError(err) && ctx.Err() == nil {
+				return handler(ctx, result, method, args...)
+			}
+
+			return err
+		}
+	}
+}
+
+// coalescableMethods are read-only and idempotent methods that are safe to coalesce, while other
+// methods are never coalesced, e.g., stateful or with side effect.
+var coalescableMethods = map[string]bool{
+	// core space
+	"cfx_epochNumber":                       true,
+	"cfx_getStatus":                         true,
+	"cfx_clientVersion":                     true,
+	"cfx_gasPrice":                          true,
+	"cfx_maxPriorityFeePerGas":              true,
+	"cfx_feeHistory":                        true,
+	"cfx_getFeeBurnt":                       true,
+	"cfx_getBalance":                        true,
+	"cfx_getStakingBalance":                 true,
+	"cfx_getCollateralForStorage":           true,
+	"cfx_getCollateralInfo":                 true,
+	"cfx_getAdmin":                          true,
+	"cfx_getCode":                           true,
+	"cfx_getStorageAt":                      true,
+	"cfx_getStorageRoot":                    true,
+	"cfx_getSponsorInfo":                    true,
+	"cfx_getNextNonce":                      true,
+	"cfx_getAccount":                        true,
+	"cfx_getInterestRate":                   true,
+	"cfx_getAccumulateInterestRate":         true,
+	"cfx_getDepositList":                    true,
+	"cfx_getVoteList":                       true,
+	"cfx_getSupplyInfo":                     true,
+	"cfx_getParamsFromVote":                 true,
+	"cfx_getPoSEconomics":                   true,
+	"cfx_getPoSRewardByEpoch":               true,
+	"cfx_getBlockRewardInfo":                true,
+	"cfx_call":                              true,
+	"cfx_estimateGasAndCollateral":          true,
+	"cfx_checkBalanceAgainstTransaction":    true,
+	"cfx_getBestBlockHash":                  true,
+	"cfx_getBlockByHash":                    true,
+	"cfx_getBlockByEpochNumber":             true,
+	"cfx_getBlockByBlockNumber":             true,
+	"cfx_getBlockByHashWithPivotAssumption": true,
+	"cfx_getBlocksByEpoch":                  true,
+	"cfx_getSkippedBlocksByEpoch":           true,
+	"cfx_getTransactionByHash":              true,
+	"cfx_getTransactionReceipt":             true,
+	"cfx_getEpochReceipts":                  true,
+	"cfx_getConfirmationRiskByHash":         true,
+	"cfx_getLogs":                           true,
+
+	// evm space
+	"eth_blockNumber":                         true,
+	"eth_chainId":                             true,
+	"eth_syncing":                             true,
+	"eth_gasPrice":                            true,
+	"eth_maxPriorityFeePerGas":                true,
+	"eth_feeHistory":                          true,
+	"eth_getBalance":                          true,
+	"eth_getCode":                             true,
+	"eth_getStorageAt":                        true,
+	"eth_getTransactionCount":                 true,
+	"eth_call":                                true,
+	"eth_estimateGas":                         true,
+	"eth_getBlockByHash":                      true,
+	"eth_getBlockByNumber":                    true,
+	"eth_getBlockTransactionCountByHash":      true,
+	"eth_getBlockTransactionCountByNumber":    true,
+	"eth_getTransactionByHash":                true,
+	"eth_getTransactionByBlockHashAndIndex":   true,
+	"eth_getTransactionByBlockNumberAndIndex": true,
+	"eth_getTransactionReceipt":               true,
+	"eth_getBlockReceipts":                    true,
+	"eth_getLogs":                             true,
+	"net_version":                             true,
+	"web3_clientVersion":                      true,
+	"parity_getBlockReceipts":                 true,
+	"trace_block":                             true,
+	"trace_transaction":                       true,
+	"trace_filter":                            true,
+}
+
+func isCoalescableMethod(method string) bool {
+	return coalescableMethods[method]
+}
+
+func coalesceCallKey(method string, args ...interface{}) (string, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+
+	return method + string(data), nil
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// inflightCall is an in-flight RPC call that is waited by coalesced calls.
+type inflightCall struct {
+	done    chan struct{}
+	waiters int             // number of coalesced calls
+	data    json.RawMessage // JSON encoded result, only available when waited by coalesced calls
+	err     error
+}
+
+// callCoalescer is similar to `singleflight.Group`, but only encodes the result of the leading call
+// when there are coalesced calls waiting for it.
+type callCoalescer struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+func newCallCoalescer() *callCoalescer {
+	return &callCoalescer{calls: make(map[string]*inflightCall)}
+}
+
+// do executes the call for the key if there is no identical in-flight one, otherwise waits for the
+// in-flight call and decodes its result into the specified result pointer. Returns true if coalesced.
+func (c *callCoalescer) do(ctx context.Context, key string, result interface{}, call func() error) (bool, error) {
+	c.mu.Lock()
+
+	if ic, ok := c.calls[key]; ok {
+		ic.waiters++
+		c.mu.Unlock()
+
+		select {
+		case <-ic.done:
+		case <-ctx.Done():
+			return true, ctx.Err()
+		}
+
+		if ic.err != nil {
+			return true, ic.err
+		}
+
+		return true, json.Unmarshal(ic.data, result)
+	}
+
+	ic := &inflightCall{done: make(chan struct{})}
+	c.calls[key] = ic
+	c.mu.Unlock()
+
+	err := call()
+
+	// no more coalesced calls once removed
+	c.mu.Lock()
+	delete(c.calls, key)
+	waiters := ic.waiters
+	c.mu.Unlock()
+
+	if waiters > 0 {
+		ic.err = err
+		if err == nil {
+			ic.data, ic.err = json.Marshal(result)
+		}
+	}
+
+	close(ic.done)
+
+	return false, err
+}
+
 func middlewareCache(url, space string) providers.CallContextMiddleware {
 	switch space {
 	case "eth":
@@ -0,0 +1,100 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCallCoalescer(t *testing.T) {
+	coalescer := newCallCoalescer()
+
+	var calls int32
+	release := make(chan struct{})
+
+	call := func(result *[]uint64) func() error {
+		return func() error {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			*result = []uint64{1, 2, 3}
+			return nil
+		}
+	}
+
+	var (
+		wg        sync.WaitGroup
+		coalesced int32
+		results   = make([][]uint64, 5)
+	)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			ok, err := coalescer.do(context.Background(), "key", &results[i], call(&results[i]))
+			assert.NoError(t, err)
+			if ok {
+				atomic.AddInt32(&coalesced, 1)
+			}
+		}(i)
+	}
+
+	// wait for all calls to be in-flight
+	assert.Eventually(t, func() bool {
+		coalescer.mu.Lock()
+		defer coalescer.mu.Unlock()
+
+		ic, ok := coalescer.calls["key"]
+		return ok && ic.waiters == len(results)-1
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+	assert.Equal(t, int32(len(results)-1), coalesced)
+	for _, result := range results {
+		assert.Equal(t, []uint64{1, 2, 3}, result)
+	}
+}
+
+func TestIsCoalescableMethod(t *testing.T) {
+	assert.True(t, isCoalescableMethod("eth_getBlockByNumber"))
+	assert.False(t, isCoalescableMethod("eth_sendRawTransaction"))
+	assert.False(t, isCoalescableMethod("cfx_getFilterChanges"))
+
+	// methods not listed are never coalesced
+	assert.False(t, isCoalescableMethod("txpool_content"))
+	assert.False(t, isCoalescableMethod("eth_sendRawTransactionConditional"))
+	assert.False(t, isCoalescableMethod("eth_newFilter"))
+}
+
+func TestFasthttpHeaderCarrier(t *testing.T) {
+	propagator := propagation.TraceContext{}
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceId, SpanID: spanId, TraceFlags: trace.FlagsSampled,
+	})
+
+	var req fasthttp.Request
+	carrier := (*fasthttpHeaderCarrier)(&req.Header)
+	propagator.Inject(trace.ContextWithSpanContext(context.Background(), spanCtx), carrier)
+
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", string(req.Header.Peek("traceparent")))
+	assert.Contains(t, carrier.Keys(), "Traceparent")
+
+	extracted := trace.SpanContextFromContext(propagator.Extract(context.Background(), carrier))
+	assert.Equal(t, traceId, extracted.TraceID())
+	assert.Equal(t, spanId, extracted.SpanID())
+	assert.True(t, extracted.IsRemote())
+}
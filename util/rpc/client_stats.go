@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Conflux-Chain/confura/util"
+)
+
+const (
+	// smoothing factor of the exponentially weighted moving average latency
+	nodeStatsEwmaAlpha = 0.2
+	// time constant to decay the weight of the EWMA latency since the last completed request, so that
+	// a stale latency (e.g., node rarely picked due to a latency spike) is quickly replaced
+	nodeStatsEwmaDecay = 10 * time.Second
+	// the EWMA latency expires if no request completed for a while, so that routing falls back to the
+	// heartbeat latency, which keeps refreshing even if the node is never picked
+	nodeStatsEwmaTTL = time.Minute
+)
+
+var nodeStats util.ConcurrentMap // node name => *NodeStats
+
+// NodeStats is the statistics of RPC requests sent to a full node, which is collected by the metrics
+// client middleware and used for load-aware routing.
+type NodeStats struct {
+	inflight int64 // number of outstanding requests
+
+	mu          sync.Mutex
+	ewmaLatency float64   // in nanoseconds
+	lastUpdated time.Time // when the EWMA latency last updated
+}
+
+// GetNodeStats returns the RPC request statistics of the specified full node.
+func GetNodeStats(nodeName string) *NodeStats {
+	val, _ := nodeStats.LoadOrStoreFn(nodeName, func(interface{}) interface{} {
+		return &NodeStats{}
+	})
+
+	return val.(*NodeStats)
+}
+
+// Inflight returns the number of outstanding requests.
+func (s *NodeStats) Inflight() int64 {
+	return atomic.LoadInt64(&s.inflight)
+}
+
+// EwmaLatency returns the exponentially weighted moving average latency, or 0 if no request completed
+// recently.
+func (s *NodeStats) EwmaLatency() time.Duration {
+	return s.ewmaLatencyAt(time.Now())
+}
+
+func (s *NodeStats) ewmaLatencyAt(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastUpdated) > nodeStatsEwmaTTL {
+		return 0
+	}
+
+	return time.Duration(s.ewmaLatency)
+}
+
+func (s *NodeStats) begin() {
+	atomic.AddInt64(&s.inflight, 1)
+}
+
+func (s *NodeStats) end(elapsed time.Duration) {
+	atomic.AddInt64(&s.inflight, -1)
+	s.updateLatencyAt(elapsed, time.Now())
+}
+
+func (s *NodeStats) updateLatencyAt(elapsed time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ewmaLatency == 0 || now.Sub(s.lastUpdated) > nodeStatsEwmaTTL {
+		s.ewmaLatency = float64(elapsed)
+	} else {
+		// the older the EWMA latency, the less weight it has
+		decay := math.Exp(-float64(now.Sub(s.lastUpdated)) / float64(nodeStatsEwmaDecay))
+		weight := (1 - nodeStatsEwmaAlpha) * decay
+		s.ewmaLatency = (1-weight)*float64(elapsed) + weight*s.ewmaLatency
+	}
+
+	s.lastUpdated = now
+}
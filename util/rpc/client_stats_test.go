@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeStatsEwmaLatencyDecay(t *testing.T) {
+	now := time.Now()
+	s := &NodeStats{}
+
+	s.updateLatencyAt(time.Second, now)
+	assert.Equal(t, time.Second, s.ewmaLatencyAt(now))
+
+	// recent requests smoothed by EWMA
+	s.updateLatencyAt(0, now)
+	assert.Equal(t, 800*time.Millisecond, s.ewmaLatencyAt(now))
+
+	// the older the EWMA latency, the more weight the new sample has
+	s.updateLatencyAt(100*time.Millisecond, now.Add(nodeStatsEwmaDecay))
+	latency := s.ewmaLatencyAt(now.Add(nodeStatsEwmaDecay))
+	assert.Less(t, latency, 400*time.Millisecond)
+	assert.Greater(t, latency, 100*time.Millisecond)
+
+	// expired if no request completed for a while
+	expiredAt := now.Add(nodeStatsEwmaDecay + nodeStatsEwmaTTL + time.Second)
+	assert.Zero(t, s.ewmaLatencyAt(expiredAt))
+
+	s.updateLatencyAt(50*time.Millisecond, expiredAt)
+	assert.Equal(t, 50*time.Millisecond, s.ewmaLatencyAt(expiredAt))
+}
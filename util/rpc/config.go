@@ -3,6 +3,7 @@ package rpc
 import (
 	"time"
 
+	"github.com/Conflux-Chain/confura/util/tracing"
 	"github.com/Conflux-Chain/go-conflux-util/viper"
 )
 
@@ -25,6 +26,7 @@ type clientConfig struct {
 	RequestTimeout  time.Duration `default:"3s"`
 	MaxConnsPerHost int           `default:"1024"`
 	CircuitBreaker  circuitBreakerConfig
+	Coalesce        bool // whether to coalesce identical in-flight requests per node group
 }
 
 type ClientOptioner interface {
@@ -34,12 +36,14 @@ type ClientOptioner interface {
 	SetMaxConnsPerHost(maxConns int)
 	SetHookMetrics(hook bool)
 	SetHookCache(hook bool)
+	SetHookCoalesce(group string)
 	SetCircuitBreaker(maxFail int, failTimeWindow, openColdTime time.Duration)
 }
 
 type baseClientOption struct {
-	hookMetrics bool
-	hookCache   bool
+	hookMetrics   bool
+	hookCache     bool
+	coalesceGroup string // node group to coalesce identical in-flight requests
 }
 
 func (o *baseClientOption) SetHookMetrics(hook bool) {
@@ -50,6 +54,10 @@ func (o *baseClientOption) SetHookCache(hook bool) {
 	o.hookCache = hook
 }
 
+func (o *baseClientOption) SetHookCoalesce(group string) {
+	o.coalesceGroup = group
+}
+
 type ClientOption func(opt ClientOptioner)
 
 func WithClientRetryCount(retry int) ClientOption {
@@ -88,6 +96,14 @@ func WithClientHookCache(hook bool) ClientOption {
 	}
 }
 
+// WithClientHookCoalesce coalesces identical in-flight requests among clients of the same node group,
+// which only takes effect if coalescing is enabled in the client configurations.
+func WithClientHookCoalesce(group string) ClientOption {
+	return func(opt ClientOptioner) {
+		opt.SetHookCoalesce(group)
+	}
+}
+
 func WithCircuitBreaker(maxFail int, failTimeWindow, openColdTime time.Duration) ClientOption {
 	return func(opt ClientOptioner) {
 		opt.SetCircuitBreaker(maxFail, failTimeWindow, openColdTime)
@@ -97,4 +113,8 @@ func WithCircuitBreaker(maxFail int, failTimeWindow, openColdTime time.Duration)
 func MustInit() {
 	viper.MustUnmarshalKey("cfx", &cfxClientCfg)
 	viper.MustUnmarshalKey("eth", &ethClientCfg)
+
+	if tracing.Enabled() {
+		hookTraceHeader()
+	}
 }
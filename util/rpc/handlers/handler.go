@@ -9,8 +9,9 @@ type Middleware func(next http.Handler) http.Handler
 type CtxKey string
 
 const (
-	CtxKeyRateRegistry = CtxKey("Infura-Rate-Limit-Registry")
-	CtxKeyAuthId       = CtxKey("Infura-Auth-ID")
+	CtxKeyRateRegistry  = CtxKey("Infura-Rate-Limit-Registry")
+	CtxKeyAuthId        = CtxKey("Infura-Auth-ID")
+	CtxKeyResponseCache = CtxKey("Infura-Response-Cache")
 
 	CtxKeyRealIP      = CtxKey("Infura-Real-IP")
 	CtxKeyAccessToken = CtxKey("Infura-Access-Token")
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CtxKeyRateLimitStatus = CtxKey("Infura-Rate-Limit-Status")
+)
+
+// RateLimitStatus is the rate limit status of an HTTP request, which will be exposed to clients
+// through response headers if the request is subject to any rate limit rule.
+type RateLimitStatus struct {
+	mu sync.Mutex
+
+	permitted  bool // whether quota status available for the permitted request
+	limited    bool
+	limit      int
+	remaining  int
+	reset      time.Time
+	retryAfter time.Duration
+}
+
+// Update updates the rate limit status, and keeps the most restrictive one, e.g., for batch requests.
+func (s *RateLimitStatus) Update(limit, remaining int, reset time.Time, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.limited && retryAfter < s.retryAfter {
+		return
+	}
+
+	s.limited = true
+	s.limit, s.remaining = limit, remaining
+	s.reset, s.retryAfter = reset, retryAfter
+}
+
+// Permit updates the quota status of a permitted request, and keeps the one with the least remaining
+// quota, e.g., for multiple limit rules, unless rate limited.
+func (s *RateLimitStatus) Permit(limit, remaining int, reset time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.limited || (s.permitted && remaining >= s.remaining) {
+		return
+	}
+
+	s.permitted = true
+	s.limit, s.remaining, s.reset = limit, remaining, reset
+}
+
+// writeHeaders writes rate limit headers if subject to any rate limit rule, and the `Retry-After`
+// header only if rate limited.
+func (s *RateLimitStatus) writeHeaders(h http.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.limited && !s.permitted {
+		return
+	}
+
+	h.Set("X-RateLimit-Limit", strconv.Itoa(s.limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(s.remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(s.reset.Unix(), 10))
+
+	if s.limited {
+		retryAfterSecs := int64(math.Ceil(s.retryAfter.Seconds()))
+		h.Set("Retry-After", strconv.FormatInt(retryAfterSecs, 10))
+	}
+}
+
+func GetRateLimitStatusFromContext(ctx context.Context) (*RateLimitStatus, bool) {
+	val, ok := ctx.Value(CtxKeyRateLimitStatus).(*RateLimitStatus)
+	return val, ok
+}
+
+// rateLimitResponseWriter writes rate limit headers before the response is written.
+type rateLimitResponseWriter struct {
+	http.ResponseWriter
+
+	status      *RateLimitStatus
+	wroteHeader bool
+}
+
+func (w *rateLimitResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status.writeHeaders(w.Header())
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *rateLimitResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *rateLimitResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// RateLimitHeaders is the HTTP middleware to emit rate limit headers (`X-RateLimit-Limit`,
+// `X-RateLimit-Remaining` and `X-RateLimit-Reset`) for requests subject to any rate limit rule, along
+// with the `Retry-After` header if request rate limited.
+//
+// Note, websocket connections are not affected, and clients could only get the hints from the
+// JSON-RPC error data.
+func RateLimitHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") { // websocket requires `http.Hijacker`
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		status := &RateLimitStatus{}
+		ctx := context.WithValue(r.Context(), CtxKeyRateLimitStatus, status)
+
+		rw := &rateLimitResponseWriter{ResponseWriter: w, status: status}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/Conflux-Chain/confura/util/accesslog"
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/openweb3/go-rpc-provider"
+)
+
+// AccessLog records structured access log for each RPC call if access logger injected, where the
+// routed full node and store hit are populated by the inner handlers.
+func AccessLog(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
+	return func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+		logger, ok := accesslog.FromContext(ctx)
+		if !ok {
+			return next(ctx, msg)
+		}
+
+		entry := logger.Begin(msg.Method)
+		entry.Key, _ = handlers.GetAuthIdFromContext(ctx)
+		entry.IP, _ = handlers.GetIPAddressFromContext(ctx)
+
+		if vs, ok := handlers.VipStatusFromContext(ctx); ok {
+			entry.VipTier = int(vs.Tier)
+		}
+
+		resp := next(accesslog.NewContextWithEntry(ctx, entry), msg)
+
+		if resp != nil {
+			entry.ResponseSize = len(resp.Result)
+
+			if resp.Error != nil {
+				entry.ErrorCode = resp.Error.Code
+			}
+		}
+
+		logger.End(entry)
+
+		return resp
+	}
+}
@@ -37,3 +37,16 @@ func Authenticate(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
 		return next(ctx, msg)
 	}
 }
+
+// authenticateSVip injects the auth ID of SVIP user into context if access token provided.
+func authenticateSVip(ctx context.Context) context.Context {
+	if !handlers.IsAccessTokenValid(ctx) {
+		return ctx
+	}
+
+	if svs, ok := rate.SVipStatusFromContext(ctx); ok {
+		return context.WithValue(ctx, handlers.CtxKeyAuthId, svs.Key)
+	}
+
+	return ctx
+}
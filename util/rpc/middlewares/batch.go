@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Conflux-Chain/confura/util/rate"
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ctxKeyBatchStatus = handlers.CtxKey("Infura-Batch-Status")
+)
+
+var (
+	errBatchTimeout = errors.New("batch request timed out, please reduce the batch size")
+)
+
+// batchConfig controls for batch requests
+type batchConfig struct {
+	// max number of requests within a batch, which could be overridden by rate limit strategy
+	MaxSize int
+	// timeout to execute the whole batch
+	Timeout time.Duration
+	// whether to rate limit a batch as N requests up front
+	RateLimitUpfront bool
+}
+
+// batchStatus is shared by all the call messages within a batch request.
+type batchStatus struct {
+	rejectErr error     // error to reject all call messages
+	deadline  time.Time // deadline to execute the whole batch
+
+	// group and key of the overall QPS rate limit charged up front if any
+	qpsGroup, qpsKey string
+}
+
+// Batch returns the batch middleware to apply the size, timeout and up front rate limit controls.
+//
+// Note, responses of batch requests are written by the RPC handler rather than the returned messages
+// of batch middlewares, so the controls are shared through context and applied to call messages one
+// by one in `BatchCall`. Besides, call messages within a batch are executed sequentially by the RPC
+// handler, so a batch never fans out concurrent full node calls, and the whole batch timeout bounds
+// its total execution time.
+func Batch() rpc.HandleBatchMiddleware {
+	var conf batchConfig
+	viper.MustUnmarshalKey("requestControl.batch", &conf)
+
+	logrus.WithField("config", conf).Debug("Batch RPC middleware initialized")
+
+	return func(next rpc.HandleBatchFunc) rpc.HandleBatchFunc {
+		return func(ctx context.Context, msgs []*rpc.JsonRpcMessage) []*rpc.JsonRpcMessage {
+			// call messages within a batch will be authenticated again separately
+			ctx = authenticateSVip(ctx)
+
+			status := &batchStatus{}
+			registry, _ := ctx.Value(handlers.CtxKeyRateRegistry).(*rate.Registry)
+
+			maxSize := conf.MaxSize
+			if registry != nil {
+				if caps, ok := registry.GetStrategyCaps(ctx); ok && caps.MaxBatchSize > 0 {
+					maxSize = caps.MaxBatchSize
+				}
+			}
+
+			if maxSize > 0 && len(msgs) > maxSize {
+				status.rejectErr = errBatchSizeTooLarge(maxSize)
+			} else if conf.RateLimitUpfront && registry != nil {
+				if err := registry.LimitN(ctx, "rpc_all_qps", len(msgs)); err != nil {
+					status.rejectErr = errQpsRateLimited(ctx, err)
+				} else {
+					status.qpsGroup, status.qpsKey, _ = registry.GetGroupAndKey(ctx, "rpc_all_qps")
+				}
+			}
+
+			if conf.Timeout > 0 {
+				status.deadline = time.Now().Add(conf.Timeout)
+			}
+
+			return next(context.WithValue(ctx, ctxKeyBatchStatus, status), msgs)
+		}
+	}
+}
+
+// BatchCall applies the batch controls to call messages within a batch request.
+func BatchCall(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
+	return func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+		status, ok := ctx.Value(ctxKeyBatchStatus).(*batchStatus)
+		if !ok {
+			return next(ctx, msg)
+		}
+
+		if status.rejectErr != nil {
+			return msg.ErrorResponse(status.rejectErr)
+		}
+
+		if !status.deadline.IsZero() {
+			if time.Now().After(status.deadline) {
+				return msg.ErrorResponse(errBatchTimeout)
+			}
+
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, status.deadline)
+			defer cancel()
+		}
+
+		return next(ctx, msg)
+	}
+}
+
+// isBatchQpsCharged checks if the overall QPS rate limit is already charged for the whole batch.
+//
+// Note, the batch is charged up front before the call messages are fully authenticated, e.g., web3pay
+// VIP users, so it's only regarded as charged if the same limiter applies to the call message.
+func isBatchQpsCharged(ctx context.Context, registry *rate.Registry) bool {
+	status, ok := ctx.Value(ctxKeyBatchStatus).(*batchStatus)
+	if !ok || len(status.qpsGroup) == 0 {
+		return false
+	}
+
+	group, key, err := registry.GetGroupAndKey(ctx, "rpc_all_qps")
+	return err == nil && group == status.qpsGroup && key == status.qpsKey
+}
+
+func errBatchSizeTooLarge(maxSize int) error {
+	return &rpc.JsonError{
+		Code:    ratelimitErrorCode,
+		Message: fmt.Sprintf("batch size is too large with more than %d requests", maxSize),
+	}
+}
@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchCallTimeout(t *testing.T) {
+	status := &batchStatus{deadline: time.Now().Add(50 * time.Millisecond)}
+	ctx := context.WithValue(context.Background(), ctxKeyBatchStatus, status)
+
+	var executed int
+	handle := BatchCall(func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+		executed++
+
+		// call message is bounded by the whole batch deadline
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, status.deadline, deadline)
+
+		<-ctx.Done()
+		return &rpc.JsonRpcMessage{ID: msg.ID, Result: json.RawMessage(`"0x1"`)}
+	})
+
+	// call messages within a batch are executed one by one
+	for i := 0; i < 3; i++ {
+		resp := handle(ctx, &rpc.JsonRpcMessage{ID: json.RawMessage(`1`), Method: "eth_blockNumber"})
+
+		if i == 0 {
+			assert.Nil(t, resp.Error)
+		} else { // remaining requests rejected once timed out
+			assert.NotNil(t, resp.Error)
+		}
+	}
+
+	assert.Equal(t, 1, executed)
+}
+
+func TestBatchCallRejected(t *testing.T) {
+	status := &batchStatus{rejectErr: errBatchSizeTooLarge(2)}
+	ctx := context.WithValue(context.Background(), ctxKeyBatchStatus, status)
+
+	handle := BatchCall(func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+		t.Fatal("call message should not be executed")
+		return nil
+	})
+
+	resp := handle(ctx, &rpc.JsonRpcMessage{ID: json.RawMessage(`1`), Method: "eth_blockNumber"})
+	assert.NotNil(t, resp.Error)
+	assert.Equal(t, ratelimitErrorCode, resp.Error.Code)
+}
@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/Conflux-Chain/confura/util/metrics"
+	"github.com/Conflux-Chain/confura/util/rpc/cache"
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/openweb3/go-rpc-provider"
+)
+
+// ResponseCache serves the RPC calls whose results are immutable from the response cache if any.
+func ResponseCache(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
+	return func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+		respCache, ok := ctx.Value(handlers.CtxKeyResponseCache).(*cache.ResponseCache)
+		if !ok {
+			return next(ctx, msg)
+		}
+
+		key, ok := respCache.Key(msg.Method, msg.Params)
+		if !ok {
+			return next(ctx, msg)
+		}
+
+		result, hit := respCache.Get(key)
+		metrics.Registry.RPC.Percentage(msg.Method, "cache/response/hit").Mark(hit)
+
+		if hit {
+			return &rpc.JsonRpcMessage{Version: msg.Version, ID: msg.ID, Result: result}
+		}
+
+		resp := next(ctx, msg)
+		if resp != nil && resp.Error == nil {
+			respCache.Add(key, msg.Method, resp.Result)
+		}
+
+		return resp
+	}
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/Conflux-Chain/confura/store"
+	"github.com/Conflux-Chain/confura/util/rate"
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/openweb3/go-rpc-provider"
+)
+
+// StrategyCaps injects the bounds for `getLogs` into context by the resource caps of the rate limit
+// strategy. Note, the max batch size cap is applied by the `Batch` middleware.
+func StrategyCaps(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
+	return func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+		registry, ok := ctx.Value(handlers.CtxKeyRateRegistry).(*rate.Registry)
+		if !ok {
+			return next(ctx, msg)
+		}
+
+		if caps, ok := registry.GetStrategyCaps(ctx); ok {
+			ctx = store.NewContextWithLogLimits(ctx, store.LogLimits{
+				MaxLogs:          caps.MaxLogs,
+				MaxEpochRange:    caps.MaxLogsEpochRange,
+				MaxBlockRange:    caps.MaxLogsBlockRange,
+				MaxResponseBytes: caps.MaxLogsResponseBytes,
+			})
+		}
+
+		return next(ctx, msg)
+	}
+}
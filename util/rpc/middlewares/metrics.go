@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Conflux-Chain/confura/util/metrics"
+	"github.com/Conflux-Chain/confura/util/rate"
 	"github.com/Conflux-Chain/confura/util/rpc/handlers"
 	"github.com/openweb3/go-rpc-provider"
 )
@@ -42,6 +43,29 @@ func Metrics(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
 	}
 }
 
+// Usage collects per-key usages of RPC calls, which should be executed ahead of rate limit and
+// resource caps, so that rejected requests are accounted as well.
+func Usage(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
+	return func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+		registry, ok := ctx.Value(handlers.CtxKeyRateRegistry).(*rate.Registry)
+		if !ok {
+			return next(ctx, msg)
+		}
+
+		start := time.Now()
+		resp := next(ctx, msg)
+
+		method := msg.Method
+		if resp.Error != nil && isMethodNotFoundByError(msg.Method, resp.Error) {
+			method = "method_not_found"
+		}
+
+		registry.CollectUsage(ctx, method, resp.Error != nil, start)
+
+		return resp
+	}
+}
+
 func getTrafficSourceFromContext(ctx context.Context) (source string) {
 	if authId, ok := handlers.GetAuthIdFromContext(ctx); ok {
 		source = authId
@@ -2,10 +2,14 @@ package middlewares
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/Conflux-Chain/confura/store"
 	"github.com/Conflux-Chain/confura/util/rate"
 	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/openweb3/go-rpc-provider"
 	"github.com/pkg/errors"
 )
@@ -21,26 +25,65 @@ func QpsRateLimit(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
 			return next(ctx, msg)
 		}
 
-		// overall rate limit
-		if err := registry.Limit(ctx, "rpc_all_qps"); err != nil {
-			return msg.ErrorResponse(errQpsRateLimited(err))
+		// overall rate limit, unless already charged up front for the whole batch
+		if !isBatchQpsCharged(ctx, registry) {
+			if err := registry.Limit(ctx, "rpc_all_qps"); err != nil {
+				return msg.ErrorResponse(errQpsRateLimited(ctx, err))
+			}
 		}
 
 		// single method rate limit
 		resource := fmt.Sprintf("%v_qps", msg.Method)
 		if err := registry.Limit(ctx, resource); err != nil {
-			return msg.ErrorResponse(errQpsRateLimited(err))
+			return msg.ErrorResponse(errQpsRateLimited(ctx, err))
+		}
+
+		// overall compute units rate limit
+		if err := registry.LimitComputeUnits(ctx, "rpc_all_cups", parseCallShape(ctx, msg)); err != nil {
+			return msg.ErrorResponse(errQpsRateLimited(ctx, err))
 		}
 
 		return next(ctx, msg)
 	}
 }
 
-func errQpsRateLimited(err error) error {
-	return &rpc.JsonError{
+// rateLimitErrorData is the JSON-RPC error data with retry hints for rate limited requests.
+type rateLimitErrorData struct {
+	Limit      int   `json:"limit"`
+	Remaining  int   `json:"remaining"`
+	Reset      int64 `json:"reset"`      // unix timestamp in seconds
+	RetryAfter int64 `json:"retryAfter"` // in milliseconds
+}
+
+// newRateLimitJsonError creates JSON-RPC error with retry hints in the error data if available,
+// which are also exposed through HTTP response headers.
+func newRateLimitJsonError(ctx context.Context, err error) error {
+	jsonErr := &rpc.JsonError{
 		Code:    ratelimitErrorCode,
-		Message: errors.WithMessage(err, "request rate exceeded").Error(),
+		Message: err.Error(),
 	}
+
+	var limitErr *rate.LimitError
+	if !errors.As(err, &limitErr) {
+		return jsonErr
+	}
+
+	jsonErr.Data = rateLimitErrorData{
+		Limit:      limitErr.Limit,
+		Remaining:  limitErr.Remaining,
+		Reset:      limitErr.Reset.Unix(),
+		RetryAfter: limitErr.RetryAfter.Milliseconds(),
+	}
+
+	if status, ok := handlers.GetRateLimitStatusFromContext(ctx); ok {
+		status.Update(limitErr.Limit, limitErr.Remaining, limitErr.Reset, limitErr.RetryAfter)
+	}
+
+	return jsonErr
+}
+
+func errQpsRateLimited(ctx context.Context, err error) error {
+	return newRateLimitJsonError(ctx, errors.WithMessage(err, "request rate exceeded"))
 }
 
 func DailyMaxReqRateLimit(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
@@ -52,16 +95,116 @@ func DailyMaxReqRateLimit(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
 
 		// constrain daily total requests
 		if err := registry.Limit(ctx, "rpc_all_daily"); err != nil {
-			return msg.ErrorResponse(errDailyMaxReqRateLimited(err))
+			return msg.ErrorResponse(errDailyMaxReqRateLimited(ctx, err))
+		}
+
+		// constrain daily total compute units
+		if err := registry.LimitComputeUnits(ctx, "rpc_all_daily_cu", parseCallShape(ctx, msg)); err != nil {
+			return msg.ErrorResponse(errDailyMaxComputeUnitsLimited(ctx, err))
 		}
 
 		return next(ctx, msg)
 	}
 }
 
-func errDailyMaxReqRateLimited(err error) error {
-	return &rpc.JsonError{
-		Code:    ratelimitErrorCode,
-		Message: errors.WithMessage(err, "daily request count exceeded").Error(),
+func errDailyMaxReqRateLimited(ctx context.Context, err error) error {
+	return newRateLimitJsonError(ctx, errors.WithMessage(err, "daily request count exceeded"))
+}
+
+func errDailyMaxComputeUnitsLimited(ctx context.Context, err error) error {
+	return newRateLimitJsonError(ctx, errors.WithMessage(err, "daily compute units exceeded"))
+}
+
+// parseCallShape parses the RPC call parameters that affect compute unit cost. Note that if the log
+// filter range is not specified with explicit numbers, e.g., `latest` tag, the range width is billed
+// as the max range delegated to full node, which is bounded by the caps of rate limit strategy.
+func parseCallShape(ctx context.Context, msg *rpc.JsonRpcMessage) *rate.CallShape {
+	call := &rate.CallShape{Method: msg.Method}
+
+	switch msg.Method {
+	case "eth_getLogs", "eth_getLogsPaged", "cfx_getLogs", "cfx_getLogsPaged":
+		var params []struct {
+			FromBlock   json.RawMessage `json:"fromBlock"`
+			ToBlock     json.RawMessage `json:"toBlock"`
+			FromEpoch   json.RawMessage `json:"fromEpoch"`
+			ToEpoch     json.RawMessage `json:"toEpoch"`
+			BlockHash   json.RawMessage `json:"blockHash"`
+			BlockHashes []string        `json:"blockHashes"`
+		}
+
+		limits := store.GetLogLimits(ctx)
+		call.RangeWidth = limits.MaxBlockRange // bill the max range if unknown
+
+		if err := json.Unmarshal(msg.Params, &params); err != nil || len(params) == 0 {
+			return call
+		}
+
+		filter := params[0]
+
+		// filter by block hashes
+		if len(filter.BlockHash) > 0 && string(filter.BlockHash) != "null" {
+			call.RangeWidth = 1
+			return call
+		}
+
+		if len(filter.BlockHashes) > 0 {
+			call.RangeWidth = uint64(len(filter.BlockHashes))
+			return call
+		}
+
+		// filter by block range, which defaults to the `latest` block for evm space
+		from, to := filter.FromBlock, filter.ToBlock
+		if strings.HasPrefix(msg.Method, "eth_") && isNullRangeBound(from) && isNullRangeBound(to) {
+			call.RangeWidth = 1
+			return call
+		}
+
+		// filter by epoch range for core space
+		if isNullRangeBound(from) && isNullRangeBound(to) {
+			from, to = filter.FromEpoch, filter.ToEpoch
+			call.RangeWidth = limits.MaxEpochRange
+		}
+
+		// same block tags, e.g., from `latest` to `latest`
+		if !isNullRangeBound(from) && string(from) == string(to) {
+			call.RangeWidth = 1
+			return call
+		}
+
+		fromNum, ok1 := parseRangeBound(from)
+		toNum, ok2 := parseRangeBound(to)
+		if ok1 && ok2 && toNum >= fromNum {
+			call.RangeWidth = toNum - fromNum + 1
+		}
+	case "eth_getBlockByNumber", "eth_getBlockByHash",
+		"cfx_getBlockByHash", "cfx_getBlockByEpochNumber", "cfx_getBlockByBlockNumber":
+		var params []json.RawMessage
+		if err := json.Unmarshal(msg.Params, &params); err != nil || len(params) < 2 {
+			return call
+		}
+
+		json.Unmarshal(params[1], &call.FullTx)
+	}
+
+	return call
+}
+
+func isNullRangeBound(bound json.RawMessage) bool {
+	return len(bound) == 0 || string(bound) == "null"
+}
+
+// parseRangeBound parses the log filter range bound as number, which fails for block tags except
+// the `earliest` one.
+func parseRangeBound(bound json.RawMessage) (uint64, bool) {
+	var num hexutil.Uint64
+	if err := json.Unmarshal(bound, &num); err == nil {
+		return uint64(num), true
 	}
+
+	var tag string
+	if err := json.Unmarshal(bound, &tag); err == nil && tag == "earliest" {
+		return 0, true
+	}
+
+	return 0, false
 }
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Conflux-Chain/confura/store"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCallShapeRangeWidth(t *testing.T) {
+	ctx := store.NewContextWithLogLimits(context.Background(), store.LogLimits{
+		MaxEpochRange: 500,
+		MaxBlockRange: 1000,
+	})
+
+	testCases := []struct {
+		method string
+		params string
+		width  uint64
+	}{
+		{"eth_getLogs", `[{"fromBlock":"0x10","toBlock":"0x1f"}]`, 16},
+		{"eth_getLogs", `[{"fromBlock":"earliest","toBlock":"0x1f"}]`, 32},
+		{"eth_getLogs", `[{"fromBlock":"earliest","toBlock":"latest"}]`, 1000},
+		{"eth_getLogs", `[{"fromBlock":"0x10","toBlock":"finalized"}]`, 1000},
+		{"eth_getLogs", `[{"fromBlock":"latest","toBlock":"latest"}]`, 1},
+		{"eth_getLogs", `[{}]`, 1},
+		{"eth_getLogs", `[{"blockHash":"0x7a8f0e3b3d1f1c7a5ef4d4e3c0e0f31f6c8a5a2e8a1d5c2c7b3c5c9f0e2b1a3d"}]`, 1},
+		{"eth_getLogs", `invalid`, 1000},
+		{"cfx_getLogs", `[{"fromEpoch":"0x1","toEpoch":"0x2"}]`, 2},
+		{"cfx_getLogs", `[{"fromEpoch":"earliest","toEpoch":"latest_state"}]`, 500},
+		{"cfx_getLogs", `[{}]`, 500},
+		{"cfx_getLogs", `[{"fromBlock":"0x1","toBlock":"latest_state"}]`, 1000},
+		{"cfx_getLogs", `[{"blockHashes":["0x01","0x02"]}]`, 2},
+	}
+
+	for _, tc := range testCases {
+		msg := &rpc.JsonRpcMessage{Method: tc.method, Params: json.RawMessage(tc.params)}
+		call := parseCallShape(ctx, msg)
+		assert.Equal(t, tc.width, call.RangeWidth, "%v %v", tc.method, tc.params)
+	}
+}
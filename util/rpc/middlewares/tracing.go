@@ -0,0 +1,101 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/Conflux-Chain/confura/util/rpc/handlers"
+	"github.com/Conflux-Chain/confura/util/tracing"
+	"github.com/openweb3/go-rpc-provider"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// context key of the span for the traced middleware
+	ctxKeyMiddlewareSpan = handlers.CtxKey("Infura-Middleware-Span")
+)
+
+type middlewareSpan struct {
+	span   trace.Span
+	parent context.Context // context with parent span, which is restored when middleware passes on
+}
+
+// TracingBatch starts a server span for each batch RPC request.
+func TracingBatch(next rpc.HandleBatchFunc) rpc.HandleBatchFunc {
+	return func(ctx context.Context, msgs []*rpc.JsonRpcMessage) []*rpc.JsonRpcMessage {
+		ctx, span := tracing.Start(ctx, "rpc.batch",
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.Int("rpc.batch.size", len(msgs))),
+		)
+		defer span.End()
+
+		return next(ctx, msgs)
+	}
+}
+
+// Tracing starts a server span for each RPC call, which is continued from the trace context of the
+// incoming `traceparent` header if any.
+func Tracing(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
+	return func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+		ctx, span := tracing.Start(ctx, msg.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("rpc.system", "jsonrpc"),
+				attribute.String("rpc.method", msg.Method),
+			),
+		)
+		defer span.End()
+
+		resp := next(ctx, msg)
+		setSpanStatus(span, resp)
+
+		return resp
+	}
+}
+
+// Traced wraps the middleware to trace the elapsed time within the middleware, e.g., rate limit,
+// until it passes on to the next handler or returns early. It returns the middleware as it is
+// if tracing disabled.
+func Traced(name string, middleware rpc.HandleCallMsgMiddleware) rpc.HandleCallMsgMiddleware {
+	if !tracing.Enabled() {
+		return middleware
+	}
+
+	return func(next rpc.HandleCallMsgFunc) rpc.HandleCallMsgFunc {
+		passOn := func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+			if ms, ok := ctx.Value(ctxKeyMiddlewareSpan).(*middlewareSpan); ok {
+				ms.span.End()
+
+				// restore the parent span, so that the next handlers are not traced as children
+				ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(ms.parent))
+			}
+
+			return next(ctx, msg)
+		}
+
+		handle := middleware(passOn)
+
+		return func(ctx context.Context, msg *rpc.JsonRpcMessage) *rpc.JsonRpcMessage {
+			spanCtx, span := tracing.Start(ctx, "middleware."+name)
+			spanCtx = context.WithValue(spanCtx, ctxKeyMiddlewareSpan, &middlewareSpan{span: span, parent: ctx})
+
+			resp := handle(spanCtx, msg)
+
+			// returned early without passing on, e.g., rejected by rate limit
+			if span.IsRecording() {
+				setSpanStatus(span, resp)
+				span.End()
+			}
+
+			return resp
+		}
+	}
+}
+
+func setSpanStatus(span trace.Span, resp *rpc.JsonRpcMessage) {
+	if resp != nil && resp.Error != nil {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", resp.Error.Code))
+		span.SetStatus(codes.Error, resp.Error.Error())
+	}
+}
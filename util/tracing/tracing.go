@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentation name of tracer
+	tracerName = "github.com/Conflux-Chain/confura"
+
+	// timeout to flush the pending spans on shutdown
+	shutdownTimeout = 5 * time.Second
+)
+
+var (
+	enabled  bool
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer = otel.Tracer(tracerName)
+)
+
+// config is the configuration to trace RPC requests with OpenTelemetry.
+type config struct {
+	// Whether to enable tracing
+	Enabled bool
+	// Service name reported to trace collector
+	ServiceName string `default:"confura"`
+	// OTLP/HTTP endpoint of the trace collector
+	Endpoint string `default:"127.0.0.1:4318"`
+	// Whether to export spans without TLS
+	Insecure bool `default:"true"`
+	// Ratio of root spans to sample, while spans with sampled parent are always sampled
+	SampleRatio float64 `default:"1"`
+}
+
+// MustInit inits the global tracer provider to export spans via OTLP, and the global propagator
+// to propagate trace context in W3C `traceparent` headers.
+//
+// Note, this should be called after viper initialized.
+func MustInit() {
+	var conf config
+	viper.MustUnmarshalKey("tracing", &conf)
+
+	if !conf.Enabled {
+		return
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(conf.Endpoint)}
+	if conf.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create OTLP trace exporter")
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(conf.ServiceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	tracer = provider.Tracer(tracerName)
+	enabled = true
+
+	logrus.WithField("config", fmt.Sprintf("%+v", conf)).Info("Tracing initialized")
+}
+
+// Shutdown flushes the pending spans and stops exporting, which is a noop if tracing disabled.
+func Shutdown() {
+	if provider == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := provider.Shutdown(ctx); err != nil {
+		logrus.WithError(err).Info("Failed to shutdown tracer provider")
+	}
+}
+
+// Enabled checks whether tracing is enabled.
+func Enabled() bool {
+	return enabled
+}
+
+// Start starts a span as child of the span within context if any.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, opts...)
+}
+
+// End ends the span, and marks the span as failed if error happened.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// Detach returns a new background context that only carries the span of the specified context,
+// which is used to propagate trace context without the cancellation and values of context.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+// Extract extracts the trace context from carrier, e.g., the incoming `traceparent` headers.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// Inject injects the trace context into carrier, e.g., the outgoing `traceparent` headers.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// SetAttributes sets attributes to the span within context if recording.
+func SetAttributes(ctx context.Context, kv ...attribute.KeyValue) {
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(kv...)
+	}
+}
@@ -1,11 +1,14 @@
 package virtualfilter
 
 import (
+	"sync"
+
 	"github.com/Conflux-Chain/confura/util"
 	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
 	sdk "github.com/Conflux-Chain/go-conflux-sdk"
 	"github.com/Conflux-Chain/go-conflux-sdk/types"
 	w3rpc "github.com/openweb3/go-rpc-provider"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,6 +17,7 @@ import (
 type cfxFilterApi struct {
 	fs        *cfxFilterSystem   // filter system
 	fnClients util.ConcurrentMap // full node clients: node name => sdk client
+	restoreMu sync.Mutex         // guards against restoring the same filter concurrently
 }
 
 func newCfxFilterApi(sys *cfxFilterSystem) *cfxFilterApi {
@@ -39,6 +43,7 @@ func (api *cfxFilterApi) NewPendingTransactionFilter(nodeUrl string) (w3rpc.ID,
 }
 
 func (api *cfxFilterApi) UninstallFilter(id w3rpc.ID) (bool, error) {
+	api.restoreFilter(id)
 	return api.fs.uninstallFilter(id)
 }
 
@@ -52,6 +57,8 @@ func (api *cfxFilterApi) NewFilter(nodeUrl string, crit types.LogFilter) (w3rpc.
 }
 
 func (api *cfxFilterApi) GetLogFilter(fid w3rpc.ID) (*types.LogFilter, error) {
+	api.restoreFilter(fid)
+
 	vf, ok := api.fs.getFilter(fid)
 	if !ok || vf.ftype() != filterTypeLog {
 		return nil, errFilterNotFound
@@ -62,9 +69,55 @@ func (api *cfxFilterApi) GetLogFilter(fid w3rpc.ID) (*types.LogFilter, error) {
 }
 
 func (api *cfxFilterApi) GetFilterChanges(id w3rpc.ID) (*types.CfxFilterChanges, error) {
+	api.restoreFilter(id)
 	return api.fs.getFilterChanges(id)
 }
 
+// restoreFilter restores the virtual filter from the persisted state if not found, e.g., which is
+// created by another replica before failover.
+func (api *cfxFilterApi) restoreFilter(id w3rpc.ID) {
+	if _, ok := api.fs.getFilter(id); ok {
+		return
+	}
+
+	api.restoreMu.Lock()
+	defer api.restoreMu.Unlock()
+
+	// double check in case restored by another concurrent call
+	if _, ok := api.fs.getFilter(id); ok {
+		return
+	}
+
+	state, ok := api.fs.loadFilterState(id)
+	if !ok {
+		return
+	}
+
+	client, err := api.loadOrGetFnClient(state.NodeUrl)
+	if err != nil {
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"fid":       id,
+		"fnNodeUrl": state.NodeUrl,
+	})
+
+	if err := api.fs.restoreFilter(client, state); err != nil {
+		if errors.Is(err, errFilterNotResumable) {
+			// expire the filter so that the client could re-create it
+			logger.WithError(err).Info("Virtual filter expired due to missing filter changes")
+			api.fs.delFilterState(id)
+			return
+		}
+
+		logger.WithError(err).Error("Failed to restore virtual filter from persisted state")
+		return
+	}
+
+	logger.Info("Virtual filter restored from persisted state")
+}
+
 func (api *cfxFilterApi) loadOrGetFnClient(nodeUrl string) (*sdk.Client, error) {
 	nodeName := rpcutil.Url2NodeName(nodeUrl)
 	client, _, err := api.fnClients.LoadOrStoreFnErr(nodeName, func(interface{}) (interface{}, error) {
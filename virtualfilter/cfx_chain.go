@@ -2,6 +2,7 @@ package virtualfilter
 
 import (
 	"container/list"
+	"encoding/json"
 	"fmt"
 
 	"github.com/Conflux-Chain/go-conflux-sdk/types"
@@ -154,6 +155,63 @@ func (fc *cfxFilterChain) onFilterEpochCacheEvict(key, value interface{}) {
 	}
 }
 
+// snapshot returns event logs of at most the latest n epochs on the canonical chain, excluding
+// those epochs no later than the latest one whose event logs have been evicted.
+func (fc *cfxFilterChain) snapshot(n int) filterChanges {
+	logs := []*types.SubscriptionLog{}
+
+	for _, node := range fc.tail(n) {
+		epoch := node.filterNodable.(*cfxFilterEpoch)
+		if len(epoch.logs) == 0 { // event logs evicted
+			logs = logs[:0]
+			continue
+		}
+
+		for i := range epoch.logs {
+			logs = append(logs, &types.SubscriptionLog{Log: &epoch.logs[i]})
+		}
+	}
+
+	return &types.CfxFilterChanges{Type: "log", Logs: logs}
+}
+
+// restore merges the snapshot filter changes up to the start epoch into the filter chain, and
+// backfills the epochs between the snapshot tail and the start epoch if any.
+func (fc *cfxFilterChain) restore(data []byte, startHeight uint64, backfill backfillFunc) (filterChanges, error) {
+	var fchanges types.CfxFilterChanges
+	if err := json.Unmarshal(data, &fchanges); err != nil {
+		return nil, errors.WithMessage(err, "invalid filter changes json")
+	}
+
+	// changes after the start epoch will be polled from the proxy filter
+	var logs []*types.SubscriptionLog
+	for _, log := range fchanges.Logs {
+		if log.Log != nil && log.Log.EpochNumber.ToInt().Uint64() <= startHeight {
+			logs = append(logs, log)
+		}
+	}
+
+	if len(logs) == 0 {
+		return nil, errEmptyChainSnapshot
+	}
+
+	if tail := logs[len(logs)-1].Log.EpochNumber.ToInt().Uint64(); tail < startHeight {
+		bchanges, err := backfill(tail+1, startHeight)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to backfill filter chain")
+		}
+
+		logs = append(logs, bchanges.(*types.CfxFilterChanges).Logs...)
+	}
+
+	restored := &types.CfxFilterChanges{Type: "log", Logs: logs}
+	if err := fc.merge(restored); err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
 // traverses the filter chain from the starting cursor, and prints the node info
 // (eg., epoch number, block hash etc.), mainly used for debugging.
 func (fc *cfxFilterChain) print(cursor filterCursor) {
@@ -1,10 +1,13 @@
 package virtualfilter
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
 	"github.com/Conflux-Chain/go-conflux-sdk/types"
+	"github.com/Conflux-Chain/go-conflux-sdk/types/cfxaddress"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -278,3 +281,48 @@ func _newCfxHash(h string) *types.Hash {
 	v := types.Hash(h)
 	return &v
 }
+
+func TestCfxFilterChainSnapshot(t *testing.T) {
+	fchain := newCfxFilterChain(10)
+
+	// event logs must be valid for JSON marshaling
+	newLog := func(epoch int64, hash string) *types.SubscriptionLog {
+		return &types.SubscriptionLog{Log: &types.Log{
+			Address:     cfxaddress.MustNewFromHex("0x1000000000000000000000000000000000000000", 1),
+			EpochNumber: _newHexBigFromInt(epoch),
+			BlockHash:   _newCfxHash(common.HexToHash(hash).Hex()),
+		}}
+	}
+
+	demologs := []*types.SubscriptionLog{
+		newLog(1, "0x21"),
+		newLog(2, "0x22"),
+		{ChainReorg: &types.ChainReorg{RevertTo: _newHexBigFromInt(1)}},
+		newLog(2, "0x32"),
+		newLog(3, "0x33"),
+	}
+
+	err := fchain.merge(&types.CfxFilterChanges{Logs: demologs})
+	assert.NoError(t, err, "failed to merge filter chain")
+
+	// only epochs on the canonical chain are snapshot
+	snapshot := fchain.snapshot(2)
+	assert.Equal(t, []*types.SubscriptionLog{demologs[3], demologs[4]}, snapshot.(*types.CfxFilterChanges).Logs)
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	rchain := newCfxFilterChain(10)
+	_, err = rchain.restore(data, 3, func(fromHeight, toHeight uint64) (filterChanges, error) {
+		t.Fatalf("unexpected backfill from %v to %v", fromHeight, toHeight)
+		return nil, nil
+	})
+	assert.NoError(t, err, "failed to restore filter chain")
+
+	assert.Equal(t, 2, rchain.len)
+	assert.Equal(t, fchain.snapshotLatestCursor(), rchain.snapshotLatestCursor())
+
+	// cursor of the reorged epoch falls back to the nearest canonical one before it
+	reorgedCursor := filterCursor{height: 3, hash: common.HexToHash("0x23").Hex()}
+	assert.Equal(t, rchain.front().cursor(), rchain.locate(reorgedCursor))
+}
@@ -48,10 +48,23 @@ func (f *cfxFilter) uninstall() (bool, error) {
 	return f.client.Filter().UninstallFilter(f.id)
 }
 
+func (f *cfxFilter) release() {
+	metricVirtualFilterSession("cfx", f, -1)
+}
+
 func (f *cfxFilter) nodeName() string {
 	return rpcutil.Url2NodeName(f.client.GetNodeURL())
 }
 
+func (f *cfxFilter) state() *filterState {
+	return &filterState{
+		ID:              f.id,
+		Type:            f.typ,
+		NodeUrl:         f.client.GetNodeURL(),
+		LastPollingTime: f.lastPollingTime,
+	}
+}
+
 func newCfxBlockFilter(client *sdk.Client) (*cfxFilter, error) {
 	fid, err := client.Filter().NewBlockFilter()
 	if err != nil {
@@ -103,11 +116,52 @@ func newCfxLogFilter(
 	return lf, nil
 }
 
+// restoreCfxLogFilter restores log filter from the persisted state, which resumes polling from
+// the persisted cursor.
+func restoreCfxLogFilter(
+	vfls *mysql.VirtualFilterLogStore,
+	worker *cfxFilterWorker,
+	client *sdk.Client,
+	state *filterState,
+) (*cfxLogFilter, error) {
+	lf := &cfxLogFilter{
+		logStore:  vfls,
+		worker:    worker,
+		cfxFilter: newCfxFilter(state.ID, filterTypeLog, client),
+	}
+
+	if err := json.Unmarshal(state.Crit, &lf.crit); err != nil {
+		return nil, errors.WithMessage(err, "invalid filter criteria json")
+	}
+
+	if err := worker.resume(lf, state.cursor()); err != nil {
+		return nil, err
+	}
+
+	metricVirtualFilterSession("cfx", lf, 1)
+	return lf, nil
+}
+
 func (f *cfxLogFilter) uninstall() (bool, error) {
 	metricVirtualFilterSession("cfx", f, -1)
 	return f.worker.reject(f)
 }
 
+func (f *cfxLogFilter) release() {
+	f.uninstall()
+}
+
+func (f *cfxLogFilter) state() *filterState {
+	state := f.cfxFilter.state()
+	state.Crit, _ = json.Marshal(f.crit)
+
+	if cursor, ok := f.worker.cursor(f.id); ok {
+		state.setCursor(cursor)
+	}
+
+	return state
+}
+
 func (f *cfxLogFilter) fetch() (filterChanges, error) {
 	// get change epochs from filter worker since last polling
 	pchanges, err := f.worker.fetchPollingChanges(f.id)
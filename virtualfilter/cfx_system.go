@@ -25,11 +25,12 @@ type cfxFilterSystem struct {
 func newCfxFilterSystem(
 	conf *cfxConfig,
 	vfls *mysql.VirtualFilterLogStore,
+	vfss filterStateStore,
 	shutdownCtx cmdutil.GracefulShutdownContext,
 ) *cfxFilterSystem {
 	return &cfxFilterSystem{
 		conf:             conf,
-		filterSystemBase: newFilterSystemBase(conf.TTL, vfls, shutdownCtx),
+		filterSystemBase: newFilterSystemBase(conf.TTL, vfls, vfss, shutdownCtx),
 	}
 }
 
@@ -39,7 +40,7 @@ func (fs *cfxFilterSystem) newBlockFilter(client *sdk.Client) (rpc.ID, error) {
 		return nilRpcId, err
 	}
 
-	fs.filterMgr.add(f)
+	fs.addFilter(f)
 	return f.fid(), nil
 }
 
@@ -49,27 +50,52 @@ func (fs *cfxFilterSystem) newPendingTransactionFilter(client *sdk.Client) (rpc.
 		return nilRpcId, err
 	}
 
-	fs.filterMgr.add(f)
+	fs.addFilter(f)
 	return f.fid(), nil
 }
 
 func (fs *cfxFilterSystem) newFilter(client *sdk.Client, crit types.LogFilter) (rpc.ID, error) {
-	nodeName := rpcutil.Url2NodeName(client.GetNodeURL())
-	worker, _ := fs.workers.LoadOrStoreFn(nodeName, func(k interface{}) interface{} {
-		return newCfxFilterWorker(
-			fs.conf.MaxFullFilterEpochs, fs, client, fs.shutdownCtx,
-		)
-	})
-
-	f, err := newCfxLogFilter(fs.logStore, worker.(*cfxFilterWorker), client, crit)
+	f, err := newCfxLogFilter(fs.logStore, fs.loadOrNewWorker(client), client, crit)
 	if err != nil {
 		return nilRpcId, err
 	}
 
-	fs.filterMgr.add(f)
+	fs.addFilter(f)
 	return f.fid(), nil
 }
 
+// restoreFilter restores the virtual filter from the persisted state, e.g., after failover.
+func (fs *cfxFilterSystem) restoreFilter(client *sdk.Client, state *filterState) error {
+	var f virtualFilter
+
+	switch state.Type {
+	case filterTypeBlock, filterTypePendingTxn:
+		f = newCfxFilter(state.ID, state.Type, client)
+		metricVirtualFilterSession("cfx", f, 1)
+	case filterTypeLog:
+		lf, err := restoreCfxLogFilter(fs.logStore, fs.loadOrNewWorker(client), client, state)
+		if err != nil {
+			return err
+		}
+
+		f = lf
+	default:
+		return errors.Errorf("unknown filter type %v", state.Type)
+	}
+
+	fs.addFilter(f)
+	return nil
+}
+
+func (fs *cfxFilterSystem) loadOrNewWorker(client *sdk.Client) *cfxFilterWorker {
+	nodeName := rpcutil.Url2NodeName(client.GetNodeURL())
+	worker, _ := fs.workers.LoadOrStoreFn(nodeName, func(k interface{}) interface{} {
+		return newCfxFilterWorker(fs.conf, fs, fs.stateStore, client, fs.shutdownCtx)
+	})
+
+	return worker.(*cfxFilterWorker)
+}
+
 func (fs *cfxFilterSystem) getFilterChanges(id rpc.ID) (*types.CfxFilterChanges, error) {
 	vf, ok := fs.filterMgr.get(id)
 	if !ok {
@@ -82,11 +108,14 @@ func (fs *cfxFilterSystem) getFilterChanges(id rpc.ID) (*types.CfxFilterChanges,
 	}
 
 	fs.filterMgr.refresh(id)
+	fs.saveFilterState(vf)
+
 	return fc.(*types.CfxFilterChanges), nil
 }
 
 func (fs *cfxFilterSystem) uninstallFilter(id rpc.ID) (bool, error) {
 	if vf, ok := fs.filterMgr.delete(id); ok {
+		fs.delFilterState(id)
 		return vf.uninstall()
 	}
 
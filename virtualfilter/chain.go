@@ -9,6 +9,7 @@ var (
 
 	errRewindNodeNotFound = errors.New("rewind node not found")
 	errBadFilterCursor    = errors.New("bad filter cursor")
+	errEmptyChainSnapshot = errors.New("empty filter chain snapshot")
 )
 
 type filterNodable interface {
@@ -23,6 +24,9 @@ type filterCursor struct {
 
 type filterChainIterator func(node *filterNode, forkPoint bool) bool
 
+// backfillFunc fetches the filter changes of blocks (or epochs) within the height range
+type backfillFunc func(fromHeight, toHeight uint64) (filterChanges, error)
+
 type filterChain interface {
 	// snapshots filter cursor of the latest node
 	snapshotLatestCursor() filterCursor
@@ -34,6 +38,15 @@ type filterChain interface {
 	traverse(cursor filterCursor, iterator filterChainIterator) error
 	// print all nodes along the chain from specific cursor
 	print(cursor filterCursor)
+	// locate the cursor on the filter chain, or the nearest one before it if not found
+	locate(cursor filterCursor) filterCursor
+	// snapshot filter changes of at most the latest n nodes on the canonical chain
+	snapshot(n int) filterChanges
+	// restore filter chain from the snapshot filter changes data up to the start height, with
+	// the gap after the snapshot backfilled, and returns the restored changes
+	restore(data []byte, startHeight uint64, backfill backfillFunc) (filterChanges, error)
+	// the first node of the filter chain or nil if empty
+	front() *filterNode
 }
 
 type filterChainBase struct {
@@ -128,6 +141,33 @@ func (c *filterChainBase) traverse(cursor filterCursor, iterator filterChainIter
 	return nil
 }
 
+// locate returns the cursor if it's on the filter chain, otherwise the cursor of the last canonical
+// node below the cursor height, e.g., for cursor restored from the persisted state after failover.
+func (fc *filterChainBase) locate(cursor filterCursor) filterCursor {
+	if _, ok := fc.hashToNodes[cursor.hash]; ok || cursor == nilFilterCursor {
+		return cursor
+	}
+
+	located := nilFilterCursor
+	for node := fc.front(); node != nil && node.cursor().height < cursor.height; node = node.getNext() {
+		located = node.cursor()
+	}
+
+	return located
+}
+
+// tail returns at most n nodes at the back of the canonical chain in order.
+func (fc *filterChainBase) tail(n int) []*filterNode {
+	n = min(n, fc.len)
+	nodes := make([]*filterNode, n)
+
+	for i, node := n-1, fc.root.prev; i >= 0; i, node = i-1, node.prev {
+		nodes[i] = node
+	}
+
+	return nodes
+}
+
 // front returns the first node of the filter chain or nil if the chain is empty.
 func (fc *filterChainBase) front() *filterNode {
 	if fc.len == 0 {
@@ -3,6 +3,7 @@ package virtualfilter
 import (
 	"time"
 
+	"github.com/Conflux-Chain/confura/store/redis"
 	"github.com/Conflux-Chain/go-conflux-util/viper"
 )
 
@@ -13,6 +14,11 @@ type ethConfig struct {
 
 	// max number of filter blocks full of event logs to restrict memory usage (default: 100)
 	MaxFullFilterBlocks int `default:"100"`
+
+	// persistence of filter states for failover
+	Persistence persistenceConfig
+	// block filters served from the blocks synced by syncer
+	SyncBlockFilter syncBlockFilterConfig
 }
 
 func mustNewEthConfigFromViper() *ethConfig {
@@ -29,6 +35,9 @@ type cfxConfig struct {
 
 	// max number of filter epochs full of event logs to restrict memory usage (default: 100)
 	MaxFullFilterEpochs int `default:"100"`
+
+	// persistence of filter states for failover
+	Persistence persistenceConfig
 }
 
 func mustNewCfxConfigFromViper() *cfxConfig {
@@ -37,3 +46,30 @@ func mustNewCfxConfigFromViper() *cfxConfig {
 
 	return &conf
 }
+
+// syncBlockFilterConfig represents the configuration of block filters served from the blocks synced
+// by syncer rather than delegated to full node.
+type syncBlockFilterConfig struct {
+	Enabled bool
+	// max number of the latest synced blocks for block filters to traverse (default: 1000)
+	Window int `default:"1000"`
+}
+
+// persistenceConfig represents the configuration to persist virtual filter states, so that filters
+// survive restarts and could be taken over by another replica.
+type persistenceConfig struct {
+	// Redis URL to persist filter states, which is disabled if empty
+	RedisUrl string
+	// max number of the latest filter blocks (or epochs) of filter chain to persist (default: 100)
+	ChainWindow int `default:"100"`
+}
+
+// mustNewFilterStateStore creates filter state store, or nil if persistence disabled.
+func (conf *persistenceConfig) mustNewFilterStateStore(space string, ttl time.Duration) filterStateStore {
+	if len(conf.RedisUrl) == 0 {
+		return nil
+	}
+
+	client := redis.MustNewRedisClient(conf.RedisUrl)
+	return newRedisFilterStateStore(client, space, ttl)
+}
@@ -1,11 +1,14 @@
 package virtualfilter
 
 import (
+	"sync"
+
 	"github.com/Conflux-Chain/confura/node"
 	"github.com/Conflux-Chain/confura/util"
 	rpcutil "github.com/Conflux-Chain/confura/util/rpc"
 	w3rpc "github.com/openweb3/go-rpc-provider"
 	"github.com/openweb3/web3go/types"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -18,6 +21,7 @@ var (
 type ethFilterApi struct {
 	fs        *ethFilterSystem   // filter system
 	fnClients util.ConcurrentMap // full node clients: node name => sdk client
+	restoreMu sync.Mutex         // guards against restoring the same filter concurrently
 }
 
 func newEthFilterApi(sys *ethFilterSystem) *ethFilterApi {
@@ -25,6 +29,10 @@ func newEthFilterApi(sys *ethFilterSystem) *ethFilterApi {
 }
 
 func (api *ethFilterApi) NewBlockFilter(nodeUrl string) (w3rpc.ID, error) {
+	if api.fs.blockFeed != nil { // served from synced blocks
+		return api.fs.newSyncBlockFilter()
+	}
+
 	client, err := api.loadOrGetFnClient(nodeUrl)
 	if err != nil {
 		return nilRpcId, err
@@ -43,6 +51,7 @@ func (api *ethFilterApi) NewPendingTransactionFilter(nodeUrl string) (w3rpc.ID,
 }
 
 func (api *ethFilterApi) UninstallFilter(id w3rpc.ID) (bool, error) {
+	api.restoreFilter(id)
 	return api.fs.uninstallFilter(id)
 }
 
@@ -56,6 +65,8 @@ func (api *ethFilterApi) NewFilter(nodeUrl string, crit types.FilterQuery) (w3rp
 }
 
 func (api *ethFilterApi) GetLogFilter(fid w3rpc.ID) (*types.FilterQuery, error) {
+	api.restoreFilter(fid)
+
 	vf, ok := api.fs.getFilter(fid)
 	if !ok || vf.ftype() != filterTypeLog {
 		return nil, errFilterNotFound
@@ -66,9 +77,60 @@ func (api *ethFilterApi) GetLogFilter(fid w3rpc.ID) (*types.FilterQuery, error)
 }
 
 func (api *ethFilterApi) GetFilterChanges(id w3rpc.ID) (*types.FilterChanges, error) {
+	api.restoreFilter(id)
 	return api.fs.getFilterChanges(id)
 }
 
+// restoreFilter restores the virtual filter from the persisted state if not found, e.g., which is
+// created by another replica before failover.
+func (api *ethFilterApi) restoreFilter(id w3rpc.ID) {
+	if _, ok := api.fs.getFilter(id); ok {
+		return
+	}
+
+	api.restoreMu.Lock()
+	defer api.restoreMu.Unlock()
+
+	// double check in case restored by another concurrent call
+	if _, ok := api.fs.getFilter(id); ok {
+		return
+	}
+
+	state, ok := api.fs.loadFilterState(id)
+	if !ok {
+		return
+	}
+
+	var client *node.Web3goClient
+	if len(state.NodeUrl) > 0 { // delegated to full node
+		c, err := api.loadOrGetFnClient(state.NodeUrl)
+		if err != nil {
+			return
+		}
+
+		client = c
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"fid":       id,
+		"fnNodeUrl": state.NodeUrl,
+	})
+
+	if err := api.fs.restoreFilter(client, state); err != nil {
+		if errors.Is(err, errFilterNotResumable) {
+			// expire the filter so that the client could re-create it
+			logger.WithError(err).Info("Virtual filter expired due to missing filter changes")
+			api.fs.delFilterState(id)
+			return
+		}
+
+		logger.WithError(err).Error("Failed to restore virtual filter from persisted state")
+		return
+	}
+
+	logger.Info("Virtual filter restored from persisted state")
+}
+
 func (api *ethFilterApi) loadOrGetFnClient(nodeUrl string) (*node.Web3goClient, error) {
 	nodeName := rpcutil.Url2NodeName(nodeUrl)
 	client, _, err := api.fnClients.LoadOrStoreFnErr(nodeName, func(interface{}) (interface{}, error) {
@@ -0,0 +1,215 @@
+package virtualfilter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// max number of synced blocks to load once
+	maxBlockFeedLoadSize = 1000
+	// number of the latest blocks to check for reorg when loading synced blocks
+	blockFeedReorgCheckDepth = 100
+
+	// pseudo node name of block filters served from the synced blocks
+	syncBlockFeedNodeName = "syncer"
+)
+
+// syncedBlockStore provides blocks synced by syncer, e.g., the evm space db store.
+type syncedBlockStore interface {
+	// returns the max synced block number, or false if no block synced yet
+	MaxEpoch() (uint64, bool, error)
+	// returns the synced block hashes within the range, keyed by block number
+	PivotHashes(from, to uint64) (map[uint64]string, error)
+}
+
+// ethBlockFeed feeds block filters with blocks synced by syncer, which keeps a window of the
+// latest canonical blocks in ascending order, and is also rewound if chain reorg happens.
+type ethBlockFeed struct {
+	mu       sync.Mutex
+	store    syncedBlockStore
+	capacity int               // max number of blocks within window
+	window   []filterCursor    // the latest canonical blocks
+	reorged  map[string]uint64 // reorged block hash => fork height since which blocks reorged
+}
+
+func newEthBlockFeed(store syncedBlockStore, capacity int) *ethBlockFeed {
+	return &ethBlockFeed{
+		store:    store,
+		capacity: max(capacity, 1),
+		reorged:  make(map[string]uint64),
+	}
+}
+
+// run loads synced blocks periodically until context done.
+func (f *ethBlockFeed) run(ctx context.Context) {
+	ticker := time.NewTicker(pollingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.loadOnce(); err != nil {
+				logrus.WithError(err).Info("Block feed failed to load synced blocks")
+			}
+		}
+	}
+}
+
+// loadOnce loads the synced blocks since the latest ones within window, and rewinds the window
+// if any block reorged.
+func (f *ethBlockFeed) loadOnce() error {
+	maxBlock, ok, err := f.store.MaxEpoch()
+	if err != nil {
+		return errors.WithMessage(err, "failed to get max synced block")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !ok { // no block synced
+		f.rewind(0)
+		return nil
+	}
+
+	// blocks beyond the max synced one are reverted
+	f.rewind(maxBlock + 1)
+
+	var from uint64
+	if len(f.window) == 0 {
+		from = maxBlock - min(maxBlock, uint64(f.capacity-1))
+	} else {
+		tail := f.window[len(f.window)-1].height
+		from = max(f.window[0].height, tail-min(tail, blockFeedReorgCheckDepth))
+	}
+
+	to := min(maxBlock, from+maxBlockFeedLoadSize-1)
+
+	hashes, err := f.store.PivotHashes(from, to)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get synced block hashes")
+	}
+
+	for bn := from; bn <= to; bn++ {
+		hash, ok := hashes[bn]
+
+		if i, inWindow := f.index(bn); inWindow {
+			if ok && f.window[i].hash == hash {
+				continue
+			}
+
+			// block reorged or reverted
+			f.rewind(bn)
+		}
+
+		if !ok { // not synced yet
+			break
+		}
+
+		// synced blocks must be continuous
+		if n := len(f.window); n > 0 && f.window[n-1].height+1 != bn {
+			break
+		}
+
+		f.window = append(f.window, filterCursor{height: bn, hash: hash})
+	}
+
+	if n := len(f.window); n > f.capacity {
+		f.window = append([]filterCursor(nil), f.window[n-f.capacity:]...)
+
+		// prune reorged blocks forked before window
+		for hash, forkHeight := range f.reorged {
+			if forkHeight < f.window[0].height {
+				delete(f.reorged, hash)
+			}
+		}
+	}
+
+	return nil
+}
+
+// index returns the index of block within window if exists.
+func (f *ethBlockFeed) index(bn uint64) (int, bool) {
+	if len(f.window) == 0 || bn < f.window[0].height {
+		return 0, false
+	}
+
+	i := bn - f.window[0].height
+	return int(i), i < uint64(len(f.window))
+}
+
+// rewind removes blocks from the specified block number within window, and marks them as reorged.
+func (f *ethBlockFeed) rewind(bn uint64) {
+	var i int
+	if len(f.window) > 0 && bn >= f.window[0].height {
+		if i, _ = f.index(bn); i >= len(f.window) {
+			return
+		}
+	}
+
+	// previously reorged blocks also fork at the rewound height
+	for hash, forkHeight := range f.reorged {
+		if forkHeight > bn {
+			f.reorged[hash] = bn
+		}
+	}
+
+	for _, c := range f.window[i:] {
+		f.reorged[c.hash] = bn
+	}
+
+	f.window = f.window[:i]
+}
+
+// latestCursor returns the cursor of the latest block within window.
+func (f *ethBlockFeed) latestCursor() filterCursor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n := len(f.window); n > 0 {
+		return f.window[n-1]
+	}
+
+	return nilFilterCursor
+}
+
+// changes returns hashes of canonical blocks since the cursor along with the latest cursor. If
+// the cursor reorged, canonical blocks will be returned since the fork height.
+func (f *ethBlockFeed) changes(cursor filterCursor) ([]common.Hash, filterCursor) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hashes := []common.Hash{}
+	if len(f.window) == 0 {
+		return hashes, cursor
+	}
+
+	var start int
+	if cursor != nilFilterCursor {
+		from := cursor.height + 1
+
+		if forkHeight, ok := f.reorged[cursor.hash]; ok {
+			from = forkHeight
+		} else if i, ok := f.index(cursor.height); ok && f.window[i] != cursor {
+			// unknown fork height, e.g., cursor restored after restart
+			from = cursor.height
+		}
+
+		if front := f.window[0].height; from > front {
+			start = int(min(from-front, uint64(len(f.window))))
+		}
+	}
+
+	for _, c := range f.window[start:] {
+		hashes = append(hashes, common.HexToHash(c.hash))
+	}
+
+	return hashes, f.window[len(f.window)-1]
+}
@@ -0,0 +1,112 @@
+package virtualfilter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSyncedBlockStore struct {
+	hashes map[uint64]string
+	max    uint64
+}
+
+func (s *testSyncedBlockStore) MaxEpoch() (uint64, bool, error) {
+	return s.max, len(s.hashes) > 0, nil
+}
+
+func (s *testSyncedBlockStore) PivotHashes(from, to uint64) (map[uint64]string, error) {
+	res := make(map[uint64]string)
+	for bn := from; bn <= to; bn++ {
+		if hash, ok := s.hashes[bn]; ok {
+			res[bn] = hash
+		}
+	}
+
+	return res, nil
+}
+
+// sync syncs blocks in range [from, to] of the specified fork.
+func (s *testSyncedBlockStore) sync(from, to uint64, fork int) {
+	for bn := from; bn <= to; bn++ {
+		s.hashes[bn] = testBlockHash(bn, fork)
+	}
+
+	s.max = to
+}
+
+// revert reverts blocks since the specified block number.
+func (s *testSyncedBlockStore) revert(from uint64) {
+	for bn := from; bn <= s.max; bn++ {
+		delete(s.hashes, bn)
+	}
+
+	s.max = from - 1
+}
+
+func testBlockHash(bn uint64, fork int) string {
+	return common.HexToHash(fmt.Sprintf("0x%x%04x", fork, bn)).Hex()
+}
+
+func testBlockHashes(from, to uint64, fork int) (hashes []common.Hash) {
+	for bn := from; bn <= to; bn++ {
+		hashes = append(hashes, common.HexToHash(testBlockHash(bn, fork)))
+	}
+
+	return hashes
+}
+
+func TestEthBlockFeed(t *testing.T) {
+	store := &testSyncedBlockStore{hashes: make(map[uint64]string)}
+	feed := newEthBlockFeed(store, 5)
+
+	// no block synced yet
+	assert.NoError(t, feed.loadOnce())
+	assert.Equal(t, nilFilterCursor, feed.latestCursor())
+
+	// only the latest blocks within window are loaded
+	store.sync(1, 10, 1)
+	assert.NoError(t, feed.loadOnce())
+	assert.Equal(t, uint64(10), feed.latestCursor().height)
+
+	hashes, cursor := feed.changes(nilFilterCursor)
+	assert.Equal(t, testBlockHashes(6, 10, 1), hashes)
+
+	// new blocks synced
+	store.sync(11, 12, 1)
+	assert.NoError(t, feed.loadOnce())
+
+	hashes, cursor = feed.changes(cursor)
+	assert.Equal(t, testBlockHashes(11, 12, 1), hashes)
+
+	// blocks reverted by syncer due to chain reorg
+	store.revert(11)
+	assert.NoError(t, feed.loadOnce())
+
+	hashes, cursor = feed.changes(cursor)
+	assert.Empty(t, hashes)
+	assert.Equal(t, uint64(10), cursor.height)
+
+	// blocks re-synced on the new fork
+	store.sync(11, 13, 2)
+	assert.NoError(t, feed.loadOnce())
+
+	hashes, cursor = feed.changes(cursor)
+	assert.Equal(t, testBlockHashes(11, 13, 2), hashes)
+
+	// reorg happens between two loads
+	lastCursor := cursor
+	store.revert(12)
+	store.sync(12, 14, 3)
+	assert.NoError(t, feed.loadOnce())
+
+	hashes, cursor = feed.changes(lastCursor)
+	assert.Equal(t, testBlockHashes(12, 14, 3), hashes)
+	assert.Equal(t, uint64(14), cursor.height)
+
+	// cursor on the canonical chain
+	hashes, _ = feed.changes(cursor)
+	assert.Empty(t, hashes)
+}
@@ -2,6 +2,7 @@ package virtualfilter
 
 import (
 	"container/list"
+	"encoding/json"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -159,6 +160,61 @@ func (fc *ethFilterChain) onFilterBlockCacheEvict(key, value interface{}) {
 	}
 }
 
+// snapshot returns event logs of at most the latest n blocks on the canonical chain, excluding
+// those blocks no later than the latest one whose event logs have been evicted.
+func (fc *ethFilterChain) snapshot(n int) filterChanges {
+	logs := []types.Log{}
+
+	for _, node := range fc.tail(n) {
+		block := node.filterNodable.(*ethFilterBlock)
+		if len(block.logs) == 0 { // event logs evicted
+			logs = logs[:0]
+			continue
+		}
+
+		logs = append(logs, block.logs...)
+	}
+
+	return &types.FilterChanges{Logs: logs}
+}
+
+// restore merges the snapshot filter changes up to the start height into the filter chain, and
+// backfills the blocks between the snapshot tail and the start height if any.
+func (fc *ethFilterChain) restore(data []byte, startHeight uint64, backfill backfillFunc) (filterChanges, error) {
+	var fchanges types.FilterChanges
+	if err := json.Unmarshal(data, &fchanges); err != nil {
+		return nil, errors.WithMessage(err, "invalid filter changes json")
+	}
+
+	// changes after the start height will be polled from the proxy filter
+	var logs []types.Log
+	for i := range fchanges.Logs {
+		if fchanges.Logs[i].BlockNumber <= startHeight {
+			logs = append(logs, fchanges.Logs[i])
+		}
+	}
+
+	if len(logs) == 0 {
+		return nil, errEmptyChainSnapshot
+	}
+
+	if tail := logs[len(logs)-1].BlockNumber; tail < startHeight {
+		bchanges, err := backfill(tail+1, startHeight)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to backfill filter chain")
+		}
+
+		logs = append(logs, bchanges.(*types.FilterChanges).Logs...)
+	}
+
+	restored := &types.FilterChanges{Logs: logs}
+	if err := fc.merge(restored); err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
 // traverses the filter chain from the starting cursor, and prints the node info
 // (eg., block number, block hash etc.), mainly used for debugging.
 func (fc *ethFilterChain) print(cursor filterCursor) {
@@ -1,10 +1,12 @@
 package virtualfilter
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/openweb3/web3go/types"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -262,3 +264,81 @@ func TestEthFilterChainInitialReorg(t *testing.T) {
 		return true
 	})
 }
+
+func TestEthFilterChainSnapshot(t *testing.T) {
+	fchain := newEthFilterChain(2)
+
+	demolog := []types.Log{
+		{BlockNumber: 1, BlockHash: common.HexToHash("0x11")},
+		{BlockNumber: 2, BlockHash: common.HexToHash("0x12")},
+		{BlockNumber: 3, BlockHash: common.HexToHash("0x13")},
+		{BlockNumber: 4, BlockHash: common.HexToHash("0x14")},
+	}
+
+	err := fchain.merge(&types.FilterChanges{Logs: demolog})
+	assert.NoError(t, err, "failed to merge filter chain")
+
+	// event logs of the first two blocks are evicted
+	snapshot := fchain.snapshot(3)
+	assert.Equal(t, demolog[2:], snapshot.(*types.FilterChanges).Logs)
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	rchain := newEthFilterChain(2)
+	_, err = rchain.restore(data, 4, func(fromHeight, toHeight uint64) (filterChanges, error) {
+		t.Fatalf("unexpected backfill from %v to %v", fromHeight, toHeight)
+		return nil, nil
+	})
+	assert.NoError(t, err, "failed to restore filter chain")
+
+	assert.Equal(t, 2, rchain.len)
+	assert.Equal(t, fchain.snapshotLatestCursor(), rchain.snapshotLatestCursor())
+
+	// locate cursor on the restored chain
+	cursor3, cursor4 := rchain.front().cursor(), rchain.back().cursor()
+	assert.Equal(t, cursor4, rchain.locate(cursor4))
+	assert.Equal(t, cursor3, rchain.locate(filterCursor{height: 4, hash: common.HexToHash("0x24").String()}))
+	assert.Equal(t, nilFilterCursor, rchain.locate(filterCursor{height: 2, hash: common.HexToHash("0x12").String()}))
+	assert.Equal(t, nilFilterCursor, rchain.locate(nilFilterCursor))
+}
+
+func TestEthFilterChainRestoreBackfill(t *testing.T) {
+	snapshot := &types.FilterChanges{Logs: []types.Log{
+		{BlockNumber: 3, BlockHash: common.HexToHash("0x13")},
+		{BlockNumber: 4, BlockHash: common.HexToHash("0x14")},
+	}}
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	// gap between the snapshot tail and the start height is backfilled
+	rchain := newEthFilterChain(0)
+	fchanges, err := rchain.restore(data, 6, func(fromHeight, toHeight uint64) (filterChanges, error) {
+		assert.Equal(t, uint64(5), fromHeight)
+		assert.Equal(t, uint64(6), toHeight)
+
+		return &types.FilterChanges{Logs: []types.Log{
+			{BlockNumber: 6, BlockHash: common.HexToHash("0x16")},
+		}}, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, fchanges.(*types.FilterChanges).Logs, 3)
+	assert.Equal(t, 3, rchain.len)
+	assert.Equal(t, uint64(6), rchain.snapshotLatestCursor().height)
+
+	// snapshot beyond the start height is left to the proxy filter
+	rchain = newEthFilterChain(0)
+	_, err = rchain.restore(data, 3, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rchain.len)
+	assert.Equal(t, uint64(3), rchain.snapshotLatestCursor().height)
+
+	// nothing is restored if failed to backfill
+	rchain = newEthFilterChain(0)
+	_, err = rchain.restore(data, 6, func(fromHeight, toHeight uint64) (filterChanges, error) {
+		return nil, errors.New("backfill error")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 0, rchain.len)
+}
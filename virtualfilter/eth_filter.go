@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/Conflux-Chain/confura/node"
@@ -45,10 +46,23 @@ func (f *ethFilter) uninstall() (bool, error) {
 	return f.client.Filter.UninstallFilter(f.id)
 }
 
+func (f *ethFilter) release() {
+	metricVirtualFilterSession("eth", f, -1)
+}
+
 func (f *ethFilter) nodeName() string {
 	return f.client.NodeName()
 }
 
+func (f *ethFilter) state() *filterState {
+	return &filterState{
+		ID:              f.id,
+		Type:            f.typ,
+		NodeUrl:         f.client.URL,
+		LastPollingTime: f.lastPollingTime,
+	}
+}
+
 func newEthBlockFilter(client *node.Web3goClient) (*ethFilter, error) {
 	fid, err := client.Filter.NewBlockFilter()
 	if err != nil {
@@ -73,6 +87,67 @@ func newEthPendingTxnFilter(client *node.Web3goClient) (*ethFilter, error) {
 	return f, nil
 }
 
+// ethSyncBlockFilter evm space virtual block filter served from the blocks synced by syncer,
+// which doesn't depend on any full node.
+type ethSyncBlockFilter struct {
+	filterBase
+
+	mu     sync.Mutex
+	feed   *ethBlockFeed
+	cursor filterCursor
+}
+
+func newEthSyncBlockFilter(fid rpc.ID, feed *ethBlockFeed, cursor filterCursor) *ethSyncBlockFilter {
+	return &ethSyncBlockFilter{
+		feed:   feed,
+		cursor: cursor,
+		filterBase: filterBase{
+			id:              fid,
+			typ:             filterTypeBlock,
+			lastPollingTime: time.Now(),
+		},
+	}
+}
+
+// implements `virtualFilter` interface
+
+func (f *ethSyncBlockFilter) fetch() (filterChanges, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	hashes, cursor := f.feed.changes(f.cursor)
+	f.cursor = cursor
+
+	return &types.FilterChanges{Hashes: hashes}, nil
+}
+
+func (f *ethSyncBlockFilter) uninstall() (bool, error) {
+	metricVirtualFilterSession("eth", f, -1)
+	return true, nil
+}
+
+func (f *ethSyncBlockFilter) release() {
+	metricVirtualFilterSession("eth", f, -1)
+}
+
+func (f *ethSyncBlockFilter) nodeName() string {
+	return syncBlockFeedNodeName
+}
+
+func (f *ethSyncBlockFilter) state() *filterState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	state := &filterState{
+		ID:              f.id,
+		Type:            f.typ,
+		LastPollingTime: f.lastPollingTime,
+	}
+	state.setCursor(f.cursor)
+
+	return state
+}
+
 type ethLogFilter struct {
 	*ethFilter
 
@@ -102,11 +177,52 @@ func newEthLogFilter(
 	return lf, nil
 }
 
+// restoreEthLogFilter restores log filter from the persisted state, which resumes polling from
+// the persisted cursor.
+func restoreEthLogFilter(
+	vfls *mysql.VirtualFilterLogStore,
+	worker *ethFilterWorker,
+	client *node.Web3goClient,
+	state *filterState,
+) (*ethLogFilter, error) {
+	lf := &ethLogFilter{
+		logStore:  vfls,
+		worker:    worker,
+		ethFilter: newEthFilter(state.ID, filterTypeLog, client),
+	}
+
+	if err := json.Unmarshal(state.Crit, &lf.crit); err != nil {
+		return nil, errors.WithMessage(err, "invalid filter criteria json")
+	}
+
+	if err := worker.resume(lf, state.cursor()); err != nil {
+		return nil, err
+	}
+
+	metricVirtualFilterSession("eth", lf, 1)
+	return lf, nil
+}
+
 func (f *ethLogFilter) uninstall() (bool, error) {
 	metricVirtualFilterSession("eth", f, -1)
 	return f.worker.reject(f)
 }
 
+func (f *ethLogFilter) release() {
+	f.uninstall()
+}
+
+func (f *ethLogFilter) state() *filterState {
+	state := f.ethFilter.state()
+	state.Crit, _ = json.Marshal(f.crit)
+
+	if cursor, ok := f.worker.cursor(f.id); ok {
+		state.setCursor(cursor)
+	}
+
+	return state
+}
+
 func (f *ethLogFilter) fetch() (filterChanges, error) {
 	// get change blocks from filter worker since last polling
 	pchanges, err := f.worker.fetchPollingChanges(f.id)
@@ -19,17 +19,33 @@ import (
 type ethFilterSystem struct {
 	*filterSystemBase
 	conf *ethConfig
+
+	// feed of synced blocks for block filters, nil if disabled
+	blockFeed *ethBlockFeed
 }
 
 func newEthFilterSystem(
 	conf *ethConfig,
-	vfls *mysql.VirtualFilterLogStore,
+	db *mysql.MysqlStore,
+	vfss filterStateStore,
 	shutdownCtx cmdutil.GracefulShutdownContext,
 ) *ethFilterSystem {
-	return &ethFilterSystem{
+	fs := &ethFilterSystem{
 		conf:             conf,
-		filterSystemBase: newFilterSystemBase(conf.TTL, vfls, shutdownCtx),
+		filterSystemBase: newFilterSystemBase(conf.TTL, db.VirtualFilterLogStore, vfss, shutdownCtx),
 	}
+
+	if conf.SyncBlockFilter.Enabled {
+		fs.blockFeed = newEthBlockFeed(db, conf.SyncBlockFilter.Window)
+
+		if err := fs.blockFeed.loadOnce(); err != nil {
+			logrus.WithError(err).Warn("Block feed failed to load synced blocks initially")
+		}
+
+		go fs.blockFeed.run(shutdownCtx.Ctx)
+	}
+
+	return fs
 }
 
 func (fs *ethFilterSystem) newBlockFilter(client *node.Web3goClient) (rpc.ID, error) {
@@ -38,7 +54,16 @@ func (fs *ethFilterSystem) newBlockFilter(client *node.Web3goClient) (rpc.ID, er
 		return nilRpcId, err
 	}
 
-	fs.filterMgr.add(f)
+	fs.addFilter(f)
+	return f.fid(), nil
+}
+
+// newSyncBlockFilter creates block filter served from the synced blocks.
+func (fs *ethFilterSystem) newSyncBlockFilter() (rpc.ID, error) {
+	f := newEthSyncBlockFilter(rpc.NewID(), fs.blockFeed, fs.blockFeed.latestCursor())
+	metricVirtualFilterSession("eth", f, 1)
+
+	fs.addFilter(f)
 	return f.fid(), nil
 }
 
@@ -48,26 +73,63 @@ func (fs *ethFilterSystem) newPendingTransactionFilter(client *node.Web3goClient
 		return nilRpcId, err
 	}
 
-	fs.filterMgr.add(f)
+	fs.addFilter(f)
 	return f.fid(), nil
 }
 
 func (fs *ethFilterSystem) newFilter(client *node.Web3goClient, crit types.FilterQuery) (rpc.ID, error) {
-	worker, _ := fs.workers.LoadOrStoreFn(client.NodeName(), func(k interface{}) interface{} {
-		return newEthFilterWorker(
-			fs.conf.MaxFullFilterBlocks, fs, client, fs.shutdownCtx,
-		)
-	})
-
-	f, err := newEthLogFilter(fs.logStore, worker.(*ethFilterWorker), client, crit)
+	f, err := newEthLogFilter(fs.logStore, fs.loadOrNewWorker(client), client, crit)
 	if err != nil {
 		return nilRpcId, err
 	}
 
-	fs.filterMgr.add(f)
+	fs.addFilter(f)
 	return f.fid(), nil
 }
 
+// restoreFilter restores the virtual filter from the persisted state, e.g., after failover.
+func (fs *ethFilterSystem) restoreFilter(client *node.Web3goClient, state *filterState) error {
+	var f virtualFilter
+
+	if client == nil { // not delegated to full node
+		if state.Type != filterTypeBlock || fs.blockFeed == nil {
+			return errors.New("block filter from synced blocks not supported")
+		}
+
+		f = newEthSyncBlockFilter(state.ID, fs.blockFeed, state.cursor())
+		metricVirtualFilterSession("eth", f, 1)
+
+		fs.addFilter(f)
+		return nil
+	}
+
+	switch state.Type {
+	case filterTypeBlock, filterTypePendingTxn:
+		f = newEthFilter(state.ID, state.Type, client)
+		metricVirtualFilterSession("eth", f, 1)
+	case filterTypeLog:
+		lf, err := restoreEthLogFilter(fs.logStore, fs.loadOrNewWorker(client), client, state)
+		if err != nil {
+			return err
+		}
+
+		f = lf
+	default:
+		return errors.Errorf("unknown filter type %v", state.Type)
+	}
+
+	fs.addFilter(f)
+	return nil
+}
+
+func (fs *ethFilterSystem) loadOrNewWorker(client *node.Web3goClient) *ethFilterWorker {
+	worker, _ := fs.workers.LoadOrStoreFn(client.NodeName(), func(k interface{}) interface{} {
+		return newEthFilterWorker(fs.conf, fs, fs.stateStore, client, fs.shutdownCtx)
+	})
+
+	return worker.(*ethFilterWorker)
+}
+
 func (fs *ethFilterSystem) getFilterChanges(id rpc.ID) (*types.FilterChanges, error) {
 	vf, ok := fs.filterMgr.get(id)
 	if !ok {
@@ -80,11 +142,14 @@ func (fs *ethFilterSystem) getFilterChanges(id rpc.ID) (*types.FilterChanges, er
 	}
 
 	fs.filterMgr.refresh(id)
+	fs.saveFilterState(vf)
+
 	return fc.(*types.FilterChanges), nil
 }
 
 func (fs *ethFilterSystem) uninstallFilter(id rpc.ID) (bool, error) {
 	if vf, ok := fs.filterMgr.delete(id); ok {
+		fs.delFilterState(id)
 		return vf.uninstall()
 	}
 
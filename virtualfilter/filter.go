@@ -34,6 +34,8 @@ type virtualFilter interface {
 	expired(ttl time.Duration) bool // if this filter is expired with the provided TTL
 	fetch() (filterChanges, error)  // fetch filter changes since last polling
 	uninstall() (bool, error)       // uninstall filter
+	release()                       // release local delegate without uninstalling filter
+	state() *filterState            // persistent state of filter
 }
 
 type filterBase struct {
@@ -9,10 +9,11 @@ import (
 // MustNewEvmSpaceServerFromViper creates evm space virtual filters RPC server from viper settings
 func MustNewEvmSpaceServerFromViper(
 	shutdownContext util.GracefulShutdownContext,
-	vfls *mysql.VirtualFilterLogStore,
+	db *mysql.MysqlStore,
 ) (*rpc.Server, string) {
 	conf := mustNewEthConfigFromViper()
-	fs := newEthFilterSystem(conf, vfls, shutdownContext)
+	vfss := conf.Persistence.mustNewFilterStateStore("eth", conf.TTL)
+	fs := newEthFilterSystem(conf, db, vfss, shutdownContext)
 
 	srv := rpc.MustNewServer("eth_vfilter", map[string]interface{}{
 		"eth": newEthFilterApi(fs),
@@ -27,7 +28,8 @@ func MustNewCoreSpaceServerFromViper(
 	vfls *mysql.VirtualFilterLogStore,
 ) (*rpc.Server, string) {
 	conf := mustNewCfxConfigFromViper()
-	fs := newCfxFilterSystem(conf, vfls, shutdownContext)
+	vfss := conf.Persistence.mustNewFilterStateStore("cfx", conf.TTL)
+	fs := newCfxFilterSystem(conf, vfls, vfss, shutdownContext)
 
 	srv := rpc.MustNewServer("cfx_vfilter", map[string]interface{}{
 		"cfx": newCfxFilterApi(fs),
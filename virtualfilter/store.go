@@ -0,0 +1,137 @@
+package virtualfilter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/pkg/errors"
+)
+
+const (
+	// timeout to read or write the filter state store
+	filterStateStoreTimeout = 3 * time.Second
+)
+
+// filterState is the persistent state of virtual filter, which is shared among replicas so that
+// the virtual filter could be restored by another replica after restart or failover.
+type filterState struct {
+	ID      rpc.ID     `json:"id"`
+	Type    filterType `json:"type"`
+	NodeUrl string     `json:"nodeUrl"` // delegate full node URL, empty if served from synced blocks
+
+	// log filter criteria
+	Crit json.RawMessage `json:"crit,omitempty"`
+	// filter cursor of log filter on the filter chain, or block filter on the synced blocks
+	CursorHeight uint64 `json:"cursorHeight,omitempty"`
+	CursorHash   string `json:"cursorHash,omitempty"`
+
+	LastPollingTime time.Time `json:"lastPollingTime"`
+}
+
+func (s *filterState) cursor() filterCursor {
+	return filterCursor{height: s.CursorHeight, hash: s.CursorHash}
+}
+
+func (s *filterState) setCursor(cursor filterCursor) {
+	s.CursorHeight, s.CursorHash = cursor.height, cursor.hash
+}
+
+// filterStateStore persists virtual filter states along with the recent window of filter chain
+// of each full node.
+type filterStateStore interface {
+	// save filter state
+	saveFilter(state *filterState) error
+	// load filter state by ID, returns false if not found or expired
+	loadFilter(id rpc.ID) (*filterState, bool, error)
+	// delete filter state by ID
+	delFilter(id rpc.ID) error
+	// save the snapshot of filter chain for the full node
+	saveChain(nodeName string, snapshot []byte) error
+	// load the snapshot of filter chain for the full node, returns false if not found or expired
+	loadChain(nodeName string) ([]byte, bool, error)
+}
+
+// redisFilterStateStore persists virtual filter states in Redis, which expire if not refreshed
+// within the filter TTL.
+type redisFilterStateStore struct {
+	client *redis.Client
+	space  string
+	ttl    time.Duration
+}
+
+func newRedisFilterStateStore(client *redis.Client, space string, ttl time.Duration) *redisFilterStateStore {
+	return &redisFilterStateStore{client: client, space: space, ttl: ttl}
+}
+
+func (s *redisFilterStateStore) saveFilter(state *filterState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal filter state")
+	}
+
+	return s.set(s.filterKey(state.ID), data)
+}
+
+func (s *redisFilterStateStore) loadFilter(id rpc.ID) (*filterState, bool, error) {
+	data, ok, err := s.get(s.filterKey(id))
+	if !ok || err != nil {
+		return nil, false, err
+	}
+
+	var state filterState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false, errors.WithMessage(err, "invalid filter state json")
+	}
+
+	return &state, true, nil
+}
+
+func (s *redisFilterStateStore) delFilter(id rpc.ID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), filterStateStoreTimeout)
+	defer cancel()
+
+	return s.client.Del(ctx, s.filterKey(id)).Err()
+}
+
+func (s *redisFilterStateStore) saveChain(nodeName string, snapshot []byte) error {
+	return s.set(s.chainKey(nodeName), snapshot)
+}
+
+func (s *redisFilterStateStore) loadChain(nodeName string) ([]byte, bool, error) {
+	return s.get(s.chainKey(nodeName))
+}
+
+func (s *redisFilterStateStore) set(key string, value []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), filterStateStoreTimeout)
+	defer cancel()
+
+	return s.client.Set(ctx, key, value, s.ttl).Err()
+}
+
+func (s *redisFilterStateStore) get(key string) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), filterStateStoreTimeout)
+	defer cancel()
+
+	data, err := s.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+func (s *redisFilterStateStore) filterKey(id rpc.ID) string {
+	return fmt.Sprintf("vfilter:%v:filter:%v", s.space, id)
+}
+
+func (s *redisFilterStateStore) chainKey(nodeName string) string {
+	return fmt.Sprintf("vfilter:%v:chain:%v", s.space, nodeName)
+}
@@ -27,6 +27,8 @@ type filterSystemBase struct {
 
 	// log store to persist changed logs for more reliability
 	logStore *mysql.VirtualFilterLogStore
+	// state store to persist filter states for failover, nil if disabled
+	stateStore filterStateStore
 
 	// graceful shutdown context
 	shutdownCtx cmdutil.GracefulShutdownContext
@@ -35,10 +37,12 @@ type filterSystemBase struct {
 func newFilterSystemBase(
 	ttl time.Duration,
 	vfls *mysql.VirtualFilterLogStore,
+	vfss filterStateStore,
 	shutdownCtx cmdutil.GracefulShutdownContext,
 ) *filterSystemBase {
 	fs := &filterSystemBase{
 		logStore:    vfls,
+		stateStore:  vfss,
 		shutdownCtx: shutdownCtx,
 		filterMgr:   newFilterManager(),
 	}
@@ -51,6 +55,68 @@ func (fs *filterSystemBase) getFilter(id rpc.ID) (virtualFilter, bool) {
 	return fs.filterMgr.get(id)
 }
 
+// addFilter adds the virtual filter, and also persists the filter state if enabled.
+func (fs *filterSystemBase) addFilter(f virtualFilter) {
+	fs.filterMgr.add(f)
+	fs.saveFilterState(f)
+}
+
+// saveFilterState persists the virtual filter state if enabled.
+func (fs *filterSystemBase) saveFilterState(f virtualFilter) {
+	if fs.stateStore == nil {
+		return
+	}
+
+	if err := fs.stateStore.saveFilter(f.state()); err != nil {
+		logrus.WithField("fid", f.fid()).
+			WithError(err).
+			Error("Filter system failed to save virtual filter state")
+	}
+}
+
+// loadFilterState loads the persisted virtual filter state, e.g., which is saved by another replica.
+func (fs *filterSystemBase) loadFilterState(id rpc.ID) (*filterState, bool) {
+	if fs.stateStore == nil {
+		return nil, false
+	}
+
+	state, ok, err := fs.stateStore.loadFilter(id)
+	if err != nil {
+		logrus.WithField("fid", id).
+			WithError(err).
+			Error("Filter system failed to load virtual filter state")
+		return nil, false
+	}
+
+	return state, ok
+}
+
+// delFilterState deletes the persisted virtual filter state if enabled.
+func (fs *filterSystemBase) delFilterState(id rpc.ID) {
+	if fs.stateStore == nil {
+		return
+	}
+
+	if err := fs.stateStore.delFilter(id); err != nil {
+		logrus.WithField("fid", id).
+			WithError(err).
+			Error("Filter system failed to delete virtual filter state")
+	}
+}
+
+// expireFilter uninstalls the expired virtual filter, unless it has been taken over by another
+// replica which keeps polling, in which case only the local delegate is released.
+func (fs *filterSystemBase) expireFilter(vf virtualFilter) {
+	state, ok := fs.loadFilterState(vf.fid())
+	if ok && state.LastPollingTime.After(vf.state().LastPollingTime) {
+		vf.release()
+		return
+	}
+
+	fs.delFilterState(vf.fid())
+	vf.uninstall()
+}
+
 // timeoutLoop runs at the interval set by 'ttl' and deletes expired virtual filters
 func (fs *filterSystemBase) timeoutLoop(ttl time.Duration) {
 	ticker := time.NewTicker(ttl / 2)
@@ -59,7 +125,7 @@ func (fs *filterSystemBase) timeoutLoop(ttl time.Duration) {
 	for range ticker.C {
 		expfs := fs.filterMgr.expire(ttl)
 		for _, vf := range expfs {
-			vf.uninstall()
+			fs.expireFilter(vf)
 		}
 	}
 }
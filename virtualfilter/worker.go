@@ -23,12 +23,26 @@ var (
 	nilPollingSession = pollingSession{fid: nilRpcId}
 
 	errFilterWorkerShutdown = errors.New("filter worker already shutdown")
+	errFilterNotResumable   = errors.New("filter changes since the cursor are missing")
+)
+
+const (
+	// max attempts to install the proxy filter while the latest height unchanged
+	maxEstablishAttempts = 3
+	// max number of blocks (or epochs) to backfill the gap between the persisted filter chain
+	// snapshot and the newly established polling session
+	maxBackfillRange = 1000
 )
 
 // pollingSession session context data for the filer worker during polling
 type pollingSession struct {
 	// id of the shared proxy filter
 	fid rpc.ID
+	// latest block (or epoch) height when the proxy filter installed, after which the changes are
+	// polled from the proxy filter, or 0 if unknown
+	startHeight uint64
+	// height after which the changes are all kept in the filter chain without gap, 0 if unknown
+	baseHeight uint64
 	// last polling time
 	lastPollingTime time.Time
 	// delegate virtual filter cursors
@@ -36,6 +50,8 @@ type pollingSession struct {
 	// simulated filter blockchain (for world outlook) to which
 	// the polling will be applied
 	fchain filterChain
+	// latest cursor of the filter chain when last persisted
+	persistedCursor filterCursor
 }
 
 // pollingClient client for filter worker to polling from full node
@@ -46,11 +62,14 @@ type pollingClient interface {
 	fetch(fid rpc.ID) (filterChanges, error)
 	// uninstall filter with specific id
 	uninstall(fid rpc.ID) (bool, error)
+	// fetch changes of blocks (or epochs) within the height range from full node
+	backfill(fromHeight, toHeight uint64) (filterChanges, error)
 }
 
-func newPollingSession(fid rpc.ID, chain filterChain) *pollingSession {
+func newPollingSession(fid rpc.ID, startHeight uint64, chain filterChain) *pollingSession {
 	return &pollingSession{
 		fid:             fid,
+		startHeight:     startHeight,
 		fchain:          chain,
 		lastPollingTime: time.Now(),
 		fcursors:        make(map[rpc.ID]filterCursor),
@@ -79,12 +98,17 @@ type filterWorker struct {
 	session  pollingSession  // ongoing polling session
 	client   pollingClient   // polling client
 	observer pollingObserver // polling observer
+
+	stateStore  filterStateStore // state store to persist filter chain, nil if disabled
+	chainWindow int              // max number of the latest filter chain nodes to persist
 }
 
 func newFilterWorker(
 	space, nodeName string,
 	client pollingClient,
 	obs pollingObserver,
+	vfss filterStateStore,
+	chainWindow int,
 	shutdownCtx cmdutil.GracefulShutdownContext,
 ) *filterWorker {
 	return &filterWorker{
@@ -93,12 +117,24 @@ func newFilterWorker(
 		nodeName:    nodeName,
 		client:      client,
 		session:     nilPollingSession,
+		stateStore:  vfss,
+		chainWindow: chainWindow,
 		shutdownCtx: shutdownCtx,
 	}
 }
 
 // accept accepts delegate for virtual filter
 func (w *filterWorker) accept(f virtualFilter) error {
+	return w.delegate(f, nil)
+}
+
+// resume accepts delegate for virtual filter restored from the persisted state, which resumes
+// polling from the persisted cursor.
+func (w *filterWorker) resume(f virtualFilter, cursor filterCursor) error {
+	return w.delegate(f, &cursor)
+}
+
+func (w *filterWorker) delegate(f virtualFilter, cursor *filterCursor) error {
 	if atomic.LoadUint32(&w.quitflag) != 0 { // worker already shutdown
 		return errFilterWorkerShutdown
 	}
@@ -119,11 +155,153 @@ func (w *filterWorker) accept(f virtualFilter) error {
 			w.observer.onEstablished(w.nodeName, w.session.fid)
 		}
 
+		w.restoreChain()
+
 		go w.poll()
 	}
 
-	// snapshot filter cursor for the delegate virtual filter
-	w.session.fcursors[f.fid()] = w.session.fchain.snapshotLatestCursor()
+	if cursor != nil {
+		// the restored virtual filter can't be resumed if any changes after its cursor are missing,
+		// e.g., the persisted filter chain snapshot is expired or too stale to be backfilled.
+		if base := w.session.baseHeight; base == 0 || cursor.height < base {
+			return errFilterNotResumable
+		}
+
+		// locate the persisted cursor for the restored virtual filter
+		w.session.fcursors[f.fid()] = w.session.fchain.locate(*cursor)
+	} else {
+		// snapshot filter cursor for the delegate virtual filter
+		w.session.fcursors[f.fid()] = w.session.fchain.snapshotLatestCursor()
+	}
+
+	return nil
+}
+
+// cursor returns the filter cursor of the delegate virtual filter
+func (w *filterWorker) cursor(fid rpc.ID) (filterCursor, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cursor, ok := w.session.fcursors[fid]
+	return cursor, ok
+}
+
+// restoreChain restores the filter chain of the newly established polling session from the
+// persisted snapshot, with the gap before the proxy filter installed backfilled, so that the
+// restored virtual filters could resume from their cursors.
+func (w *filterWorker) restoreChain() {
+	// changes are kept without gap since the proxy filter installed
+	w.session.baseHeight = w.session.startHeight
+
+	if w.stateStore == nil || w.session.startHeight == 0 {
+		return
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"fid":      w.session.fid,
+		"nodeName": w.nodeName,
+	})
+
+	data, ok, err := w.stateStore.loadChain(w.nodeName)
+	if err != nil {
+		logger.WithError(err).Error("Filter worker failed to load filter chain snapshot")
+		return
+	}
+
+	if !ok { // no snapshot persisted yet or expired
+		return
+	}
+
+	fchanges, err := w.session.fchain.restore(data, w.session.startHeight, w.backfill)
+	if err != nil {
+		logger.WithError(err).Info("Filter worker failed to restore filter chain")
+		return
+	}
+
+	// also persist the restored changes as if they are polled
+	if w.observer != nil {
+		w.observer.onPolled(w.nodeName, w.session.fid, fchanges)
+	}
+
+	w.session.persistedCursor = w.session.fchain.snapshotLatestCursor()
+	w.session.baseHeight = w.session.fchain.front().cursor().height - 1
+}
+
+// backfill fetches the changes between the persisted filter chain snapshot and the newly
+// installed proxy filter.
+func (w *filterWorker) backfill(fromHeight, toHeight uint64) (filterChanges, error) {
+	if toHeight-fromHeight+1 > maxBackfillRange {
+		return nil, errors.Errorf("too many blocks to backfill from %v to %v", fromHeight, toHeight)
+	}
+
+	return w.client.backfill(fromHeight, toHeight)
+}
+
+// establishProxyFilter installs the proxy filter while the latest height stays unchanged, so that
+// the changes after the start height are all polled from the proxy filter, while those before
+// could be backfilled without gap or overlap. The start height is 0 if the latest height keeps
+// changing during the installation.
+func establishProxyFilter(
+	latest func() (uint64, error),
+	install func() (*rpc.ID, error),
+	uninstall func(rpc.ID) (bool, error),
+) (rpc.ID, uint64, error) {
+	for i := 1; ; i++ {
+		before, err := latest()
+		if err != nil {
+			return nilRpcId, 0, errors.WithMessage(err, "failed to get latest height")
+		}
+
+		fid, err := install()
+		if err != nil {
+			return nilRpcId, 0, err
+		}
+
+		after, err := latest()
+		if err == nil && after == before {
+			return *fid, after, nil
+		}
+
+		if i >= maxEstablishAttempts {
+			return *fid, 0, nil
+		}
+
+		uninstall(*fid)
+	}
+}
+
+// persistChain persists the snapshot of filter chain if changed since last persistence.
+func (w *filterWorker) persistChain() error {
+	if w.stateStore == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+
+	fchain := w.session.fchain
+	if fchain == nil || fchain.snapshotLatestCursor() == w.session.persistedCursor {
+		w.mu.Unlock()
+		return nil
+	}
+
+	cursor := fchain.snapshotLatestCursor()
+	snapshot := fchain.snapshot(w.chainWindow)
+
+	w.mu.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal filter chain snapshot")
+	}
+
+	if err := w.stateStore.saveChain(w.nodeName, data); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.session.persistedCursor = cursor
+	w.mu.Unlock()
+
 	return nil
 }
 
@@ -218,6 +396,11 @@ func (w *filterWorker) pollOnce() error {
 		}
 	}
 
+	// persist filter chain for failover
+	if err := w.persistChain(); err != nil {
+		logger.WithError(err).Info("Filter worker failed to persist filter chain")
+	}
+
 	// update last polling time
 	w.session.lastPollingTime = time.Now()
 	return nil
@@ -315,18 +498,19 @@ type ethFilterWorker struct {
 }
 
 func newEthFilterWorker(
-	maxFullFilterBlocks int,
+	conf *ethConfig,
 	obs pollingObserver,
+	vfss filterStateStore,
 	client *node.Web3goClient,
 	shutdownCtx cmdutil.GracefulShutdownContext,
 ) *ethFilterWorker {
 	w := &ethFilterWorker{
 		client:              client,
-		maxFullFilterBlocks: maxFullFilterBlocks,
+		maxFullFilterBlocks: conf.MaxFullFilterBlocks,
 	}
 
 	w.filterWorker = newFilterWorker(
-		"eth", client.NodeName(), w, obs, shutdownCtx,
+		"eth", client.NodeName(), w, obs, vfss, conf.Persistence.ChainWindow, shutdownCtx,
 	)
 
 	return w
@@ -394,12 +578,25 @@ func (w *ethFilterWorker) fetchPollingChanges(fid rpc.ID) (*ethPollingChanges, e
 // implements `pollingClient` interface
 
 func (w *ethFilterWorker) establish() (pollingSession, error) {
-	fid, err := w.client.Filter.NewLogFilter(&ethtypes.FilterQuery{})
+	fid, startHeight, err := establishProxyFilter(
+		func() (uint64, error) {
+			bn, err := w.client.Eth.BlockNumber()
+			if err != nil {
+				return 0, err
+			}
+
+			return bn.Uint64(), nil
+		},
+		func() (*rpc.ID, error) {
+			return w.client.Filter.NewLogFilter(&ethtypes.FilterQuery{})
+		},
+		w.uninstall,
+	)
 	if err != nil {
 		return nilPollingSession, err
 	}
 
-	session := newPollingSession(*fid, newEthFilterChain(w.maxFullFilterBlocks))
+	session := newPollingSession(fid, startHeight, newEthFilterChain(w.maxFullFilterBlocks))
 	return *session, nil
 }
 
@@ -417,6 +614,17 @@ func (w *ethFilterWorker) uninstall(fid rpc.ID) (bool, error) {
 	return w.client.Filter.UninstallFilter(fid)
 }
 
+func (w *ethFilterWorker) backfill(fromHeight, toHeight uint64) (filterChanges, error) {
+	fromBlock, toBlock := ethtypes.BlockNumber(fromHeight), ethtypes.BlockNumber(toHeight)
+
+	logs, err := w.client.Eth.Logs(ethtypes.FilterQuery{FromBlock: &fromBlock, ToBlock: &toBlock})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ethtypes.FilterChanges{Logs: logs}, nil
+}
+
 // core space filter worker
 type cfxFilterWorker struct {
 	*filterWorker
@@ -426,19 +634,20 @@ type cfxFilterWorker struct {
 }
 
 func newCfxFilterWorker(
-	maxFullFilterEpochs int,
+	conf *cfxConfig,
 	obs pollingObserver,
+	vfss filterStateStore,
 	client *sdk.Client,
 	shutdownCtx cmdutil.GracefulShutdownContext,
 ) *cfxFilterWorker {
 	w := &cfxFilterWorker{
 		client:              client,
-		maxFullFilterEpochs: maxFullFilterEpochs,
+		maxFullFilterEpochs: conf.MaxFullFilterEpochs,
 	}
 
 	nodeName := rpcutil.Url2NodeName(client.GetNodeURL())
 	w.filterWorker = newFilterWorker(
-		"cfx", nodeName, w, obs, shutdownCtx,
+		"cfx", nodeName, w, obs, vfss, conf.Persistence.ChainWindow, shutdownCtx,
 	)
 
 	return w
@@ -447,12 +656,25 @@ func newCfxFilterWorker(
 // implements `pollingClient` interface
 
 func (w *cfxFilterWorker) establish() (pollingSession, error) {
-	fid, err := w.client.Filter().NewFilter(cfxtypes.LogFilter{})
+	fid, startHeight, err := establishProxyFilter(
+		func() (uint64, error) {
+			epoch, err := w.client.GetEpochNumber(cfxtypes.EpochLatestState)
+			if err != nil {
+				return 0, err
+			}
+
+			return epoch.ToInt().Uint64(), nil
+		},
+		func() (*rpc.ID, error) {
+			return w.client.Filter().NewFilter(cfxtypes.LogFilter{})
+		},
+		w.uninstall,
+	)
 	if err != nil {
 		return nilPollingSession, err
 	}
 
-	session := newPollingSession(*fid, newCfxFilterChain(w.maxFullFilterEpochs))
+	session := newPollingSession(fid, startHeight, newCfxFilterChain(w.maxFullFilterEpochs))
 	return *session, nil
 }
 
@@ -470,6 +692,23 @@ func (w *cfxFilterWorker) uninstall(fid rpc.ID) (bool, error) {
 	return w.client.Filter().UninstallFilter(fid)
 }
 
+func (w *cfxFilterWorker) backfill(fromHeight, toHeight uint64) (filterChanges, error) {
+	logs, err := w.client.GetLogs(cfxtypes.LogFilter{
+		FromEpoch: cfxtypes.NewEpochNumberUint64(fromHeight),
+		ToEpoch:   cfxtypes.NewEpochNumberUint64(toHeight),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slogs := make([]*cfxtypes.SubscriptionLog, len(logs))
+	for i := range logs {
+		slogs[i] = &cfxtypes.SubscriptionLog{Log: &logs[i]}
+	}
+
+	return &cfxtypes.CfxFilterChanges{Type: "log", Logs: slogs}, nil
+}
+
 type cfxPollingChanges struct {
 	fid    rpc.ID           // proxy filter where changes are polled
 	epochs []cfxFilterEpoch // changed epochs since last polling
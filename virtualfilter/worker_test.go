@@ -0,0 +1,156 @@
+package virtualfilter
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	cmdutil "github.com/Conflux-Chain/confura/cmd/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/openweb3/go-rpc-provider"
+	"github.com/openweb3/web3go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubPollingClient struct {
+	startHeight  uint64
+	backfillLogs []types.Log
+	backfilled   [][2]uint64
+}
+
+func (c *stubPollingClient) establish() (pollingSession, error) {
+	return *newPollingSession(rpc.ID("0x1"), c.startHeight, newEthFilterChain(0)), nil
+}
+
+func (c *stubPollingClient) fetch(fid rpc.ID) (filterChanges, error) {
+	return &types.FilterChanges{}, nil
+}
+
+func (c *stubPollingClient) uninstall(fid rpc.ID) (bool, error) {
+	return true, nil
+}
+
+func (c *stubPollingClient) backfill(fromHeight, toHeight uint64) (filterChanges, error) {
+	c.backfilled = append(c.backfilled, [2]uint64{fromHeight, toHeight})
+	return &types.FilterChanges{Logs: c.backfillLogs}, nil
+}
+
+type memFilterStateStore struct {
+	chains map[string][]byte
+}
+
+func (s *memFilterStateStore) saveFilter(state *filterState) error { return nil }
+
+func (s *memFilterStateStore) loadFilter(id rpc.ID) (*filterState, bool, error) {
+	return nil, false, nil
+}
+
+func (s *memFilterStateStore) delFilter(id rpc.ID) error { return nil }
+
+func (s *memFilterStateStore) saveChain(nodeName string, snapshot []byte) error {
+	s.chains[nodeName] = snapshot
+	return nil
+}
+
+func (s *memFilterStateStore) loadChain(nodeName string) ([]byte, bool, error) {
+	data, ok := s.chains[nodeName]
+	return data, ok, nil
+}
+
+func newTestFilterWorker(t *testing.T, client pollingClient, store filterStateStore) *filterWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	shutdownCtx := cmdutil.GracefulShutdownContext{Ctx: ctx, Wg: &sync.WaitGroup{}}
+	return newFilterWorker("eth", "node", client, nil, store, 10, shutdownCtx)
+}
+
+func newTestLogFilter(id rpc.ID) *ethLogFilter {
+	return &ethLogFilter{ethFilter: newEthFilter(id, filterTypeLog, nil)}
+}
+
+func TestFilterWorkerResumeWithBackfill(t *testing.T) {
+	snapshot := &types.FilterChanges{Logs: []types.Log{
+		{BlockNumber: 3, BlockHash: common.HexToHash("0x13")},
+		{BlockNumber: 4, BlockHash: common.HexToHash("0x14")},
+		{BlockNumber: 8, BlockHash: common.HexToHash("0x18")}, // polled by the proxy filter
+	}}
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	client := &stubPollingClient{
+		startHeight:  6,
+		backfillLogs: []types.Log{{BlockNumber: 6, BlockHash: common.HexToHash("0x16")}},
+	}
+	store := &memFilterStateStore{chains: map[string][]byte{"node": data}}
+	w := newTestFilterWorker(t, client, store)
+
+	// gap between the snapshot and the proxy filter is backfilled
+	cursor := filterCursor{height: 4, hash: common.HexToHash("0x14").String()}
+	assert.NoError(t, w.resume(newTestLogFilter("0xa"), cursor))
+	assert.Equal(t, [][2]uint64{{5, 6}}, client.backfilled)
+
+	located, ok := w.cursor("0xa")
+	assert.True(t, ok)
+	assert.Equal(t, cursor, located)
+	assert.Equal(t, uint64(6), w.session.fchain.snapshotLatestCursor().height)
+
+	// changes before the snapshot are missing
+	cursor = filterCursor{height: 1, hash: common.HexToHash("0x11").String()}
+	assert.ErrorIs(t, w.resume(newTestLogFilter("0xb"), cursor), errFilterNotResumable)
+}
+
+func TestFilterWorkerResumeWithoutSnapshot(t *testing.T) {
+	client := &stubPollingClient{startHeight: 6}
+	store := &memFilterStateStore{chains: map[string][]byte{}}
+	w := newTestFilterWorker(t, client, store)
+
+	// changes before the proxy filter installed are missing
+	cursor := filterCursor{height: 4, hash: common.HexToHash("0x14").String()}
+	assert.ErrorIs(t, w.resume(newTestLogFilter("0xa"), cursor), errFilterNotResumable)
+
+	cursor = filterCursor{height: 7, hash: common.HexToHash("0x17").String()}
+	assert.NoError(t, w.resume(newTestLogFilter("0xb"), cursor))
+	assert.Empty(t, client.backfilled)
+}
+
+func TestEstablishProxyFilter(t *testing.T) {
+	newLatest := func(heights ...uint64) func() (uint64, error) {
+		return func() (uint64, error) {
+			h := heights[0]
+			if len(heights) > 1 {
+				heights = heights[1:]
+			}
+			return h, nil
+		}
+	}
+
+	var installed, uninstalled int
+	install := func() (*rpc.ID, error) {
+		installed++
+		fid := rpc.ID("0x1")
+		return &fid, nil
+	}
+	uninstall := func(rpc.ID) (bool, error) {
+		uninstalled++
+		return true, nil
+	}
+
+	// re-install if the latest height changed during installation
+	fid, startHeight, err := establishProxyFilter(newLatest(1, 2, 2), install, uninstall)
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.ID("0x1"), fid)
+	assert.Equal(t, uint64(2), startHeight)
+	assert.Equal(t, 2, installed)
+	assert.Equal(t, 1, uninstalled)
+
+	// start height unknown if the latest height keeps changing
+	installed, uninstalled = 0, 0
+	_, startHeight, err = establishProxyFilter(newLatest(1, 2, 3, 4, 5, 6, 7), install, uninstall)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), startHeight)
+	assert.Equal(t, maxEstablishAttempts, installed)
+	assert.Equal(t, maxEstablishAttempts-1, uninstalled)
+}